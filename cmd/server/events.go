@@ -0,0 +1,24 @@
+package main
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"log"
+)
+
+// registerSubscribers suscribe al bus lo que reacciona a los cambios de libros.
+// Los suscriptores nuevos (índices, cachés, webhooks) se registran aquí. El relay del
+// outbox puede entregar un evento más de una vez: los que no toleren repetidos
+// deben descartarlos por meta.ID.
+func registerSubscribers(bus domain.EventBus, webhooks *application.WebhookService) {
+	// Registro de auditoría: una línea por cambio
+	bus.Subscribe(domain.AllEvents, func(ctx context.Context, event domain.Event) error {
+		meta := event.Meta()
+		log.Printf("Event %s id=%s book=%d actor=%s", event.EventName(), meta.ID, meta.BookID, meta.Actor)
+		return nil
+	})
+
+	// Webhooks: una entrega pendiente por webhook suscrito; las envía runWebhookDispatcher
+	bus.Subscribe(domain.AllEvents, webhooks.Enqueue)
+}
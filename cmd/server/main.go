@@ -2,12 +2,19 @@ package main
 
 import (
 	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
 	"api-go-gestion-libros-hexagonal/modules/book/infrastructure"
 	"api-go-gestion-libros-hexagonal/modules/book/presentation"
 	"api-go-gestion-libros-hexagonal/shared/config"
 	"api-go-gestion-libros-hexagonal/shared/database"
+	"context"
+	"flag"
 	"log"
+	"os"
+	"os/signal"
 	"strconv"
+	"sync"
+	"syscall"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -16,43 +23,106 @@ import (
 )
 
 func main() {
+	storage := flag.String("storage", "", "dónde guardar los libros: sql (por defecto) o memory")
+	flag.Parse()
+	args := flag.Args()
+
 	// Cargar configuración
-	cfg, err := config.Load()
+	cfg, err := config.LoadWithStorage(*storage)
 	if err != nil {
 		log.Fatal("Error loading config:", err)
 	}
 
-	// Conectar a base de datos
-	db, err := database.OpenTurso(cfg.TursoURL, cfg.TursoToken)
-	if err != nil {
-		log.Fatal("Error connecting to database:", err)
-	}
-	defer db.Close()
+	// Crear instancias de la arquitectura hexagonal
+	// Infrastructure -> Application -> Presentation
+	var (
+		bookRepo     domain.BookRepository
+		revisionRepo domain.RevisionRepository
+		outboxRepo   domain.OutboxRepository
+		webhookRepo  domain.WebhookRepository
+		txManager    domain.TxManager
+	)
+	if cfg.Storage == config.StorageMemory {
+		if len(args) > 0 && args[0] == "migrate" {
+			log.Fatal("Error running migrations: migrate needs sql storage")
+		}
+		// Sin base de datos: los datos se pierden al reiniciar (demos y pruebas)
+		log.Printf("Using in-memory storage")
+		memoryRepo := infrastructure.NewMemoryBookRepository()
+		bookRepo, txManager = memoryRepo, memoryRepo
+		revisionRepo = infrastructure.NewMemoryRevisionRepository()
+		outboxRepo = infrastructure.NewMemoryOutboxRepository()
+		webhookRepo = infrastructure.NewMemoryWebhookRepository()
+	} else {
+		// Conectar a base de datos
+		db, err := database.Open(cfg)
+		if err != nil {
+			log.Fatal("Error connecting to database:", err)
+		}
+		defer db.Close()
 
-	// Inicializar schema
-	if err := database.InitSchema(db); err != nil {
-		log.Fatal("Error initializing schema:", err)
+		// Subcomando "migrate up|down|status": administra el esquema y termina
+		if len(args) > 0 && args[0] == "migrate" {
+			if err := runMigrate(db, cfg.DatabaseDriver, args[1:]); err != nil {
+				log.Fatal("Error running migrations:", err)
+			}
+			return
+		}
+
+		// Aplicar migraciones pendientes
+		if err := migrateUp(context.Background(), db, cfg.DatabaseDriver); err != nil {
+			log.Fatal("Error migrating schema:", err)
+		}
+
+		txManager = database.NewTxManager(db)
+		if cfg.DatabaseDriver == config.DriverPostgres {
+			// Postgres normaliza en las consultas (unaccent): no hay columnas que rellenar
+			bookRepo = infrastructure.NewPostgresBookRepository(db)
+			revisionRepo = infrastructure.NewPostgresRevisionRepository(db)
+			outboxRepo = infrastructure.NewPostgresOutboxRepository(db)
+			webhookRepo = infrastructure.NewPostgresWebhookRepository(db)
+		} else {
+			sqlRepo := infrastructure.NewSqlBookRepository(db)
+
+			// Rellenar columnas normalizadas de libros anteriores a la búsqueda sin tildes
+			if n, err := sqlRepo.BackfillNormalized(context.Background()); err != nil {
+				log.Fatal("Error backfilling normalized columns:", err)
+			} else if n > 0 {
+				log.Printf("Normalized search columns backfilled for %d books", n)
+			}
+			bookRepo = sqlRepo
+			revisionRepo = infrastructure.NewSqlRevisionRepository(db)
+			outboxRepo = infrastructure.NewSqlOutboxRepository(db)
+			webhookRepo = infrastructure.NewSqlWebhookRepository(db)
+		}
 	}
 
-	// Crear instancias de la arquitectura hexagonal
-	// Infrastructure -> Application -> Presentation
-	bookRepo := infrastructure.NewSqlBookRepository(db)
-	bookService := application.NewBookService(bookRepo)
+	// Eventos: el servicio los guarda en el outbox con cada cambio y el relay los
+	// entrega a los suscriptores; si uno falla, el evento se reintenta más tarde
+	webhookService := application.NewWebhookService(webhookRepo, infrastructure.NewHTTPWebhookClient(infrastructure.DefaultWebhookTimeout),
+		domain.DefaultRetryPolicy, application.DefaultWebhookDisableAfter)
+	eventBus := infrastructure.NewSyncEventBus()
+	registerSubscribers(eventBus, webhookService)
+	relay := application.NewOutboxRelay(outboxRepo, eventBus, domain.DefaultRetryPolicy)
+
+	// Las tareas en segundo plano corren hasta que se apaga el servidor
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	var background sync.WaitGroup
+	background.Go(func() { runOutboxRelay(backgroundCtx, relay, cfg.OutboxPollInterval) })
+	background.Go(func() { runWebhookDispatcher(backgroundCtx, webhookService, cfg.WebhookPollInterval) })
+
+	bookService := application.NewBookService(bookRepo, revisionRepo, txManager, application.NewOutboxPublisher(outboxRepo))
 	bookHandler := presentation.NewBookHandler(bookService)
+	adminHandler := presentation.NewAdminHandler(relay)
+	webhookHandler := presentation.NewWebhookHandler(webhookService)
+
+	// Vaciar la papelera en segundo plano según TRASH_RETENTION
+	background.Go(func() { runTrashPurge(backgroundCtx, bookService, cfg.TrashRetention, cfg.TrashPurgeInterval) })
 
 	// Configurar Fiber
 	app := fiber.New(fiber.Config{
-		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			code := fiber.StatusInternalServerError
-			if e, ok := err.(*fiber.Error); ok {
-				code = e.Code
-			}
-
-			return c.Status(code).JSON(presentation.ErrorResponse{
-				Success: false,
-				Errors:  []string{err.Error()},
-			})
-		},
+		ErrorHandler: presentation.ErrorHandler,
 	})
 
 	// Middleware
@@ -61,7 +131,9 @@ func main() {
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
 		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders: "Origin,Content-Type,Accept,Authorization",
+		AllowHeaders: "Origin,Content-Type,Accept,Authorization,If-Match," + presentation.ActorHeader,
+		// El navegador solo deja leer ETag al cliente si se expone
+		ExposeHeaders: "ETag",
 	}))
 
 	// Health check
@@ -74,8 +146,29 @@ func main() {
 
 	// Setup routes
 	presentation.SetupBookRoutes(app, bookHandler)
+	presentation.SetupAdminRoutes(app, adminHandler)
+	presentation.SetupWebhookRoutes(app, webhookHandler)
+
+	// Con SIGINT o SIGTERM el servidor deja de aceptar conexiones y termina las peticiones en curso
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+	go func() {
+		<-signals.Done()
+		log.Printf("Shutting down server")
+		if err := app.Shutdown(); err != nil {
+			log.Printf("Error shutting down server: %v", err)
+		}
+	}()
 
 	// Start server
-	log.Printf("Server starting on port %s", cfg.Port)
-	log.Fatal(app.Listen(":" + strconv.Itoa(cfg.Port)))
+	log.Printf("Server starting on port %d", cfg.Port)
+	err = app.Listen(":" + strconv.Itoa(cfg.Port))
+
+	// Después de las peticiones, parar las tareas en segundo plano y esperarlas antes de
+	// cerrar la base de datos
+	stopBackground()
+	background.Wait()
+	if err != nil {
+		log.Fatal("Error starting server:", err)
+	}
 }
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"context"
+	"log"
+	"time"
+)
+
+// runOutboxRelay entrega cada interval los eventos pendientes del outbox, hasta que
+// ctx termine. Si un lote sale completo no espera: quedan más por entregar.
+func runOutboxRelay(ctx context.Context, relay *application.OutboxRelay, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := relay.Relay(ctx)
+		if err != nil {
+			log.Printf("Error relaying outbox: %v", err)
+		}
+		if err == nil && n == application.DefaultOutboxBatch && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
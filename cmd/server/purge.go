@@ -0,0 +1,28 @@
+package main
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"context"
+	"log"
+	"time"
+)
+
+// runTrashPurge elimina cada interval los libros que llevan en la papelera más de
+// retention, hasta que ctx termine. La primera pasada es al arrancar.
+func runTrashPurge(ctx context.Context, service *application.BookService, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := service.PurgeTrash(ctx, retention); err != nil {
+			log.Printf("Error purging trash: %v", err)
+		} else if n > 0 {
+			log.Printf("Purged %d books deleted more than %s ago", n, retention)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"context"
+	"log"
+	"time"
+)
+
+// runWebhookDispatcher envía cada interval las entregas pendientes de webhooks, hasta
+// que ctx termine. Si un lote sale completo no espera: quedan más por enviar.
+func runWebhookDispatcher(ctx context.Context, webhooks *application.WebhookService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := webhooks.Dispatch(ctx)
+		if err != nil {
+			log.Printf("Error dispatching webhooks: %v", err)
+		}
+		if err == nil && n == application.DefaultWebhookBatch && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
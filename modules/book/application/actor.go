@@ -0,0 +1,21 @@
+package application
+
+import "context"
+
+// AnonymousActor es el autor de los cambios cuando la petición no dice quién es
+const AnonymousActor = "anonymous"
+
+type actorKey struct{}
+
+// WithActor devuelve un ctx que lleva quién hace la petición, para el historial
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom devuelve quién hace la petición, o AnonymousActor si ctx no lo dice
+func ActorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
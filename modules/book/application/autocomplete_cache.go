@@ -0,0 +1,63 @@
+package application
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"sync"
+	"time"
+)
+
+const (
+	autocompleteTTL     = 30 * time.Second
+	autocompleteMaxKeys = 1000
+)
+
+// autocompleteCache guarda en memoria las ultimas sugerencias por prefijo.
+// El autocompletado repite mucho las mismas consultas mientras se escribe,
+// y se invalida completo cuando cambia algun libro.
+type autocompleteCache struct {
+	mu      sync.Mutex
+	entries map[string]autocompleteEntry
+}
+
+type autocompleteEntry struct {
+	values    []domain.ValueCount
+	expiresAt time.Time
+}
+
+func newAutocompleteCache() *autocompleteCache {
+	return &autocompleteCache{entries: map[string]autocompleteEntry{}}
+}
+
+func (c *autocompleteCache) get(key string) ([]domain.ValueCount, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expiresAt) {
+		return nil, false
+	}
+	return e.values, true
+}
+
+func (c *autocompleteCache) set(key string, values []domain.ValueCount) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= autocompleteMaxKeys {
+		// Primero descartar vencidas; si no alcanza, empezar de cero
+		now := time.Now()
+		for k, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= autocompleteMaxKeys {
+			c.entries = map[string]autocompleteEntry{}
+		}
+	}
+	c.entries[key] = autocompleteEntry{values: values, expiresAt: time.Now().Add(autocompleteTTL)}
+}
+
+func (c *autocompleteCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]autocompleteEntry{}
+}
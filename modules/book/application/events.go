@@ -0,0 +1,17 @@
+package application
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"time"
+)
+
+// eventMeta arma los datos comunes del evento name sobre book, con el autor que viaja en ctx
+func eventMeta(ctx context.Context, name string, book *domain.Book) domain.EventMeta {
+	return domain.EventMeta{
+		ID:         domain.EventID(name, book.ID, book.Version),
+		BookID:     book.ID,
+		Actor:      ActorFrom(ctx),
+		OccurredAt: time.Now().UTC(),
+	}
+}
@@ -0,0 +1,57 @@
+package application
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"time"
+)
+
+// record deja una revisión del libro id con el autor que viaja en ctx
+func (s *BookService) record(ctx context.Context, id uint, action string, changes []domain.FieldChange, revertedTo uint) error {
+	return s.revisions.Append(ctx, &domain.Revision{
+		BookID:     id,
+		Action:     action,
+		Actor:      ActorFrom(ctx),
+		Changes:    changes,
+		RevertedTo: revertedTo,
+		CreatedAt:  time.Now().UTC(),
+	})
+}
+
+// GetBookHistory devuelve las revisiones del libro, de la más vieja a la más nueva.
+// El historial de un libro borrado se sigue pudiendo consultar.
+func (s *BookService) GetBookHistory(ctx context.Context, id uint) ([]*domain.Revision, error) {
+	if id == 0 {
+		return nil, &domain.ValidationError{Field: "id", Code: domain.CodeRequired, Message: "id is required"}
+	}
+	revs, err := s.revisions.ListByBook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(revs) == 0 {
+		// Sin historial: 404 si el libro no existe; si existe es anterior al historial
+		if _, err := s.bookRepo.GetByID(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return revs, nil
+}
+
+// RevertBook vuelve el libro a los valores que tenía en la revisión revision. Es una
+// actualización más: pasa por Book.Update, las mismas validaciones, la versión y el
+// historial (queda como RevisionRevert).
+func (s *BookService) RevertBook(ctx context.Context, id, revision, version uint) (*domain.Book, error) {
+	if id == 0 {
+		return nil, &domain.ValidationError{Field: "id", Code: domain.CodeRequired, Message: "id is required"}
+	}
+	if revision == 0 {
+		return nil, &domain.ValidationError{Field: "revision", Code: domain.CodeRequired, Message: "revision is required"}
+	}
+	return s.update(ctx, id, version, func(ctx context.Context, current *domain.Book) (domain.UpdateBookInput, error) {
+		revs, err := s.revisions.ListByBook(ctx, id)
+		if err != nil {
+			return domain.UpdateBookInput{}, err
+		}
+		return domain.StateAt(current, revs, revision)
+	}, domain.RevisionRevert, revision)
+}
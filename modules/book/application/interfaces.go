@@ -9,9 +9,31 @@ import (
 
 type BookServiceInterface interface {
 	CreateBook(ctx context.Context, title, author string, year uint, genre, isbn string) (*domain.Book, error) // Crea un nuevo libro
-	UpdateBook(ctx context.Context, id uint, input domain.UpdateBookInput) (*domain.Book, error)               // Actualiza un libro existente
-	DeleteBook(ctx context.Context, id uint) error                                                             // Elimina un libro por ID
+	UpdateBook(ctx context.Context, id, version uint, input domain.UpdateBookInput) (*domain.Book, error)      // Actualiza un libro si sigue en version
+	DeleteBook(ctx context.Context, id uint) error                                                             // Mueve un libro a la papelera
 	GetBookByID(ctx context.Context, id uint) (*domain.Book, error)                                            // Obtiene un libro por ID
 	GetBookByISBN(ctx context.Context, isbn string) (*domain.Book, error)                                      // Obtiene un libro por ISBN
-	SearchBooks(ctx context.Context, filter domain.BookFilter) ([]*domain.Book, error)                         // Busca libros por filtro
+	SearchBooks(ctx context.Context, filter domain.BookFilter) (*domain.BookPage, error)                       // Busca libros por filtro, paginado
+	GetFacets(ctx context.Context, filter domain.BookFilter) (*domain.BookFacets, error)                       // Cuenta libros por genero, decada y autor
+	Autocomplete(ctx context.Context, field, prefix string, limit int) ([]domain.ValueCount, error)            // Sugiere titulos o autores por prefijo
+	GetBookHistory(ctx context.Context, id uint) ([]*domain.Revision, error)                                   // Lista las revisiones de un libro
+	RevertBook(ctx context.Context, id, revision, version uint) (*domain.Book, error)                          // Vuelve un libro a una revision
+	ListTrash(ctx context.Context) ([]*domain.Book, error)                                                     // Lista los libros de la papelera
+	RestoreBook(ctx context.Context, id uint) (*domain.Book, error)                                            // Saca un libro de la papelera
+}
+
+// OutboxServiceInterface expone el estado del outbox a la capa de presentacion
+type OutboxServiceInterface interface {
+	Backlog(ctx context.Context, limit int) (*domain.OutboxBacklog, error) // Pendientes y fallidos por entregar
+}
+
+// WebhookServiceInterface define los casos de uso de webhooks expuestos a la capa de presentacion
+type WebhookServiceInterface interface {
+	CreateWebhook(ctx context.Context, in domain.WebhookInput) (*domain.Webhook, error)          // Da de alta un webhook
+	ListWebhooks(ctx context.Context) ([]*domain.Webhook, error)                                 // Lista los webhooks
+	GetWebhook(ctx context.Context, id uint) (*domain.Webhook, error)                            // Obtiene un webhook por ID
+	UpdateWebhook(ctx context.Context, id uint, in domain.WebhookInput) (*domain.Webhook, error) // Cambia o reactiva un webhook
+	DeleteWebhook(ctx context.Context, id uint) error                                            // Elimina un webhook y sus entregas
+	ListDeliveries(ctx context.Context, id uint, limit int) ([]*domain.WebhookDelivery, error)   // Registro de entregas de un webhook
+	RedeliverWebhook(ctx context.Context, id, deliveryID uint) (*domain.WebhookDelivery, error)  // Vuelve a enviar una entrega
 }
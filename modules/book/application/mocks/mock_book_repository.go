@@ -13,6 +13,7 @@ import (
 	domain "api-go-gestion-libros-hexagonal/modules/book/domain"
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -41,6 +42,21 @@ func (m *MockBookRepository) EXPECT() *MockBookRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CountFacets mocks base method.
+func (m *MockBookRepository) CountFacets(ctx context.Context, filter domain.BookFilter) (*domain.BookFacets, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountFacets", ctx, filter)
+	ret0, _ := ret[0].(*domain.BookFacets)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountFacets indicates an expected call of CountFacets.
+func (mr *MockBookRepositoryMockRecorder) CountFacets(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountFacets", reflect.TypeOf((*MockBookRepository)(nil).CountFacets), ctx, filter)
+}
+
 // Create mocks base method.
 func (m *MockBookRepository) Create(ctx context.Context, book *domain.Book) error {
 	m.ctrl.T.Helper()
@@ -70,10 +86,10 @@ func (mr *MockBookRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
 }
 
 // FindByFilter mocks base method.
-func (m *MockBookRepository) FindByFilter(ctx context.Context, filter domain.BookFilter) ([]*domain.Book, error) {
+func (m *MockBookRepository) FindByFilter(ctx context.Context, filter domain.BookFilter) (*domain.BookPage, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "FindByFilter", ctx, filter)
-	ret0, _ := ret[0].([]*domain.Book)
+	ret0, _ := ret[0].(*domain.BookPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -129,6 +145,81 @@ func (mr *MockBookRepositoryMockRecorder) GetByISBN(ctx, isbn any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByISBN", reflect.TypeOf((*MockBookRepository)(nil).GetByISBN), ctx, isbn)
 }
 
+// ListDeleted mocks base method.
+func (m *MockBookRepository) ListDeleted(ctx context.Context) ([]*domain.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeleted", ctx)
+	ret0, _ := ret[0].([]*domain.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeleted indicates an expected call of ListDeleted.
+func (mr *MockBookRepositoryMockRecorder) ListDeleted(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeleted", reflect.TypeOf((*MockBookRepository)(nil).ListDeleted), ctx)
+}
+
+// Purge mocks base method.
+func (m *MockBookRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", ctx, deletedBefore)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Purge indicates an expected call of Purge.
+func (mr *MockBookRepositoryMockRecorder) Purge(ctx, deletedBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockBookRepository)(nil).Purge), ctx, deletedBefore)
+}
+
+// Restore mocks base method.
+func (m *MockBookRepository) Restore(ctx context.Context, id uint) (*domain.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, id)
+	ret0, _ := ret[0].(*domain.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockBookRepositoryMockRecorder) Restore(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockBookRepository)(nil).Restore), ctx, id)
+}
+
+// SuggestByPrefix mocks base method.
+func (m *MockBookRepository) SuggestByPrefix(ctx context.Context, field, prefix string, limit int) ([]domain.ValueCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuggestByPrefix", ctx, field, prefix, limit)
+	ret0, _ := ret[0].([]domain.ValueCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SuggestByPrefix indicates an expected call of SuggestByPrefix.
+func (mr *MockBookRepositoryMockRecorder) SuggestByPrefix(ctx, field, prefix, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuggestByPrefix", reflect.TypeOf((*MockBookRepository)(nil).SuggestByPrefix), ctx, field, prefix, limit)
+}
+
+// SuggestionCandidates mocks base method.
+func (m *MockBookRepository) SuggestionCandidates(ctx context.Context, field string, prefixes []string, limit int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuggestionCandidates", ctx, field, prefixes, limit)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SuggestionCandidates indicates an expected call of SuggestionCandidates.
+func (mr *MockBookRepositoryMockRecorder) SuggestionCandidates(ctx, field, prefixes, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuggestionCandidates", reflect.TypeOf((*MockBookRepository)(nil).SuggestionCandidates), ctx, field, prefixes, limit)
+}
+
 // Update mocks base method.
 func (m *MockBookRepository) Update(ctx context.Context, book *domain.Book) (*domain.Book, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,106 @@
+package application
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"time"
+)
+
+// Valores por defecto del relay
+const (
+	DefaultOutboxBatch        = 100
+	DefaultOutboxBacklogLimit = 50
+	MaxOutboxBacklogLimit     = 500
+)
+
+// OutboxPublisher es el domain.EventPublisher del servicio: en lugar de entregar los
+// eventos los guarda en el outbox, en la transacción del cambio que viaja en ctx.
+// OutboxRelay los entrega después.
+type OutboxPublisher struct {
+	outbox domain.OutboxRepository
+}
+
+func NewOutboxPublisher(outbox domain.OutboxRepository) *OutboxPublisher {
+	return &OutboxPublisher{outbox: outbox}
+}
+
+// Publish guarda cada evento como un mensaje del outbox
+func (p *OutboxPublisher) Publish(ctx context.Context, events ...domain.Event) error {
+	for _, event := range events {
+		msg, err := domain.NewOutboxMessage(event)
+		if err != nil {
+			return err
+		}
+		if err := p.outbox.Add(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OutboxRelay entrega al bus los eventos del outbox, al menos una vez: si el bus
+// devuelve error el mensaje se reintenta según policy, y si el proceso cae entre la
+// entrega y MarkPublished se vuelve a entregar. Los suscriptores descartan repetidos
+// con el ID del evento.
+type OutboxRelay struct {
+	outbox domain.OutboxRepository
+	bus    domain.EventPublisher
+	policy domain.RetryPolicy
+}
+
+func NewOutboxRelay(outbox domain.OutboxRepository, bus domain.EventPublisher, policy domain.RetryPolicy) *OutboxRelay {
+	return &OutboxRelay{outbox: outbox, bus: bus, policy: policy}
+}
+
+// Relay entrega un lote de los mensajes que ya toca intentar y devuelve cuántos entregó.
+// Los fallos de entrega no son error: quedan registrados en el mensaje.
+func (r *OutboxRelay) Relay(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	due, err := r.outbox.Due(ctx, now, DefaultOutboxBatch)
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for _, msg := range due {
+		event, err := domain.DecodeEvent(msg.EventName, msg.Payload)
+		if err != nil {
+			// Reintentar no lo va a arreglar
+			if err := r.outbox.MarkFailed(ctx, msg.ID, err.Error(), now); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+		if err := r.bus.Publish(ctx, event); err != nil {
+			if err := r.retry(ctx, msg, err, now); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+		if err := r.outbox.MarkPublished(ctx, msg.ID, time.Now().UTC()); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// retry registra el intento fallido de msg y lo reprograma, o lo da por fallido si
+// ya no quedan intentos
+func (r *OutboxRelay) retry(ctx context.Context, msg *domain.OutboxMessage, cause error, now time.Time) error {
+	attempts := msg.Attempts + 1
+	if r.policy.GiveUp(attempts) {
+		return r.outbox.MarkFailed(ctx, msg.ID, cause.Error(), now)
+	}
+	return r.outbox.MarkRetry(ctx, msg.ID, cause.Error(), now.Add(r.policy.Delay(attempts)))
+}
+
+// Backlog devuelve lo que falta entregar, para el endpoint de administración
+func (r *OutboxRelay) Backlog(ctx context.Context, limit int) (*domain.OutboxBacklog, error) {
+	if limit <= 0 {
+		limit = DefaultOutboxBacklogLimit
+	}
+	if limit > MaxOutboxBacklogLimit {
+		limit = MaxOutboxBacklogLimit
+	}
+	return r.outbox.Backlog(ctx, limit)
+}
@@ -3,114 +3,276 @@ package application
 import (
 	"api-go-gestion-libros-hexagonal/modules/book/domain"
 	"context"
+	"errors"
 	"fmt"
+	"log"
 )
 
 type BookService struct {
-	bookRepo domain.BookRepository
+	bookRepo     domain.BookRepository
+	revisions    domain.RevisionRepository
+	txManager    domain.TxManager
+	events       domain.EventPublisher
+	autocomplete *autocompleteCache
 }
 
-func NewBookService(bookRepo domain.BookRepository) *BookService {
+// NewBookService crea el servicio; los casos de uso que escriben corren dentro de txManager.WithinTx
+// y, en la misma transacción, dejan una revisión en revisions y publican el evento del libro
+// (domain.BookCreated, ...) en events, normalmente un OutboxPublisher.
+func NewBookService(bookRepo domain.BookRepository, revisions domain.RevisionRepository, txManager domain.TxManager, events domain.EventPublisher) *BookService {
 	return &BookService{
-		bookRepo: bookRepo,
+		bookRepo:     bookRepo,
+		revisions:    revisions,
+		txManager:    txManager,
+		events:       events,
+		autocomplete: newAutocompleteCache(),
 	}
 }
 
 func (s *BookService) CreateBook(ctx context.Context, title, author string, year uint, genre, isbn string) (*domain.Book, error) {
-	// Validaciones basicas por caso de uso (evita llamadas innecesarias al repositorio)
-	if title == "" {
-		return nil, fmt.Errorf("title is required")
-	}
-	if author == "" {
-		return nil, fmt.Errorf("author is required")
-	}
-
-	// Construir entidad y aplicar reglas de dominio
+	// Construir entidad y aplicar reglas de dominio antes de tocar el repositorio;
+	// el error trae todos los campos inválidos
 	book := domain.NewBook(title, author, year, genre, isbn)
 	if err := book.ValidateBasic(); err != nil {
 		return nil, err
 	}
 
-	// Unicidad por ISBN
-	existing, err := s.bookRepo.GetByISBN(ctx, book.ISBN)
-	if err == nil && existing != nil {
-		return nil, fmt.Errorf("isbn %s already exists", book.ISBN)
-	}
-
-	// Persistir en el repositorio
-	if err := s.bookRepo.Create(ctx, book); err != nil {
+	// Verificar unicidad y persistir en la misma transacción
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		existing, err := s.bookRepo.GetByISBN(ctx, book.ISBN)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return err
+		}
+		if existing != nil {
+			return fmt.Errorf("%w: %s", domain.ErrDuplicateISBN, book.ISBN)
+		}
+		if err := s.bookRepo.Create(ctx, book); err != nil {
+			return err
+		}
+		if err := s.record(ctx, book.ID, domain.RevisionCreate, domain.DiffBooks(nil, book), 0); err != nil {
+			return err
+		}
+		return s.events.Publish(ctx, domain.BookCreated{EventMeta: eventMeta(ctx, domain.EventBookCreated, book), Book: *book})
+	})
+	if err != nil {
 		return nil, err
 	}
+	s.autocomplete.clear()
 	return book, nil
 
 }
 
-func (s *BookService) UpdateBook(ctx context.Context, id uint, input domain.UpdateBookInput) (*domain.Book, error) {
+// UpdateBook aplica input si el libro sigue en version (la que el cliente leyó);
+// si cambió devuelve domain.ErrVersionMismatch. domain.AnyVersion no comprueba la versión.
+func (s *BookService) UpdateBook(ctx context.Context, id, version uint, input domain.UpdateBookInput) (*domain.Book, error) {
 	if id == 0 {
-		return nil, fmt.Errorf("id is required")
-	}
-	// Traer actual
-	current, err := s.bookRepo.GetByID(ctx, id)
-	if err != nil {
-		return nil, fmt.Errorf("book %d not found: %w", id, err)
+		return nil, &domain.ValidationError{Field: "id", Code: domain.CodeRequired, Message: "id is required"}
 	}
+	return s.update(ctx, id, version, func(ctx context.Context, current *domain.Book) (domain.UpdateBookInput, error) {
+		return input, nil
+	}, domain.RevisionUpdate, 0)
+}
 
-	// Validar input opcional
-	if err := domain.ValidateUpdateInput(input); err != nil {
-		return nil, err
-	}
-	// Si cambia ISBN, verificar unicidad
-	if input.ISBN != nil {
-		if other, err := s.bookRepo.GetByISBN(ctx, *input.ISBN); err == nil && other != nil && other.ID != current.ID {
-			return nil, fmt.Errorf("isbn already registered by another book")
+// update es común a UpdateBook y RevertBook: lee el libro, arma el input con el libro
+// actual, lo valida (campos e ISBN único), lo aplica con Book.Update, persiste y deja
+// la revisión, todo en la misma transacción
+func (s *BookService) update(ctx context.Context, id, version uint, inputFor func(ctx context.Context, current *domain.Book) (domain.UpdateBookInput, error), action string, revertedTo uint) (*domain.Book, error) {
+	var updated *domain.Book
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Traer actual (el repositorio devuelve domain.ErrNotFound si no existe)
+		current, err := s.bookRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
 		}
-	}
-	// Aplicar cambios y revalidar
-	current.Update(input)
-	if err := current.ValidateBasic(); err != nil {
-		return nil, err
-	}
-	// Persistir
-	updated, err := s.bookRepo.Update(ctx, current)
+		if version != domain.AnyVersion && current.Version != version {
+			return fmt.Errorf("book %d %w: expected version %d, current is %d", id, domain.ErrVersionMismatch, version, current.Version)
+		}
+
+		input, err := inputFor(ctx, current)
+		if err != nil {
+			return err
+		}
+		// Validar input opcional
+		if err := domain.ValidateUpdateInput(input); err != nil {
+			return err
+		}
+		// Si cambia ISBN, verificar unicidad
+		if input.ISBN != nil {
+			other, err := s.bookRepo.GetByISBN(ctx, *input.ISBN)
+			if err != nil && !errors.Is(err, domain.ErrNotFound) {
+				return err
+			}
+			if other != nil && other.ID != current.ID {
+				return fmt.Errorf("%w: %s", domain.ErrDuplicateISBN, domain.NormalizeISBN(*input.ISBN))
+			}
+		}
+		// Aplicar cambios y revalidar
+		before := *current
+		current.Update(input)
+		if err := current.ValidateBasic(); err != nil {
+			return err
+		}
+		// Persistir
+		if updated, err = s.bookRepo.Update(ctx, current); err != nil {
+			return err
+		}
+		// Un cambio que no cambia ningún campo no deja revisión ni evento
+		changes := domain.DiffBooks(&before, updated)
+		if len(changes) == 0 {
+			return nil
+		}
+		if err := s.record(ctx, id, action, changes, revertedTo); err != nil {
+			return err
+		}
+		return s.events.Publish(ctx, domain.BookUpdated{EventMeta: eventMeta(ctx, domain.EventBookUpdated, updated),
+			Book: *updated, Changes: changes, RevertedTo: revertedTo})
+	})
 	if err != nil {
 		return nil, err
 	}
+	s.autocomplete.clear()
 	return updated, nil
-
 }
 
-// DeleteBook elimina un libro por ID
+// DeleteBook mueve un libro a la papelera; se puede restaurar hasta que se purgue
 func (s *BookService) DeleteBook(ctx context.Context, id uint) error {
 	if id == 0 {
-		return fmt.Errorf("id is required")
+		return &domain.ValidationError{Field: "id", Code: domain.CodeRequired, Message: "id is required"}
 	}
-	// Verificar existencia (opcional, util para 404)
-	if _, err := s.bookRepo.GetByID(ctx, id); err != nil {
-		return fmt.Errorf("book %d not found: %w", id, err)
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Verificar existencia (util para 404) y guardar los valores que se pierden
+		current, err := s.bookRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.bookRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		if err := s.record(ctx, id, domain.RevisionDelete, domain.DiffBooks(current, nil), 0); err != nil {
+			return err
+		}
+		return s.events.Publish(ctx, domain.BookDeleted{EventMeta: eventMeta(ctx, domain.EventBookDeleted, current), Book: *current})
+	})
+	if err != nil {
+		return err
 	}
-	return s.bookRepo.Delete(ctx, id)
+	s.autocomplete.clear()
+	return nil
 }
 
 func (s *BookService) GetBookByID(ctx context.Context, id uint) (*domain.Book, error) {
 	if id == 0 {
-		return nil, fmt.Errorf("id is required")
+		return nil, &domain.ValidationError{Field: "id", Code: domain.CodeRequired, Message: "id is required"}
 	}
 	return s.bookRepo.GetByID(ctx, id)
 }
 
 func (s *BookService) GetBookByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
 	if isbn == "" {
-		return nil, fmt.Errorf("isbn is required")
+		return nil, &domain.ValidationError{Field: "isbn", Code: domain.CodeRequired, Message: "isbn is required"}
 	}
 	// Normalizar ISBN antes de consultar
 	isbn = domain.NormalizeISBN(isbn)
 	return s.bookRepo.GetByISBN(ctx, isbn)
 }
 
-// SearchBooks delega la busqueda al repositorio con filtros
-func (s *BookService) SearchBooks(ctx context.Context, filter domain.BookFilter) ([]*domain.Book, error) {
-	if !filter.HasAny() {
-		return s.bookRepo.GetAll(ctx)
+// SearchBooks delega la busqueda paginada al repositorio con filtros.
+// Un filtro sin criterios devuelve todo el catalogo, pagina a pagina.
+func (s *BookService) SearchBooks(ctx context.Context, filter domain.BookFilter) (*domain.BookPage, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	if err := filter.NormalizePage(); err != nil {
+		return nil, err
+	}
+	page, err := s.bookRepo.FindByFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	// Sin resultados en la primera pagina: proponer titulos/autores parecidos.
+	// Las sugerencias son un extra: si fallan se devuelve la pagina vacia igual
+	if page.Total == 0 && filter.Cursor == "" && filter.Offset == 0 {
+		if page.Suggestions, err = s.suggest(ctx, filter); err != nil {
+			log.Printf("Error building search suggestions: %v", err)
+			page.Suggestions = nil
+		}
+	}
+	return page, nil
+}
+
+// GetFacets cuenta los libros que cumplen el filtro por genero, decada y autor.
+// La paginacion y el orden del filtro no influyen en las cuentas.
+func (s *BookService) GetFacets(ctx context.Context, filter domain.BookFilter) (*domain.BookFacets, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	filter.Limit, filter.Offset, filter.Cursor, filter.Sort = 0, 0, "", nil
+	return s.bookRepo.CountFacets(ctx, filter)
+}
+
+// Autocomplete sugiere titulos o autores que empiezan por el prefijo, de mas a menos frecuente.
+// Las respuestas se cachean brevemente en memoria porque se llama en cada tecla.
+func (s *BookService) Autocomplete(ctx context.Context, field, prefix string, limit int) ([]domain.ValueCount, error) {
+	if err := domain.ValidateAutocompleteField(field); err != nil {
+		return nil, err
+	}
+	prefix = domain.FoldText(prefix)
+	if prefix == "" {
+		return nil, &domain.ValidationError{Field: "prefix", Code: domain.CodeRequired, Message: "prefix is required"}
+	}
+	if limit <= 0 {
+		limit = domain.DefaultAutocompleteLimit
+	}
+	if limit > domain.MaxAutocompleteLimit {
+		limit = domain.MaxAutocompleteLimit
+	}
+
+	key := fmt.Sprintf("%s|%d|%s", field, limit, prefix)
+	if values, ok := s.autocomplete.get(key); ok {
+		return values, nil
+	}
+	values, err := s.bookRepo.SuggestByPrefix(ctx, field, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	s.autocomplete.set(key, values)
+	return values, nil
+}
+
+// suggest busca valores del catalogo parecidos a lo que se pidio en title, author o q.
+// Cada busqueda puntua solo los candidatos que da el repositorio (acotados) y el
+// resultado junta todas sin repetir valores.
+func (s *BookService) suggest(ctx context.Context, filter domain.BookFilter) ([]domain.Suggestion, error) {
+	queries := map[string][]string{}
+	if filter.Title != nil {
+		queries["title"] = append(queries["title"], *filter.Title)
+	}
+	if filter.Author != nil {
+		queries["author"] = append(queries["author"], *filter.Author)
+	}
+	if node, err := domain.ParseQuery(filter.Query); err == nil && node != nil {
+		// Texto libre: puede ser un titulo o un autor mal escrito
+		for _, t := range domain.RankingTerms(node) {
+			queries["title"] = append(queries["title"], t.Text)
+			queries["author"] = append(queries["author"], t.Text)
+		}
+		queries["title"] = append(queries["title"], domain.FieldValues(node, "title")...)
+		queries["author"] = append(queries["author"], domain.FieldValues(node, "author")...)
+	}
+
+	ranked := [][]domain.Suggestion{}
+	for _, field := range []string{"author", "title"} {
+		for _, q := range queries[field] {
+			prefixes := domain.CandidatePrefixes(q)
+			if len(prefixes) == 0 {
+				continue
+			}
+			candidates, err := s.bookRepo.SuggestionCandidates(ctx, field, prefixes, domain.MaxSuggestionCandidates)
+			if err != nil {
+				return nil, err
+			}
+			ranked = append(ranked, domain.RankSuggestions(field, q, candidates))
+		}
 	}
-	return s.bookRepo.FindByFilter(ctx, filter)
+	return domain.MergeSuggestions(ranked...), nil
 }
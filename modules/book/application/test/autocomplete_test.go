@@ -0,0 +1,83 @@
+package application_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/application/mocks"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBookService_Autocomplete_CachesResults(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	values := []domain.ValueCount{{Value: "Gabriel García Márquez", Count: 12}}
+
+	// Mock: el repositorio se consulta una sola vez, el prefijo llega normalizado
+	mockRepo.EXPECT().SuggestByPrefix(ctx, "author", "gar", domain.DefaultAutocompleteLimit).Return(values, nil).Times(1)
+
+	// Act
+	first, err1 := service.Autocomplete(ctx, "author", "GAR", 0)
+	second, err2 := service.Autocomplete(ctx, "author", "gár", 0)
+
+	// Assert
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, values, first)
+	assert.Equal(t, values, second)
+}
+
+func TestBookService_Autocomplete_InvalidatedOnCreate(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	isbn := domain.NormalizeISBN("978-84-18037-01-6")
+
+	mockRepo.EXPECT().SuggestByPrefix(ctx, "title", "cien", 5).Return([]domain.ValueCount{}, nil).Times(2)
+	mockRepo.EXPECT().GetByISBN(ctx, isbn).Return(nil, domain.ErrNotFound)
+	mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+	// Act: un libro nuevo invalida la cache
+	_, err := service.Autocomplete(ctx, "title", "cien", 5)
+	assert.NoError(t, err)
+	_, err = service.CreateBook(ctx, "Cien años de soledad", "Gabriel García Márquez", 1967, "Novela", isbn)
+	assert.NoError(t, err)
+	_, err = service.Autocomplete(ctx, "title", "cien", 5)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestBookService_Autocomplete_InvalidInput(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+
+	// Act
+	_, errField := service.Autocomplete(ctx, "isbn", "978", 0)
+	_, errPrefix := service.Autocomplete(ctx, "author", "   ", 0)
+
+	// Assert
+	assert.Error(t, errField)
+	assert.Contains(t, errField.Error(), "field must be title or author")
+	assert.Error(t, errPrefix)
+	assert.Contains(t, errPrefix.Error(), "prefix is required")
+}
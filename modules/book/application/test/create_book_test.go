@@ -5,7 +5,7 @@ import (
 	"api-go-gestion-libros-hexagonal/modules/book/application/mocks"
 	"api-go-gestion-libros-hexagonal/modules/book/domain"
 	"context"
-	"fmt"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -32,7 +32,7 @@ func TestBookService_CreateBook_Success(t *testing.T) {
 
 	// Crear mock del repositorio
 	mockRepo := mocks.NewMockBookRepository(ctrl)
-	service := application.NewBookService(mockRepo)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
 
 	// Prepara el contexto y datos de prueba
 	ctx := context.Background()
@@ -44,7 +44,7 @@ func TestBookService_CreateBook_Success(t *testing.T) {
 	normalizedISBN := domain.NormalizeISBN(isbn)
 
 	// Mock para verificar que no existe el ISBN
-	mockRepo.EXPECT().GetByISBN(ctx, normalizedISBN).Return(nil, fmt.Errorf("not found"))
+	mockRepo.EXPECT().GetByISBN(ctx, normalizedISBN).Return(nil, domain.ErrNotFound)
 
 	// Mock para la creación del libro
 	mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
@@ -68,7 +68,7 @@ func TestBookService_CreateBook_TitleRequired(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockBookRepository(ctrl)
-	service := application.NewBookService(mockRepo)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
 
 	ctx := context.Background()
 
@@ -76,7 +76,10 @@ func TestBookService_CreateBook_TitleRequired(t *testing.T) {
 	result, err := service.CreateBook(ctx, "", "Autor", 2022, "Ficción", "978-84-18037-01-6")
 
 	// Assert
-	assert.Error(t, err)
+	var validation *domain.ValidationError
+	if assert.ErrorAs(t, err, &validation) {
+		assert.Equal(t, "title", validation.Field)
+	}
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "title is required")
 }
@@ -87,7 +90,7 @@ func TestBookService_CreateBook_AuthorRequired(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockBookRepository(ctrl)
-	service := application.NewBookService(mockRepo)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
 
 	ctx := context.Background()
 
@@ -106,7 +109,7 @@ func TestBookService_CreateBook_ISBNAlreadyExists(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockBookRepository(ctrl)
-	service := application.NewBookService(mockRepo)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
 
 	ctx := context.Background()
 	isbn := "978-84-18037-01-6"
@@ -121,5 +124,71 @@ func TestBookService_CreateBook_ISBNAlreadyExists(t *testing.T) {
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "already exists")
+	assert.ErrorIs(t, err, domain.ErrDuplicateISBN)
+}
+
+func TestBookService_CreateBook_RepositoryErrorIsNotNotFound(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	isbn := domain.NormalizeISBN("978-84-18037-01-6")
+
+	// Mock: la consulta falla por otra razón; no se intenta crear
+	mockRepo.EXPECT().GetByISBN(ctx, isbn).Return(nil, errors.New("database is locked"))
+
+	// Act
+	result, err := service.CreateBook(ctx, "Título", "Autor", 2022, "Ficción", isbn)
+
+	// Assert
+	assert.EqualError(t, err, "database is locked")
+	assert.Nil(t, result)
+}
+
+func TestBookService_CreateBook_ReturnsAllInvalidFields(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	// Act: sin título ni autor, año imposible e ISBN con dígito de control erróneo
+	result, err := service.CreateBook(context.Background(), "", " ", 1200, "Ficción", "9788418037010")
+
+	// Assert: ninguna llamada al repositorio y un error por cada campo
+	assert.Nil(t, result)
+	var errs domain.ValidationErrors
+	if assert.ErrorAs(t, err, &errs) {
+		assert.Equal(t, []string{"title", "author", "year", "isbn"}, fieldsOf(errs))
+		assert.Equal(t, domain.CodeInvalidChecksum, errs[3].Code)
+	}
+}
+
+func TestValidationErrors_Add_ReturnsOtherErrors(t *testing.T) {
+	// Arrange
+	var errs domain.ValidationErrors
+	cause := errors.New("database is locked")
+
+	// Act
+	added := errs.Add(&domain.ValidationError{Field: "year", Code: domain.CodeOutOfRange, Message: "year out of range"})
+	other := errs.Add(cause)
+
+	// Assert: el error que no es de validación vuelve al llamador y no se suma
+	assert.NoError(t, added)
+	assert.Same(t, cause, other)
+	assert.Equal(t, []string{"year"}, fieldsOf(errs))
+}
+
+// fieldsOf devuelve los campos de errs en orden
+func fieldsOf(errs domain.ValidationErrors) []string {
+	fields := make([]string, len(errs))
+	for i, e := range errs {
+		fields[i] = e.Field
+	}
+	return fields
 }
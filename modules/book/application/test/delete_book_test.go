@@ -5,7 +5,6 @@ import (
 	"api-go-gestion-libros-hexagonal/modules/book/application/mocks"
 	"api-go-gestion-libros-hexagonal/modules/book/domain"
 	"context"
-	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -18,7 +17,7 @@ func TestBookService_DeleteBook_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockBookRepository(ctrl)
-	service := application.NewBookService(mockRepo)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
 
 	// preparar el contexto y datos de prueba
 	ctx := context.Background()
@@ -42,21 +41,20 @@ func TestBookService_DeleteBook_BookNotFound(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockBookRepository(ctrl)
-	service := application.NewBookService(mockRepo)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
 
 	// preparar el contexto
 	ctx := context.Background()
 	id := uint(1)
 
 	// mock para verificar existencia
-	mockRepo.EXPECT().GetByID(ctx, id).Return(nil, fmt.Errorf("not found"))
+	mockRepo.EXPECT().GetByID(ctx, id).Return(nil, domain.ErrNotFound)
 
 	// Act
 	err := service.DeleteBook(ctx, id)
 
 	// Assert
-	assert.Error(t, err)                         // error esperado
-	assert.Contains(t, err.Error(), "not found") // mensaje de error esperado
+	assert.ErrorIs(t, err, domain.ErrNotFound) // error esperado
 
 }
 
@@ -65,7 +63,7 @@ func TestBookService_DeleteBook_ZeroID(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockBookRepository(ctrl)
-	service := application.NewBookService(mockRepo)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
 
 	// preparar el contexto
 	ctx := context.Background()
@@ -0,0 +1,155 @@
+package application_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/application/mocks"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// discardEvents acepta los eventos sin entregarlos, para los tests que no miran los eventos
+type discardEvents struct{}
+
+func (discardEvents) Publish(ctx context.Context, events ...domain.Event) error { return nil }
+
+// recordEvents guarda los eventos publicados; err, si no es nil, es lo que devuelve Publish
+type recordEvents struct {
+	events []domain.Event
+	err    error
+}
+
+func (r *recordEvents) Publish(ctx context.Context, events ...domain.Event) error {
+	r.events = append(r.events, events...)
+	return r.err
+}
+
+func TestBookService_CreateBook_PublishesBookCreated(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	events := &recordEvents{}
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, events)
+
+	ctx := application.WithActor(context.Background(), "ana")
+	book := ficciones()
+	mockRepo.EXPECT().GetByISBN(ctx, book.ISBN).Return(nil, domain.ErrNotFound)
+	mockRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, b *domain.Book) error {
+		b.ID = 7
+		return nil
+	})
+
+	// Act
+	_, err := service.CreateBook(ctx, book.Title, book.Author, book.Year, book.Genre, book.ISBN)
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, events.events, 1) {
+		created, ok := events.events[0].(domain.BookCreated)
+		if assert.True(t, ok) {
+			assert.Equal(t, uint(7), created.BookID)
+			assert.Equal(t, domain.EventID(domain.EventBookCreated, 7, created.Book.Version), created.ID)
+			assert.Equal(t, "ana", created.Actor)
+			assert.Equal(t, "Ficciones", created.Book.Title)
+		}
+	}
+}
+
+func TestBookService_UpdateBook_PublishesChangedFields(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	events := &recordEvents{}
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, events)
+
+	ctx := context.Background()
+	updated := ficciones()
+	updated.Year = 1956
+	year := uint(1956)
+
+	mockRepo.EXPECT().GetByID(ctx, uint(7)).Return(ficciones(), nil)
+	mockRepo.EXPECT().Update(ctx, gomock.Any()).Return(updated, nil)
+
+	// Act
+	_, err := service.UpdateBook(ctx, 7, 2, domain.UpdateBookInput{Year: &year})
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.Len(t, events.events, 1) {
+		assert.Equal(t, domain.EventBookUpdated, events.events[0].EventName())
+		assert.Equal(t, []domain.FieldChange{{Field: "year", From: "1944", To: "1956"}}, events.events[0].(domain.BookUpdated).Changes)
+	}
+}
+
+func TestBookService_UpdateBook_NoChangesPublishesNothing(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	events := &recordEvents{}
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, events)
+
+	ctx := context.Background()
+	title := "Ficciones"
+	mockRepo.EXPECT().GetByID(ctx, uint(7)).Return(ficciones(), nil)
+	mockRepo.EXPECT().Update(ctx, gomock.Any()).Return(ficciones(), nil)
+
+	// Act
+	_, err := service.UpdateBook(ctx, 7, domain.AnyVersion, domain.UpdateBookInput{Title: &title})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, events.events)
+}
+
+func TestBookService_DeleteBook_HistoryErrorPublishesNothing(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	mockHistory := mocks.NewMockRevisionRepository(ctrl)
+	events := &recordEvents{}
+	service := application.NewBookService(mockRepo, mockHistory, inlineTx{}, events)
+
+	ctx := context.Background()
+	mockRepo.EXPECT().GetByID(ctx, uint(7)).Return(ficciones(), nil)
+	mockRepo.EXPECT().Delete(ctx, uint(7)).Return(nil)
+	mockHistory.EXPECT().Append(ctx, gomock.Any()).Return(errors.New("disk full"))
+
+	// Act
+	err := service.DeleteBook(ctx, 7)
+
+	// Assert: la transacción falló antes de guardar el evento
+	assert.Error(t, err)
+	assert.Empty(t, events.events)
+}
+
+func TestBookService_DeleteBook_OutboxErrorFails(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	events := &recordEvents{err: errors.New("outbox unavailable")}
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, events)
+
+	ctx := context.Background()
+	mockRepo.EXPECT().GetByID(ctx, uint(7)).Return(ficciones(), nil)
+	mockRepo.EXPECT().Delete(ctx, uint(7)).Return(nil)
+
+	// Act
+	err := service.DeleteBook(ctx, 7)
+
+	// Assert: el evento se guarda en la transacción del borrado; sin evento no hay borrado
+	assert.ErrorContains(t, err, "outbox unavailable")
+}
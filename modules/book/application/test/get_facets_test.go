@@ -0,0 +1,59 @@
+package application_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/application/mocks"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBookService_GetFacets_IgnoresPagination(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	author := "garcía"
+	sort, _ := domain.ParseSort("-year")
+	filter := domain.BookFilter{Author: &author, Limit: 5, Offset: 10, Sort: sort}
+
+	facets := domain.NewBookFacets()
+	facets.Genre["Novela"] = 2
+	facets.Decade["1960"] = 1
+
+	// Mock: el repositorio recibe solo los criterios, sin paginación ni orden
+	mockRepo.EXPECT().CountFacets(ctx, domain.BookFilter{Author: &author}).Return(facets, nil)
+
+	// Act
+	result, err := service.GetFacets(ctx, filter)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Genre["Novela"])
+	assert.Equal(t, 1, result.Decade["1960"])
+}
+
+func TestBookService_GetFacets_InvalidFilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	from, to := uint(2000), uint(1990)
+
+	// Act
+	result, err := service.GetFacets(ctx, domain.BookFilter{YearFrom: &from, YearTo: &to})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
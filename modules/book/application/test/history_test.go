@@ -0,0 +1,340 @@
+package application_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/application/mocks"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// discardHistory acepta las revisiones sin guardarlas, para los tests que no miran el historial
+type discardHistory struct{}
+
+func (discardHistory) Append(ctx context.Context, rev *domain.Revision) error { return nil }
+
+func (discardHistory) ListByBook(ctx context.Context, bookID uint) ([]*domain.Revision, error) {
+	return nil, nil
+}
+
+// ficciones es el libro de los tests de historial, en la versión 2
+func ficciones() *domain.Book {
+	return &domain.Book{ID: 7, Title: "Ficciones", Author: "Jorge Luis Borges", Year: 1944, Genre: "Cuento",
+		ISBN: "9788420633114", Version: 2}
+}
+
+func TestBookService_CreateBook_RecordsRevisionWithActor(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	mockHistory := mocks.NewMockRevisionRepository(ctrl)
+	service := application.NewBookService(mockRepo, mockHistory, inlineTx{}, discardEvents{})
+
+	ctx := application.WithActor(context.Background(), "ana")
+	book := ficciones()
+
+	mockRepo.EXPECT().GetByISBN(ctx, book.ISBN).Return(nil, domain.ErrNotFound)
+	mockRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, b *domain.Book) error {
+		b.ID = 7
+		return nil
+	})
+	var recorded *domain.Revision
+	mockHistory.EXPECT().Append(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, rev *domain.Revision) error {
+		recorded = rev
+		return nil
+	})
+
+	// Act
+	_, err := service.CreateBook(ctx, book.Title, book.Author, book.Year, book.Genre, book.ISBN)
+
+	// Assert: al crear, todos los campos pasan de vacío a su valor
+	assert.NoError(t, err)
+	if assert.NotNil(t, recorded) {
+		assert.Equal(t, uint(7), recorded.BookID)
+		assert.Equal(t, domain.RevisionCreate, recorded.Action)
+		assert.Equal(t, "ana", recorded.Actor)
+		assert.Len(t, recorded.Changes, 5)
+		assert.Equal(t, domain.FieldChange{Field: "year", From: "", To: "1944"}, recorded.Changes[2])
+	}
+}
+
+func TestBookService_UpdateBook_RecordsOnlyChangedFields(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	mockHistory := mocks.NewMockRevisionRepository(ctrl)
+	service := application.NewBookService(mockRepo, mockHistory, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	updated := ficciones()
+	updated.Title = "Ficciones (1944)"
+
+	mockRepo.EXPECT().GetByID(ctx, uint(7)).Return(ficciones(), nil)
+	mockRepo.EXPECT().Update(ctx, gomock.Any()).Return(updated, nil)
+	mockHistory.EXPECT().Append(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, rev *domain.Revision) error {
+		assert.Equal(t, domain.RevisionUpdate, rev.Action)
+		assert.Equal(t, application.AnonymousActor, rev.Actor)
+		assert.Equal(t, []domain.FieldChange{{Field: "title", From: "Ficciones", To: "Ficciones (1944)"}}, rev.Changes)
+		return nil
+	})
+
+	// Act
+	_, err := service.UpdateBook(ctx, 7, 2, domain.UpdateBookInput{Title: &updated.Title})
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestBookService_DeleteBook_RecordsRemovedValues(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	mockHistory := mocks.NewMockRevisionRepository(ctrl)
+	service := application.NewBookService(mockRepo, mockHistory, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	mockRepo.EXPECT().GetByID(ctx, uint(7)).Return(ficciones(), nil)
+	mockRepo.EXPECT().Delete(ctx, uint(7)).Return(nil)
+	mockHistory.EXPECT().Append(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, rev *domain.Revision) error {
+		assert.Equal(t, domain.RevisionDelete, rev.Action)
+		assert.Equal(t, domain.FieldChange{Field: "title", From: "Ficciones", To: ""}, rev.Changes[0])
+		return nil
+	})
+
+	// Act
+	err := service.DeleteBook(ctx, 7)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestBookService_RevertBook_AppliesStateOfRevision(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	mockHistory := mocks.NewMockRevisionRepository(ctrl)
+	service := application.NewBookService(mockRepo, mockHistory, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	current := ficciones()
+	current.Title, current.Year = "Ficciones (1944)", 1956
+	history := []*domain.Revision{
+		{BookID: 7, Number: 1, Action: domain.RevisionCreate, Changes: domain.DiffBooks(nil, ficciones())},
+		{BookID: 7, Number: 2, Action: domain.RevisionUpdate, Changes: []domain.FieldChange{
+			{Field: "title", From: "Ficciones", To: "Ficciones (1944)"},
+			{Field: "year", From: "1944", To: "1956"},
+		}},
+	}
+
+	mockRepo.EXPECT().GetByID(ctx, uint(7)).Return(current, nil)
+	mockHistory.EXPECT().ListByBook(ctx, uint(7)).Return(history, nil)
+	mockRepo.EXPECT().GetByISBN(ctx, current.ISBN).Return(current, nil)
+	mockRepo.EXPECT().Update(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, b *domain.Book) (*domain.Book, error) {
+		out := *b
+		out.Version++
+		return &out, nil
+	})
+	mockHistory.EXPECT().Append(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, rev *domain.Revision) error {
+		assert.Equal(t, domain.RevisionRevert, rev.Action)
+		assert.Equal(t, uint(1), rev.RevertedTo)
+		assert.Len(t, rev.Changes, 2)
+		return nil
+	})
+
+	// Act
+	result, err := service.RevertBook(ctx, 7, 1, domain.AnyVersion)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Ficciones", result.Title)
+	assert.Equal(t, uint(1944), result.Year)
+}
+
+func TestBookService_RevertBook_ValidatesRestoredState(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	mockHistory := mocks.NewMockRevisionRepository(ctrl)
+	service := application.NewBookService(mockRepo, mockHistory, inlineTx{}, discardEvents{})
+
+	// La revisión 1 es anterior a una regla nueva: su ISBN ya no es válido
+	ctx := context.Background()
+	old := ficciones()
+	old.ISBN = "9788420633110"
+	history := []*domain.Revision{{BookID: 7, Number: 1, Action: domain.RevisionCreate, Changes: domain.DiffBooks(nil, old)}}
+
+	mockRepo.EXPECT().GetByID(ctx, uint(7)).Return(ficciones(), nil)
+	mockHistory.EXPECT().ListByBook(ctx, uint(7)).Return(history, nil)
+
+	// Act
+	result, err := service.RevertBook(ctx, 7, 1, domain.AnyVersion)
+
+	// Assert: no se escribe nada
+	assert.Nil(t, result)
+	var validation *domain.ValidationError
+	if assert.ErrorAs(t, err, &validation) {
+		assert.Equal(t, "isbn", validation.Field)
+	}
+}
+
+func TestBookService_RevertBook_ISBNTakenByAnotherBook(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	mockHistory := mocks.NewMockRevisionRepository(ctrl)
+	service := application.NewBookService(mockRepo, mockHistory, inlineTx{}, discardEvents{})
+
+	// Después de la revisión 1 el libro cambió de ISBN y otro libro tomó el viejo
+	ctx := context.Background()
+	old := ficciones()
+	old.ISBN = "9788418037023"
+	current := ficciones()
+	history := []*domain.Revision{
+		{BookID: 7, Number: 1, Action: domain.RevisionCreate, Changes: domain.DiffBooks(nil, old)},
+		{BookID: 7, Number: 2, Action: domain.RevisionUpdate, Changes: []domain.FieldChange{
+			{Field: "isbn", From: old.ISBN, To: current.ISBN},
+		}},
+	}
+	other := &domain.Book{ID: 9, Title: "Otro", Author: "Otro Autor", Year: 2001, ISBN: old.ISBN}
+
+	mockRepo.EXPECT().GetByID(ctx, uint(7)).Return(current, nil)
+	mockHistory.EXPECT().ListByBook(ctx, uint(7)).Return(history, nil)
+	mockRepo.EXPECT().GetByISBN(ctx, old.ISBN).Return(other, nil)
+
+	// Act
+	result, err := service.RevertBook(ctx, 7, 1, domain.AnyVersion)
+
+	// Assert: 409 como en UpdateBook y no se escribe nada
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, domain.ErrDuplicateISBN)
+}
+
+func TestBookService_RevertBook_HistoryStartsWithUpdate(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	mockHistory := mocks.NewMockRevisionRepository(ctrl)
+	service := application.NewBookService(mockRepo, mockHistory, inlineTx{}, discardEvents{})
+
+	// El libro es anterior al historial: no hay revisión create
+	ctx := context.Background()
+	current := ficciones()
+	current.Title, current.Year = "Ficciones (1944)", 1956
+	history := []*domain.Revision{
+		{BookID: 7, Number: 1, Action: domain.RevisionUpdate, Changes: []domain.FieldChange{
+			{Field: "title", From: "Ficciones", To: "Ficciones (1944)"},
+		}},
+		{BookID: 7, Number: 2, Action: domain.RevisionUpdate, Changes: []domain.FieldChange{
+			{Field: "year", From: "1944", To: "1956"},
+		}},
+	}
+
+	mockRepo.EXPECT().GetByID(ctx, uint(7)).Return(current, nil)
+	mockHistory.EXPECT().ListByBook(ctx, uint(7)).Return(history, nil)
+	mockRepo.EXPECT().GetByISBN(ctx, current.ISBN).Return(current, nil)
+	mockRepo.EXPECT().Update(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, b *domain.Book) (*domain.Book, error) {
+		out := *b
+		out.Version++
+		return &out, nil
+	})
+	mockHistory.EXPECT().Append(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, rev *domain.Revision) error {
+		assert.Equal(t, []domain.FieldChange{{Field: "year", From: "1956", To: "1944"}}, rev.Changes)
+		return nil
+	})
+
+	// Act
+	result, err := service.RevertBook(ctx, 7, 1, domain.AnyVersion)
+
+	// Assert: el año sale del cambio posterior y el resto del libro actual
+	assert.NoError(t, err)
+	assert.Equal(t, "Ficciones (1944)", result.Title)
+	assert.Equal(t, uint(1944), result.Year)
+	assert.Equal(t, current.Author, result.Author)
+	assert.Equal(t, current.ISBN, result.ISBN)
+}
+
+func TestBookService_RevertBook_InvalidRevisionIsValidationError(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	mockHistory := mocks.NewMockRevisionRepository(ctrl)
+	service := application.NewBookService(mockRepo, mockHistory, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	history := []*domain.Revision{{BookID: 7, Number: 1, Action: domain.RevisionUpdate, Changes: []domain.FieldChange{
+		{Field: "year", From: "1944", To: ""},
+	}}}
+	mockRepo.EXPECT().GetByID(ctx, uint(7)).Return(ficciones(), nil)
+	mockHistory.EXPECT().ListByBook(ctx, uint(7)).Return(history, nil)
+
+	// Act
+	result, err := service.RevertBook(ctx, 7, 1, domain.AnyVersion)
+
+	// Assert: 422, no 500
+	assert.Nil(t, result)
+	var validation *domain.ValidationError
+	if assert.ErrorAs(t, err, &validation) {
+		assert.Equal(t, "revision", validation.Field)
+		assert.Equal(t, domain.CodeInvalidValue, validation.Code)
+	}
+}
+
+func TestBookService_RevertBook_UnknownRevision(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	mockHistory := mocks.NewMockRevisionRepository(ctrl)
+	service := application.NewBookService(mockRepo, mockHistory, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	mockRepo.EXPECT().GetByID(ctx, uint(7)).Return(ficciones(), nil)
+	mockHistory.EXPECT().ListByBook(ctx, uint(7)).Return([]*domain.Revision{}, nil)
+
+	// Act
+	_, err := service.RevertBook(ctx, 7, 3, domain.AnyVersion)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestBookService_GetBookHistory_UnknownBook(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	mockHistory := mocks.NewMockRevisionRepository(ctrl)
+	service := application.NewBookService(mockRepo, mockHistory, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	mockHistory.EXPECT().ListByBook(ctx, uint(9)).Return([]*domain.Revision{}, nil)
+	mockRepo.EXPECT().GetByID(ctx, uint(9)).Return(nil, domain.ErrNotFound)
+
+	// Act
+	revs, err := service.GetBookHistory(ctx, 9)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.Nil(t, revs)
+}
@@ -0,0 +1,173 @@
+package application_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/application/mocks"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+var testPolicy = domain.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+
+// deletedMessage es el mensaje del outbox de borrar ficciones(), con attempts intentos fallidos
+func deletedMessage(t *testing.T, attempts int) *domain.OutboxMessage {
+	book := ficciones()
+	event := domain.BookDeleted{
+		EventMeta: domain.EventMeta{ID: domain.EventID(domain.EventBookDeleted, book.ID, book.Version), BookID: book.ID, OccurredAt: time.Now().UTC()},
+		Book:      *book,
+	}
+	msg, err := domain.NewOutboxMessage(event)
+	require.NoError(t, err)
+	msg.ID, msg.Attempts = 1, attempts
+	return msg
+}
+
+func TestOutboxPublisher_AddsOneMessagePerEvent(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOutbox := mocks.NewMockOutboxRepository(ctrl)
+	publisher := application.NewOutboxPublisher(mockOutbox)
+	ctx := context.Background()
+	event := domain.BookRestored{EventMeta: domain.EventMeta{ID: "book.restored:7:4", BookID: 7}, Book: *ficciones()}
+
+	var added *domain.OutboxMessage
+	mockOutbox.EXPECT().Add(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, msg *domain.OutboxMessage) error {
+		added = msg
+		return nil
+	})
+
+	// Act
+	err := publisher.Publish(ctx, event)
+
+	// Assert
+	assert.NoError(t, err)
+	if assert.NotNil(t, added) {
+		assert.Equal(t, "book.restored:7:4", added.DedupeKey)
+		assert.Equal(t, domain.EventBookRestored, added.EventName)
+		assert.Equal(t, uint(7), added.BookID)
+	}
+}
+
+func TestOutboxRelay_DeliversAndMarksPublished(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOutbox := mocks.NewMockOutboxRepository(ctrl)
+	bus := &recordEvents{}
+	relay := application.NewOutboxRelay(mockOutbox, bus, testPolicy)
+	ctx := context.Background()
+
+	mockOutbox.EXPECT().Due(ctx, gomock.Any(), application.DefaultOutboxBatch).Return([]*domain.OutboxMessage{deletedMessage(t, 0)}, nil)
+	mockOutbox.EXPECT().MarkPublished(ctx, uint(1), gomock.Any()).Return(nil)
+
+	// Act
+	n, err := relay.Relay(ctx)
+
+	// Assert: el suscriptor recibe el evento tal como se guardó
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	if assert.Len(t, bus.events, 1) {
+		deleted := bus.events[0].(domain.BookDeleted)
+		assert.Equal(t, "book.deleted:7:2", deleted.ID)
+		assert.Equal(t, "Ficciones", deleted.Book.Title)
+	}
+}
+
+func TestOutboxRelay_SubscriberErrorSchedulesRetry(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOutbox := mocks.NewMockOutboxRepository(ctrl)
+	relay := application.NewOutboxRelay(mockOutbox, &recordEvents{err: errors.New("index unavailable")}, testPolicy)
+	ctx := context.Background()
+
+	mockOutbox.EXPECT().Due(ctx, gomock.Any(), gomock.Any()).Return([]*domain.OutboxMessage{deletedMessage(t, 1)}, nil)
+	var retryAt time.Time
+	mockOutbox.EXPECT().MarkRetry(ctx, uint(1), "index unavailable", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uint, _ string, at time.Time) error {
+			retryAt = at
+			return nil
+		})
+
+	// Act
+	n, err := relay.Relay(ctx)
+
+	// Assert: es el segundo fallo, así que espera el doble de BaseDelay
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+	assert.WithinDuration(t, time.Now().Add(2*time.Minute), retryAt, 5*time.Second)
+}
+
+func TestOutboxRelay_GivesUpAfterMaxAttempts(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOutbox := mocks.NewMockOutboxRepository(ctrl)
+	relay := application.NewOutboxRelay(mockOutbox, &recordEvents{err: errors.New("index unavailable")}, testPolicy)
+	ctx := context.Background()
+
+	mockOutbox.EXPECT().Due(ctx, gomock.Any(), gomock.Any()).Return([]*domain.OutboxMessage{deletedMessage(t, 2)}, nil)
+	mockOutbox.EXPECT().MarkFailed(ctx, uint(1), "index unavailable", gomock.Any()).Return(nil)
+
+	// Act
+	_, err := relay.Relay(ctx)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestOutboxRelay_UnknownEventFailsWithoutRetry(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOutbox := mocks.NewMockOutboxRepository(ctrl)
+	bus := &recordEvents{}
+	relay := application.NewOutboxRelay(mockOutbox, bus, testPolicy)
+	ctx := context.Background()
+
+	msg := deletedMessage(t, 0)
+	msg.EventName = "book.archived"
+	mockOutbox.EXPECT().Due(ctx, gomock.Any(), gomock.Any()).Return([]*domain.OutboxMessage{msg}, nil)
+	mockOutbox.EXPECT().MarkFailed(ctx, uint(1), gomock.Any(), gomock.Any()).Return(nil)
+
+	// Act
+	_, err := relay.Relay(ctx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, bus.events)
+}
+
+func TestOutboxRelay_BacklogClampsLimit(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOutbox := mocks.NewMockOutboxRepository(ctrl)
+	relay := application.NewOutboxRelay(mockOutbox, &recordEvents{}, testPolicy)
+	ctx := context.Background()
+
+	mockOutbox.EXPECT().Backlog(ctx, application.DefaultOutboxBacklogLimit).Return(&domain.OutboxBacklog{}, nil)
+	mockOutbox.EXPECT().Backlog(ctx, application.MaxOutboxBacklogLimit).Return(&domain.OutboxBacklog{}, nil)
+
+	// Act
+	_, errDefault := relay.Backlog(ctx, 0)
+	_, errMax := relay.Backlog(ctx, 10_000)
+
+	// Assert
+	assert.NoError(t, errDefault)
+	assert.NoError(t, errMax)
+}
@@ -0,0 +1,138 @@
+package application_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/application/mocks"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestParseQuery_Example(t *testing.T) {
+	// Act
+	node, err := domain.ParseQuery(`author:"Borges" AND (genre:cuento OR genre:ensayo) AND year>=1940 -title:antología`)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.AndNode{Children: []domain.QueryNode{
+		domain.FieldTerm{Field: "author", Op: ":", Value: "Borges", Pos: 1},
+		domain.OrNode{Children: []domain.QueryNode{
+			domain.FieldTerm{Field: "genre", Op: ":", Value: "cuento", Pos: 22},
+			domain.FieldTerm{Field: "genre", Op: ":", Value: "ensayo", Pos: 38},
+		}},
+		domain.FieldTerm{Field: "year", Op: ">=", Value: "1940", Pos: 56},
+		domain.NotNode{Child: domain.FieldTerm{Field: "title", Op: ":", Value: "antología", Pos: 68}},
+	}}, node)
+}
+
+func TestParseQuery_ImplicitAndAndLowercaseOperators(t *testing.T) {
+	// Act
+	node, err := domain.ParseQuery(`guerra y paz or NOT "tolstoi"`)
+
+	// Assert: "y" y "or" en minúsculas son palabras, no operadores
+	assert.NoError(t, err)
+	assert.Equal(t, domain.AndNode{Children: []domain.QueryNode{
+		domain.TextTerm{Text: "guerra", Pos: 1},
+		domain.TextTerm{Text: "y", Pos: 8},
+		domain.TextTerm{Text: "paz", Pos: 10},
+		domain.TextTerm{Text: "or", Pos: 14},
+		domain.NotNode{Child: domain.TextTerm{Text: "tolstoi", Phrase: true, Pos: 21}},
+	}}, node)
+	assert.Len(t, domain.RankingTerms(node), 4)
+}
+
+func TestParseQuery_HyphenInsideWordIsNotNegation(t *testing.T) {
+	// Act
+	node, err := domain.ParseQuery(`isbn:978-84`)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, domain.FieldTerm{Field: "isbn", Op: ":", Value: "978-84", Pos: 1}, node)
+}
+
+func TestParseQuery_ColonAfterUnknownFieldIsText(t *testing.T) {
+	// Act
+	node, err := domain.ParseQuery(`Harry Potter: la piedra editorial:planeta`)
+
+	// Assert: solo los campos conocidos llevan ":"; el resto es texto libre
+	assert.NoError(t, err)
+	assert.Equal(t, domain.AndNode{Children: []domain.QueryNode{
+		domain.TextTerm{Text: "Harry", Pos: 1},
+		domain.TextTerm{Text: "Potter", Pos: 7},
+		domain.TextTerm{Text: "la", Pos: 15},
+		domain.TextTerm{Text: "piedra", Pos: 18},
+		domain.TextTerm{Text: "editorial", Pos: 25},
+		domain.TextTerm{Text: "planeta", Pos: 35},
+	}}, node)
+}
+
+func TestParseQuery_SyntaxErrors(t *testing.T) {
+	cases := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{`author:borges AND (genre:cuento`, 19, "missing closing parenthesis"},
+		{`title:"cien años`, 7, "unterminated quote"},
+		{`title>1900`, 6, `operator ">" not allowed for title`},
+		{`year>=mil`, 7, `year must be a number, found "mil"`},
+		{`borges OR`, 10, "expected term after OR"},
+		{`borges)`, 7, "unexpected ')'"},
+		{`()`, 2, "empty parentheses"},
+		{`author: borges`, 9, "expected value after author:"},
+	}
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			// Act
+			_, err := domain.ParseQuery(c.query)
+
+			// Assert
+			var syntaxErr *domain.QuerySyntaxError
+			assert.True(t, errors.As(err, &syntaxErr))
+			assert.Equal(t, c.pos, syntaxErr.Pos)
+			assert.Equal(t, c.msg, syntaxErr.Msg)
+		})
+	}
+}
+
+func TestBookService_SearchBooks_InvalidQuery(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	// Mock: con error de sintaxis no se consulta el repositorio
+	mockRepo.EXPECT().FindByFilter(gomock.Any(), gomock.Any()).Times(0)
+
+	// Act
+	_, err := service.SearchBooks(context.Background(), domain.BookFilter{Query: "year>>1940"})
+
+	// Assert
+	assert.EqualError(t, err, `query syntax error at position 6: expected value after year>`)
+}
+
+func TestBookService_SearchBooks_QueryWithoutTextKeepsDefaultSort(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	filter := domain.BookFilter{Query: "author:borges -cuentos"}
+
+	// Act
+	sort := filter.EffectiveSort()
+	mockRepo.EXPECT().FindByFilter(gomock.Any(), gomock.Any()).Return(&domain.BookPage{Total: 1}, nil)
+	_, err := service.SearchBooks(context.Background(), filter)
+
+	// Assert: sin texto libre positivo no hay relevancia que ordenar
+	assert.NoError(t, err)
+	assert.Empty(t, sort)
+}
@@ -0,0 +1,396 @@
+package application_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/application/mocks"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBookService_SearchBooks_DefaultLimit(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	expected := domain.BookFilter{Limit: domain.DefaultPageLimit}
+	page := &domain.BookPage{Books: []*domain.Book{{ID: 1}}, Total: 1}
+
+	// Mock: el servicio aplica el limit por defecto antes de consultar
+	mockRepo.EXPECT().FindByFilter(ctx, expected).Return(page, nil)
+
+	// Act
+	result, err := service.SearchBooks(ctx, domain.BookFilter{})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Total)
+	assert.False(t, result.HasMore)
+}
+
+func TestBookService_SearchBooks_LimitIsCapped(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	expected := domain.BookFilter{Limit: domain.MaxPageLimit}
+
+	mockRepo.EXPECT().FindByFilter(ctx, expected).Return(&domain.BookPage{}, nil)
+
+	// Act
+	_, err := service.SearchBooks(ctx, domain.BookFilter{Limit: 10000})
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestBookService_SearchBooks_WithCursor(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	cursor := domain.EncodeCursor(domain.NewCursor(&domain.Book{ID: 42}, nil))
+	filter := domain.BookFilter{Limit: 10, Cursor: cursor}
+
+	mockRepo.EXPECT().FindByFilter(ctx, filter).Return(&domain.BookPage{}, nil)
+
+	// Act
+	_, err := service.SearchBooks(ctx, filter)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestBookService_SearchBooks_InvalidPagination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+
+	cases := map[string]domain.BookFilter{
+		"invalid cursor":          {Cursor: "no-es-un-cursor"},
+		"limit must be":           {Limit: -1},
+		"offset must be":          {Offset: -5},
+		"cannot be used together": {Offset: 10, Cursor: domain.EncodeCursor(domain.NewCursor(&domain.Book{ID: 3}, nil))},
+	}
+
+	for msg, filter := range cases {
+		// Act
+		result, err := service.SearchBooks(ctx, filter)
+
+		// Assert: nunca llega al repositorio
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), msg)
+	}
+}
+
+func TestBookService_SearchBooks_SortedCursor(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	sort, err := domain.ParseSort("-year,title")
+	assert.NoError(t, err)
+
+	last := &domain.Book{ID: 7, Title: "Ficciones", Year: 1944}
+	filter := domain.BookFilter{Limit: 10, Sort: sort, Cursor: domain.EncodeCursor(domain.NewCursor(last, sort))}
+
+	mockRepo.EXPECT().FindByFilter(ctx, filter).Return(&domain.BookPage{}, nil)
+
+	// Act
+	_, err = service.SearchBooks(ctx, filter)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestBookService_SearchBooks_CursorFromOtherSort(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	byYear, _ := domain.ParseSort("-year")
+	byTitle, _ := domain.ParseSort("title")
+
+	// Cursor generado con otro orden: no se puede reutilizar
+	cursor := domain.EncodeCursor(domain.NewCursor(&domain.Book{ID: 7, Year: 1944}, byYear))
+
+	// Act
+	result, err := service.SearchBooks(ctx, domain.BookFilter{Sort: byTitle, Cursor: cursor})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "cursor does not match sort")
+}
+
+func TestParseSort_RejectsUnknownField(t *testing.T) {
+	_, err := domain.ParseSort("-year,password")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot sort by")
+}
+
+func TestBookService_SearchBooks_InvalidFilter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	from, to := uint(1990), uint(1950)
+	prefix := "978-84%"
+	later := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	earlier := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]domain.BookFilter{
+		"year_from cannot be greater": {YearFrom: &from, YearTo: &to},
+		"isbn_prefix must contain":    {ISBNPrefix: &prefix},
+		"created_after must be":       {CreatedAfter: &later, CreatedBefore: &earlier},
+		"updated_since must be":       {UpdatedSince: &later, UpdatedBefore: &earlier},
+	}
+
+	for msg, filter := range cases {
+		// Act
+		result, err := service.SearchBooks(ctx, filter)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), msg)
+	}
+}
+
+func TestBookFilter_HasAny(t *testing.T) {
+	prefix := "97884"
+	since := time.Now().UTC()
+
+	assert.False(t, domain.BookFilter{Limit: 10, Cursor: "x"}.HasAny())
+	assert.True(t, domain.BookFilter{Genres: []string{"cuento"}}.HasAny())
+	assert.True(t, domain.BookFilter{ISBNPrefix: &prefix}.HasAny())
+	assert.True(t, domain.BookFilter{UpdatedSince: &since}.HasAny())
+}
+
+func TestBookService_SearchBooks_FullTextCursorUsesRelevance(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	last := &domain.Book{ID: 3}
+	relevance := []domain.SortField{{Field: domain.RelevanceField}}
+	filter := domain.BookFilter{
+		Query:  "garcia marq",
+		Limit:  10,
+		Cursor: domain.EncodeCursor(domain.NewRankedCursor(last, -1.25, relevance)),
+	}
+
+	mockRepo.EXPECT().FindByFilter(ctx, filter).Return(&domain.BookPage{}, nil)
+
+	// Act
+	_, err := service.SearchBooks(ctx, filter)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestBookService_SearchBooks_FullTextRejectsPlainCursor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+
+	// Cursor de un listado sin q: el orden por relevancia no coincide
+	cursor := domain.EncodeCursor(domain.NewCursor(&domain.Book{ID: 3}, nil))
+
+	// Act
+	result, err := service.SearchBooks(ctx, domain.BookFilter{Query: "borges", Cursor: cursor})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "cursor does not match sort")
+}
+
+func TestFullTextTerms(t *testing.T) {
+	assert.Equal(t, []string{"garcía", "márquez", "cien"}, domain.FullTextTerms(`  García-Márquez "cien" `))
+	assert.Empty(t, domain.FullTextTerms(` *"()- `))
+}
+
+func TestFoldText(t *testing.T) {
+	assert.Equal(t, "ficcion", domain.FoldText("Ficción"))
+	assert.Equal(t, "garcia marquez", domain.FoldText("  GARCÍA   MÁRQUEZ "))
+	assert.Equal(t, "pinguino espanol", domain.FoldText("Pingüino Español"))
+	assert.Equal(t, domain.FoldText("titulo"), domain.FoldText("Título"))
+}
+
+func TestBookService_SearchBooks_SuggestsWhenEmpty(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	author := "Borjes"
+	filter := domain.BookFilter{Author: &author, Limit: domain.DefaultPageLimit}
+
+	// Mock: la búsqueda no encuentra nada
+	mockRepo.EXPECT().FindByFilter(ctx, filter).Return(&domain.BookPage{Books: []*domain.Book{}}, nil)
+
+	// Mock: autores del catálogo con alguna palabra que empieza por "bo"
+	mockRepo.EXPECT().SuggestionCandidates(ctx, "author", []string{"bo"}, domain.MaxSuggestionCandidates).
+		Return([]string{"Jorge Luis Borges", "Adolfo Bioy Casares"}, nil)
+
+	// Act
+	page, err := service.SearchBooks(ctx, filter)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, page.Suggestions, 1)
+	assert.Equal(t, "author", page.Suggestions[0].Field)
+	assert.Equal(t, "Jorge Luis Borges", page.Suggestions[0].Value)
+}
+
+func TestBookService_SearchBooks_SuggestionErrorKeepsEmptyPage(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	author := "Borjes"
+	filter := domain.BookFilter{Author: &author, Limit: domain.DefaultPageLimit}
+	mockRepo.EXPECT().FindByFilter(ctx, filter).Return(&domain.BookPage{Books: []*domain.Book{}}, nil)
+	mockRepo.EXPECT().SuggestionCandidates(ctx, "author", gomock.Any(), gomock.Any()).Return(nil, errors.New("database is locked"))
+
+	// Act
+	page, err := service.SearchBooks(ctx, filter)
+
+	// Assert: la búsqueda vacía sigue siendo válida
+	assert.NoError(t, err)
+	assert.Empty(t, page.Books)
+	assert.Empty(t, page.Suggestions)
+}
+
+func TestBookService_SearchBooks_SuggestionsAreMergedAndCapped(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	// El mismo texto llega por title y por q: los candidatos se repiten
+	ctx := context.Background()
+	title := "el alef"
+	filter := domain.BookFilter{Title: &title, Query: "el alef", Limit: domain.DefaultPageLimit}
+	mockRepo.EXPECT().FindByFilter(ctx, filter).Return(&domain.BookPage{Books: []*domain.Book{}}, nil)
+	mockRepo.EXPECT().SuggestionCandidates(ctx, "title", gomock.Any(), gomock.Any()).Return([]string{
+		"El Aleph", "El alefato", "El alef de oro", "El álef perdido", "El alef y el zahir", "Alef",
+	}, nil).AnyTimes()
+	mockRepo.EXPECT().SuggestionCandidates(ctx, "author", gomock.Any(), gomock.Any()).Return([]string{}, nil).AnyTimes()
+
+	// Act
+	page, err := service.SearchBooks(ctx, filter)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, page.Suggestions, domain.MaxSuggestions)
+	seen := map[string]bool{}
+	for _, s := range page.Suggestions {
+		assert.False(t, seen[s.Field+"/"+s.Value], "repeated suggestion %q", s.Value)
+		seen[s.Field+"/"+s.Value] = true
+	}
+}
+
+func TestBookService_SearchBooks_NoSuggestionsWithResults(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	title := "rayuela"
+	filter := domain.BookFilter{Title: &title, Limit: domain.DefaultPageLimit}
+	page := &domain.BookPage{Books: []*domain.Book{{ID: 4, Title: "Rayuela"}}, Total: 1}
+
+	// Mock: hay resultados, así que no se consultan candidatos
+	mockRepo.EXPECT().FindByFilter(ctx, filter).Return(page, nil)
+
+	// Act
+	result, err := service.SearchBooks(ctx, filter)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, result.Suggestions)
+}
+
+func TestRankSuggestions(t *testing.T) {
+	candidates := []string{"Rayuela", "Ficciones", "Cien años de soledad", "El Aleph"}
+
+	got := domain.RankSuggestions("title", "cien anos de soledd", candidates)
+
+	assert.Len(t, got, 1)
+	assert.Equal(t, "Cien años de soledad", got[0].Value)
+	assert.Greater(t, got[0].Score, domain.SuggestionThreshold)
+}
+
+func TestMergeSuggestions_KeepsBestScorePerValue(t *testing.T) {
+	got := domain.MergeSuggestions(
+		[]domain.Suggestion{{Field: "title", Value: "El Aleph", Score: 0.4}, {Field: "author", Value: "Borges", Score: 0.5}},
+		[]domain.Suggestion{{Field: "title", Value: "El Aleph", Score: 0.8}, {Field: "title", Value: "Borges", Score: 0.3}},
+	)
+
+	assert.Equal(t, []domain.Suggestion{
+		{Field: "title", Value: "El Aleph", Score: 0.8},
+		{Field: "author", Value: "Borges", Score: 0.5},
+		{Field: "title", Value: "Borges", Score: 0.3},
+	}, got)
+}
+
+func TestCandidatePrefixes(t *testing.T) {
+	assert.Equal(t, []string{"ga", "ma"}, domain.CandidatePrefixes("García  MARQUES garcia"))
+	assert.Equal(t, []string{"y"}, domain.CandidatePrefixes("y"))
+	assert.Empty(t, domain.CandidatePrefixes(" -- "))
+}
@@ -0,0 +1,72 @@
+package application_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/application/mocks"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// inlineTx ejecuta la unidad de trabajo sin transacción, con el mismo ctx
+type inlineTx struct{}
+
+func (inlineTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type txCtxKey struct{}
+
+func TestBookService_CreateBook_RunsInsideTransaction(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	mockTx := mocks.NewMockTxManager(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, mockTx, discardEvents{})
+
+	ctx := context.Background()
+	txCtx := context.WithValue(ctx, txCtxKey{}, "tx")
+	isbn := domain.NormalizeISBN("978-84-18037-01-6")
+
+	// El repositorio recibe el ctx de la transacción, no el original
+	mockTx.EXPECT().WithinTx(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, fn func(context.Context) error) error { return fn(txCtx) })
+	mockRepo.EXPECT().GetByISBN(txCtx, isbn).Return(nil, domain.ErrNotFound)
+	mockRepo.EXPECT().Create(txCtx, gomock.Any()).Return(nil)
+
+	// Act
+	result, err := service.CreateBook(ctx, "Título", "Autor", 2022, "Ficción", isbn)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+}
+
+func TestBookService_UpdateBook_TransactionError(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	mockTx := mocks.NewMockTxManager(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, mockTx, discardEvents{})
+
+	ctx := context.Background()
+	title := "Nuevo título"
+
+	// No se pudo abrir la transacción: no se toca el repositorio
+	mockTx.EXPECT().WithinTx(ctx, gomock.Any()).Return(errors.New("begin tx: database is locked"))
+
+	// Act
+	result, err := service.UpdateBook(ctx, 1, 1, domain.UpdateBookInput{Title: &title})
+
+	// Assert
+	assert.EqualError(t, err, "begin tx: database is locked")
+	assert.Nil(t, result)
+}
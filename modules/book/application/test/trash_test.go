@@ -0,0 +1,107 @@
+package application_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/application/mocks"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBookService_RestoreBook_RecordsRevision(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	mockHistory := mocks.NewMockRevisionRepository(ctrl)
+	service := application.NewBookService(mockRepo, mockHistory, inlineTx{}, discardEvents{})
+
+	ctx := application.WithActor(context.Background(), "ana")
+	restored := ficciones()
+	restored.Version = 4
+
+	mockRepo.EXPECT().Restore(ctx, uint(7)).Return(restored, nil)
+	var recorded *domain.Revision
+	mockHistory.EXPECT().Append(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, rev *domain.Revision) error {
+		recorded = rev
+		return nil
+	})
+
+	// Act
+	book, err := service.RestoreBook(ctx, 7)
+
+	// Assert: como al crear, todos los campos vuelven desde vacío
+	assert.NoError(t, err)
+	assert.Equal(t, uint(4), book.Version)
+	if assert.NotNil(t, recorded) {
+		assert.Equal(t, domain.RevisionRestore, recorded.Action)
+		assert.Equal(t, "ana", recorded.Actor)
+		assert.Len(t, recorded.Changes, 5)
+	}
+}
+
+func TestBookService_RestoreBook_IsbnTaken(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	mockHistory := mocks.NewMockRevisionRepository(ctrl)
+	service := application.NewBookService(mockRepo, mockHistory, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	mockRepo.EXPECT().Restore(ctx, uint(7)).Return(nil, fmt.Errorf("%w: book 7 cannot be restored", domain.ErrDuplicateISBN))
+
+	// Act
+	book, err := service.RestoreBook(ctx, 7)
+
+	// Assert: sin restaurar no queda revisión (mockHistory no espera Append)
+	assert.ErrorIs(t, err, domain.ErrDuplicateISBN)
+	assert.Nil(t, book)
+}
+
+func TestBookService_PurgeTrash_UsesRetention(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	retention := 48 * time.Hour
+	var cutoff time.Time
+	mockRepo.EXPECT().Purge(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, before time.Time) (int, error) {
+		cutoff = before
+		return 2, nil
+	})
+
+	// Act
+	n, err := service.PurgeTrash(ctx, retention)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.WithinDuration(t, time.Now().Add(-retention), cutoff, time.Minute)
+}
+
+func TestBookService_PurgeTrash_InvalidRetention(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	// Act: una retención de 0 vaciaría la papelera entera
+	_, err := service.PurgeTrash(context.Background(), 0)
+
+	// Assert
+	assert.Error(t, err)
+}
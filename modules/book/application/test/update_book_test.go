@@ -5,7 +5,6 @@ import (
 	"api-go-gestion-libros-hexagonal/modules/book/application/mocks"
 	"api-go-gestion-libros-hexagonal/modules/book/domain"
 	"context"
-	"fmt"
 	"testing"
 	"time"
 
@@ -19,7 +18,7 @@ func TestBookService_UpdateBook_Success(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockBookRepository(ctrl)
-	service := application.NewBookService(mockRepo)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
 
 	ctx := context.Background()
 	id := uint(1)
@@ -37,6 +36,7 @@ func TestBookService_UpdateBook_Success(t *testing.T) {
 		ISBN:      "9788418037016",
 		CreatedAt: time.Now().UTC(),
 		UpdatedAt: time.Now().UTC(),
+		Version:   3,
 	}
 
 	// Libro actualizado que retornará el repositorio
@@ -49,6 +49,7 @@ func TestBookService_UpdateBook_Success(t *testing.T) {
 		ISBN:      "9788418037016",
 		CreatedAt: currentBook.CreatedAt,
 		UpdatedAt: time.Now().UTC(),
+		Version:   4,
 	}
 
 	input := domain.UpdateBookInput{
@@ -60,15 +61,16 @@ func TestBookService_UpdateBook_Success(t *testing.T) {
 	// Mock: obtener libro actual
 	mockRepo.EXPECT().GetByID(ctx, id).Return(currentBook, nil)
 
-	// Mock: actualizar libro
-	mockRepo.EXPECT().Update(ctx, gomock.Any()).Return(updatedBook, nil)
+	// Mock: actualizar libro, con la versión leída para que el repositorio la compruebe
+	mockRepo.EXPECT().Update(ctx, gomock.Cond(func(b *domain.Book) bool { return b.Version == 3 })).Return(updatedBook, nil)
 
 	// Act
-	result, err := service.UpdateBook(ctx, id, input)
+	result, err := service.UpdateBook(ctx, id, 3, input)
 
 	// Assert
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
+	assert.Equal(t, uint(4), result.Version)
 	assert.Equal(t, newTitle, result.Title)
 	assert.Equal(t, newAuthor, result.Author)
 	assert.Equal(t, newYear, result.Year)
@@ -80,7 +82,7 @@ func TestBookService_UpdateBook_ZeroID(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockBookRepository(ctrl)
-	service := application.NewBookService(mockRepo)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
 
 	ctx := context.Background()
 	input := domain.UpdateBookInput{
@@ -88,7 +90,7 @@ func TestBookService_UpdateBook_ZeroID(t *testing.T) {
 	}
 
 	// Act
-	result, err := service.UpdateBook(ctx, 0, input)
+	result, err := service.UpdateBook(ctx, 0, domain.AnyVersion, input)
 
 	// Assert
 	assert.Error(t, err)
@@ -102,7 +104,7 @@ func TestBookService_UpdateBook_BookNotFound(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockBookRepository(ctrl)
-	service := application.NewBookService(mockRepo)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
 
 	ctx := context.Background()
 	id := uint(999)
@@ -111,15 +113,14 @@ func TestBookService_UpdateBook_BookNotFound(t *testing.T) {
 	}
 
 	// Mock: libro no encontrado
-	mockRepo.EXPECT().GetByID(ctx, id).Return(nil, fmt.Errorf("not found"))
+	mockRepo.EXPECT().GetByID(ctx, id).Return(nil, domain.ErrNotFound)
 
 	// Act
-	result, err := service.UpdateBook(ctx, id, input)
+	result, err := service.UpdateBook(ctx, id, domain.AnyVersion, input)
 
 	// Assert
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
 	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "not found")
 }
 
 func TestBookService_UpdateBook_ISBNAlreadyExists(t *testing.T) {
@@ -128,11 +129,11 @@ func TestBookService_UpdateBook_ISBNAlreadyExists(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockBookRepository(ctrl)
-	service := application.NewBookService(mockRepo)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
 
 	ctx := context.Background()
 	id := uint(1)
-	existingISBN := "9788418037017"
+	existingISBN := "9788418037023"
 
 	currentBook := &domain.Book{
 		ID:   id,
@@ -155,12 +156,12 @@ func TestBookService_UpdateBook_ISBNAlreadyExists(t *testing.T) {
 	mockRepo.EXPECT().GetByISBN(ctx, existingISBN).Return(otherBook, nil)
 
 	// Act
-	result, err := service.UpdateBook(ctx, id, input)
+	result, err := service.UpdateBook(ctx, id, domain.AnyVersion, input)
 
 	// Assert
 	assert.Error(t, err)
 	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "isbn already registered by another book")
+	assert.ErrorIs(t, err, domain.ErrDuplicateISBN)
 }
 
 func TestBookService_UpdateBook_SameISBN(t *testing.T) {
@@ -169,7 +170,7 @@ func TestBookService_UpdateBook_SameISBN(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockBookRepository(ctrl)
-	service := application.NewBookService(mockRepo)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
 
 	ctx := context.Background()
 	id := uint(1)
@@ -211,7 +212,7 @@ func TestBookService_UpdateBook_SameISBN(t *testing.T) {
 	mockRepo.EXPECT().Update(ctx, gomock.Any()).Return(updatedBook, nil)
 
 	// Act
-	result, err := service.UpdateBook(ctx, id, input)
+	result, err := service.UpdateBook(ctx, id, domain.AnyVersion, input)
 
 	// Assert
 	assert.NoError(t, err)
@@ -219,6 +220,58 @@ func TestBookService_UpdateBook_SameISBN(t *testing.T) {
 	assert.Equal(t, sameISBN, result.ISBN)
 }
 
+func TestBookService_UpdateBook_ReturnsAllInvalidFields(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	id := uint(1)
+	year := uint(3000)
+	input := domain.UpdateBookInput{
+		Title: stringPtr(""),
+		Year:  &year,
+		ISBN:  stringPtr("123"),
+	}
+
+	mockRepo.EXPECT().GetByID(ctx, id).Return(&domain.Book{ID: id}, nil)
+
+	// Act
+	result, err := service.UpdateBook(ctx, id, domain.AnyVersion, input)
+
+	// Assert
+	assert.Nil(t, result)
+	var errs domain.ValidationErrors
+	if assert.ErrorAs(t, err, &errs) {
+		assert.Equal(t, []string{"title", "year", "isbn"}, fieldsOf(errs))
+	}
+}
+
+func TestBookService_UpdateBook_VersionMismatch(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockBookRepository(ctrl)
+	service := application.NewBookService(mockRepo, discardHistory{}, inlineTx{}, discardEvents{})
+
+	ctx := context.Background()
+	id := uint(1)
+
+	// Otro usuario ya actualizó el libro a la versión 5
+	mockRepo.EXPECT().GetByID(ctx, id).Return(&domain.Book{ID: id, Version: 5}, nil)
+
+	// Act: el cliente leyó la versión 4
+	result, err := service.UpdateBook(ctx, id, 4, domain.UpdateBookInput{Title: stringPtr("Nuevo Título")})
+
+	// Assert: no se llega a escribir
+	assert.ErrorIs(t, err, domain.ErrVersionMismatch)
+	assert.Nil(t, result)
+}
+
 // Helper function para crear punteros a string
 func stringPtr(s string) *string {
 	return &s
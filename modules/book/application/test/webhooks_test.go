@@ -0,0 +1,119 @@
+package application_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/application/mocks"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// failingClient simula un receptor al que no se llega
+type failingClient struct{ err error }
+
+func (c failingClient) Post(ctx context.Context, url string, body []byte, headers map[string]string) (int, error) {
+	return 0, c.err
+}
+
+func TestWebhookService_CreateWebhook_GeneratesSecret(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockWebhookRepository(ctrl)
+	service := application.NewWebhookService(mockRepo, failingClient{}, testPolicy, 3)
+	ctx := context.Background()
+	url := "https://web.example.com/hooks"
+	mockRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+	// Act
+	webhook, err := service.CreateWebhook(ctx, domain.WebhookInput{URL: &url})
+
+	// Assert: sin eventos recibe todos
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, len(webhook.Secret), domain.MinWebhookSecret)
+	assert.Equal(t, []string{domain.AllEvents}, webhook.Events)
+	assert.True(t, webhook.Active)
+}
+
+func TestWebhookService_CreateWebhook_ReportsEveryInvalidField(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service := application.NewWebhookService(mocks.NewMockWebhookRepository(ctrl), failingClient{}, testPolicy, 3)
+	url, secret := "ftp://web.example.com", "short"
+
+	// Act
+	_, err := service.CreateWebhook(context.Background(), domain.WebhookInput{URL: &url, Events: []string{"book.archived"}, Secret: &secret})
+
+	// Assert
+	var errs domain.ValidationErrors
+	require.ErrorAs(t, err, &errs)
+	assert.True(t, errs.Has("url"))
+	assert.True(t, errs.Has("events"))
+	assert.True(t, errs.Has("secret"))
+}
+
+func TestWebhookService_Enqueue_OnlyActiveSubscribedWebhooks(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockWebhookRepository(ctrl)
+	service := application.NewWebhookService(mockRepo, failingClient{}, testPolicy, 3)
+	ctx := context.Background()
+	mockRepo.EXPECT().List(ctx).Return([]*domain.Webhook{
+		{ID: 1, Active: true, Events: []string{domain.EventBookCreated}},
+		{ID: 2, Active: true, Events: []string{domain.EventBookDeleted}},
+		{ID: 3, Active: false, Events: []string{domain.AllEvents}},
+		{ID: 4, Active: true, Events: []string{domain.AllEvents}},
+	}, nil)
+	var added []uint
+	mockRepo.EXPECT().AddDelivery(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, d *domain.WebhookDelivery) error {
+		assert.Equal(t, "book.deleted:7:2", d.EventID)
+		assert.Equal(t, domain.DeliveryPending, d.Status)
+		added = append(added, d.WebhookID)
+		return nil
+	}).Times(2)
+
+	// Act
+	err := service.Enqueue(ctx, domain.BookDeleted{EventMeta: domain.EventMeta{ID: "book.deleted:7:2", BookID: 7}, Book: *ficciones()})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []uint{2, 4}, added)
+}
+
+func TestWebhookService_Dispatch_UnreachableReceiverCountsFailure(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockWebhookRepository(ctrl)
+	service := application.NewWebhookService(mockRepo, failingClient{err: errors.New("connection refused")}, testPolicy, 3)
+	ctx := context.Background()
+	delivery := &domain.WebhookDelivery{ID: 5, WebhookID: 1, EventID: "book.created:7:1", Status: domain.DeliveryPending}
+
+	mockRepo.EXPECT().DueDeliveries(ctx, gomock.Any(), application.DefaultWebhookBatch).Return([]*domain.WebhookDelivery{delivery}, nil)
+	mockRepo.EXPECT().GetByID(ctx, uint(1)).Return(&domain.Webhook{ID: 1, URL: "http://erp.local", Active: true, Secret: "0123456789abcdef"}, nil)
+	mockRepo.EXPECT().UpdateDelivery(ctx, delivery).Return(nil)
+	mockRepo.EXPECT().RecordFailure(ctx, uint(1), 3, gomock.Any(), gomock.Any()).Return(nil)
+
+	// Act
+	n, err := service.Dispatch(ctx)
+
+	// Assert: primer fallo, se reintenta tras BaseDelay
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+	assert.Equal(t, domain.DeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, "connection refused", delivery.LastError)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), delivery.NextAttemptAt, 5*time.Second)
+}
@@ -0,0 +1,48 @@
+package application
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"fmt"
+	"time"
+)
+
+// ListTrash devuelve los libros borrados que todavía se pueden restaurar,
+// del borrado más reciente al más viejo
+func (s *BookService) ListTrash(ctx context.Context) ([]*domain.Book, error) {
+	return s.bookRepo.ListDeleted(ctx)
+}
+
+// RestoreBook saca un libro de la papelera y deja una RevisionRestore. Si mientras
+// estaba borrado otro libro tomó su ISBN devuelve domain.ErrDuplicateISBN: hay que
+// cambiar o borrar ese otro libro antes de restaurar.
+func (s *BookService) RestoreBook(ctx context.Context, id uint) (*domain.Book, error) {
+	if id == 0 {
+		return nil, &domain.ValidationError{Field: "id", Code: domain.CodeRequired, Message: "id is required"}
+	}
+	var restored *domain.Book
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if restored, err = s.bookRepo.Restore(ctx, id); err != nil {
+			return err
+		}
+		if err := s.record(ctx, id, domain.RevisionRestore, domain.DiffBooks(nil, restored), 0); err != nil {
+			return err
+		}
+		return s.events.Publish(ctx, domain.BookRestored{EventMeta: eventMeta(ctx, domain.EventBookRestored, restored), Book: *restored})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.autocomplete.clear()
+	return restored, nil
+}
+
+// PurgeTrash elimina definitivamente los libros que llevan en la papelera más de
+// retention y devuelve cuántos eliminó. El historial de esos libros se conserva.
+func (s *BookService) PurgeTrash(ctx context.Context, retention time.Duration) (int, error) {
+	if retention <= 0 {
+		return 0, fmt.Errorf("trash retention must be positive, got %s", retention)
+	}
+	return s.bookRepo.Purge(ctx, time.Now().UTC().Add(-retention))
+}
@@ -0,0 +1,288 @@
+package application
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Valores por defecto de los webhooks
+const (
+	DefaultWebhookBatch         = 50
+	DefaultWebhookDisableAfter  = 15 // fallos seguidos antes de desactivar un webhook
+	DefaultDeliveryLimit        = 50
+	MaxDeliveryLimit            = 500
+	generatedWebhookSecretBytes = 32
+)
+
+// WebhookService administra los webhooks y les entrega los eventos de libros. Enqueue
+// se suscribe al bus y deja una entrega pendiente por webhook; Dispatch las envía,
+// firmadas, y reintenta las que fallan según policy.
+type WebhookService struct {
+	repo         domain.WebhookRepository
+	client       domain.WebhookClient
+	policy       domain.RetryPolicy
+	disableAfter int
+}
+
+// NewWebhookService crea el servicio; un webhook se desactiva tras disableAfter
+// intentos fallidos seguidos
+func NewWebhookService(repo domain.WebhookRepository, client domain.WebhookClient, policy domain.RetryPolicy, disableAfter int) *WebhookService {
+	return &WebhookService{repo: repo, client: client, policy: policy, disableAfter: disableAfter}
+}
+
+// CreateWebhook da de alta un webhook activo. Sin secreto se genera uno, que solo se
+// puede ver en la respuesta del alta.
+func (s *WebhookService) CreateWebhook(ctx context.Context, in domain.WebhookInput) (*domain.Webhook, error) {
+	if in.Secret == nil || *in.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return nil, err
+		}
+		in.Secret = &secret
+	}
+	webhook := domain.NewWebhook(in)
+	if err := webhook.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (s *WebhookService) ListWebhooks(ctx context.Context) ([]*domain.Webhook, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *WebhookService) GetWebhook(ctx context.Context, id uint) (*domain.Webhook, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// UpdateWebhook cambia los campos que vienen en in; active=true reactiva un webhook
+// desactivado y sus entregas pendientes vuelven a salir
+func (s *WebhookService) UpdateWebhook(ctx context.Context, id uint, in domain.WebhookInput) (*domain.Webhook, error) {
+	webhook, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	webhook.Update(in)
+	if err := webhook.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id uint) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// ListDeliveries devuelve el registro de entregas de un webhook, de la más nueva a la más vieja
+func (s *WebhookService) ListDeliveries(ctx context.Context, id uint, limit int) ([]*domain.WebhookDelivery, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultDeliveryLimit
+	}
+	if limit > MaxDeliveryLimit {
+		limit = MaxDeliveryLimit
+	}
+	return s.repo.ListDeliveries(ctx, id, limit)
+}
+
+// RedeliverWebhook vuelve a enviar una entrega en el momento, aunque ya se haya
+// entregado o se hayan acabado sus reintentos. El reenvío es una entrega nueva que
+// apunta a la original (RedeliveryOf), que queda como estaba; si falla, se reintenta
+// como cualquier otra. El receptor recibe el mismo X-Event-ID que la primera vez.
+func (s *WebhookService) RedeliverWebhook(ctx context.Context, id, deliveryID uint) (*domain.WebhookDelivery, error) {
+	webhook, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	original, err := s.repo.GetDelivery(ctx, id, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	// El reenvío de un reenvío apunta también a la primera entrega
+	redeliveryOf := original.ID
+	if original.RedeliveryOf != 0 {
+		redeliveryOf = original.RedeliveryOf
+	}
+	now := time.Now().UTC()
+	delivery := &domain.WebhookDelivery{
+		WebhookID:     id,
+		EventID:       original.EventID,
+		EventName:     original.EventName,
+		Payload:       original.Payload,
+		Status:        domain.DeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		RedeliveryOf:  redeliveryOf,
+	}
+	if err := s.repo.AddDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	if err := s.attempt(ctx, webhook, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// Enqueue es el suscriptor del bus: deja una entrega pendiente del evento para cada
+// webhook activo que lo recibe. Si el bus entrega el evento otra vez no se duplica.
+func (s *WebhookService) Enqueue(ctx context.Context, event domain.Event) error {
+	webhooks, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	var payload []byte
+	now := time.Now().UTC()
+	for _, webhook := range webhooks {
+		if !webhook.Active || !webhook.Matches(event.EventName()) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = domain.NewWebhookPayload(event); err != nil {
+				return err
+			}
+		}
+		delivery := &domain.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.Meta().ID,
+			EventName:     event.EventName(),
+			Payload:       payload,
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+		if err := s.repo.AddDelivery(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Dispatch envía un lote de las entregas que ya toca intentar y devuelve cuántas
+// llegaron. Cada webhook se atiende en paralelo, con sus entregas en orden. Tras el
+// primer fallo de un webhook el resto de sus entregas espera con él (Webhook.RetryAt)
+// y DueDeliveries no las trae mientras tanto, así un receptor caído no ocupa los lotes
+// siguientes ni frena a los demás. Los fallos de entrega no son error: quedan en el registro.
+func (s *WebhookService) Dispatch(ctx context.Context) (int, error) {
+	due, err := s.repo.DueDeliveries(ctx, time.Now().UTC(), DefaultWebhookBatch)
+	if err != nil {
+		return 0, err
+	}
+	byWebhook := map[uint][]*domain.WebhookDelivery{}
+	for _, delivery := range due {
+		byWebhook[delivery.WebhookID] = append(byWebhook[delivery.WebhookID], delivery)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		delivered int
+		errs      []error
+	)
+	for _, deliveries := range byWebhook {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := s.dispatchWebhook(ctx, deliveries)
+			mu.Lock()
+			defer mu.Unlock()
+			delivered += n
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return delivered, errors.Join(errs...)
+}
+
+// dispatchWebhook envía en orden las entregas de un mismo webhook hasta la primera
+// que no llega, y devuelve cuántas llegaron
+func (s *WebhookService) dispatchWebhook(ctx context.Context, deliveries []*domain.WebhookDelivery) (int, error) {
+	webhook, err := s.repo.GetByID(ctx, deliveries[0].WebhookID)
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for _, delivery := range deliveries {
+		if err := s.attempt(ctx, webhook, delivery); err != nil {
+			return delivered, err
+		}
+		if delivery.Status != domain.DeliveryDelivered {
+			break
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// attempt envía delivery a webhook y guarda el resultado: entregada, reprogramada
+// según policy o fallida. Cada fallo cuenta para desactivar el webhook y lo hace
+// esperar, según policy y sus fallos seguidos, antes de intentar otra entrega.
+func (s *WebhookService) attempt(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) error {
+	headers := map[string]string{
+		"Content-Type":                "application/json",
+		domain.WebhookSignatureHeader: domain.SignWebhook(webhook.Secret, delivery.Payload),
+		domain.WebhookEventHeader:     delivery.EventName,
+		domain.WebhookEventIDHeader:   delivery.EventID,
+		domain.WebhookDeliveryHeader:  strconv.FormatUint(uint64(delivery.ID), 10),
+	}
+	status, err := s.client.Post(ctx, webhook.URL, delivery.Payload, headers)
+	now := time.Now().UTC()
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+
+	if err == nil && status >= 200 && status < 300 {
+		delivery.Status, delivery.LastError, delivery.DeliveredAt = domain.DeliveryDelivered, "", &now
+		if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+			return err
+		}
+		if webhook.Failures > 0 {
+			if err := s.repo.ResetFailures(ctx, webhook.ID); err != nil {
+				return err
+			}
+			webhook.Failures = 0
+		}
+		return nil
+	}
+
+	if err != nil {
+		delivery.LastError = err.Error()
+	} else {
+		delivery.LastError = fmt.Sprintf("receiver responded %d", status)
+	}
+	if s.policy.GiveUp(delivery.Attempts) {
+		delivery.Status = domain.DeliveryFailed
+	} else {
+		delivery.NextAttemptAt = now.Add(s.policy.Delay(delivery.Attempts))
+	}
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		return err
+	}
+	webhook.Failures++
+	retryAt := now.Add(s.policy.Delay(webhook.Failures))
+	return s.repo.RecordFailure(ctx, webhook.ID, s.disableAfter, now, retryAt)
+}
+
+// generateSecret crea un secreto aleatorio para firmar las entregas
+func generateSecret() (string, error) {
+	b := make([]byte, generatedWebhookSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,22 @@
+package domain
+
+const (
+	// DefaultAutocompleteLimit es la cantidad de sugerencias si el cliente no indica limit
+	DefaultAutocompleteLimit = 10
+	// MaxAutocompleteLimit limita las sugerencias de autocompletado
+	MaxAutocompleteLimit = 25
+)
+
+// ValueCount es un valor distinto de un campo y cuántos libros lo tienen.
+type ValueCount struct {
+	Value string
+	Count int
+}
+
+// ValidateAutocompleteField solo permite autocompletar títulos y autores.
+func ValidateAutocompleteField(field string) error {
+	if field != "title" && field != "author" {
+		return invalid("field", CodeInvalidValue, "field must be title or author")
+	}
+	return nil
+}
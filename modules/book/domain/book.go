@@ -1,7 +1,6 @@
 package domain
 
 import (
-	"errors"
 	"regexp"
 	"strings"
 	"time"
@@ -16,6 +15,13 @@ type Book struct {
 	ISBN      string    `json:"isbn" db:"isbn"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Version empieza en 1 y sube en cada actualización (concurrencia optimista):
+	// Update del repositorio solo escribe si el libro guardado sigue en esta versión
+	Version uint `json:"version" db:"version"`
+
+	// DeletedAt es nil salvo para los libros en la papelera (borrado lógico)
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
 
 type BookFilter struct {
@@ -23,6 +29,32 @@ type BookFilter struct {
 	Author *string `json:"author"`
 	Year   *uint   `json:"year"`
 	Genre  *string `json:"genre"`
+
+	// Rango de años (inclusive) y lista de géneros exactos (cualquiera de ellos)
+	YearFrom *uint    `json:"year_from"`
+	YearTo   *uint    `json:"year_to"`
+	Genres   []string `json:"genres"`
+
+	// Prefijo de ISBN (ej: prefijo de editorial), se compara ya normalizado
+	ISBNPrefix *string `json:"isbn_prefix"`
+
+	// Ventanas de tiempo para sincronización incremental
+	CreatedAfter  *time.Time `json:"created_after"`
+	CreatedBefore *time.Time `json:"created_before"`
+	UpdatedSince  *time.Time `json:"updated_since"`
+	UpdatedBefore *time.Time `json:"updated_before"`
+
+	// Consulta avanzada (ver ParseQuery): texto libre sobre título, autor y
+	// género con coincidencia por prefijo, combinable con campo:valor, AND, OR y NOT
+	Query string `json:"q"`
+
+	// Paginación: offset clásico o cursor opaco (keyset), no ambos
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+	Cursor string `json:"cursor"`
+
+	// Orden solicitado; siempre se desempata por id
+	Sort []SortField `json:"sort"`
 }
 
 // UpdateBookInput especifica campos opcionales para actualización.
@@ -34,6 +66,9 @@ type UpdateBookInput struct {
 	ISBN   *string
 }
 
+// AnyVersion, al actualizar, acepta el libro en cualquier versión (If-Match: *)
+const AnyVersion uint = 0
+
 func NewBook(title, author string, year uint, genre, isbn string) *Book {
 	// agregar el tiempo en UTC
 	now := time.Now().UTC()
@@ -45,6 +80,7 @@ func NewBook(title, author string, year uint, genre, isbn string) *Book {
 		ISBN:      NormalizeISBN(isbn),
 		CreatedAt: now,
 		UpdatedAt: now,
+		Version:   1,
 	}
 }
 
@@ -69,47 +105,78 @@ func (b *Book) Update(input UpdateBookInput) {
 	b.UpdatedAt = time.Now().UTC()
 }
 
-// HasAny indica si el filtro tiene algún criterio.
+// HasAny indica si el filtro tiene algún criterio (la paginación no cuenta).
 func (f BookFilter) HasAny() bool {
-	return f.Title != nil || f.Author != nil || f.Year != nil || f.Genre != nil
+	return f.Query != "" || f.Title != nil || f.Author != nil || f.Year != nil || f.Genre != nil ||
+		f.YearFrom != nil || f.YearTo != nil || len(f.Genres) > 0 || f.ISBNPrefix != nil ||
+		f.CreatedAfter != nil || f.CreatedBefore != nil || f.UpdatedSince != nil || f.UpdatedBefore != nil
+}
+
+// Validate revisa que los criterios del filtro sean coherentes entre sí.
+func (f BookFilter) Validate() error {
+	if _, err := ParseQuery(f.Query); err != nil {
+		return err
+	}
+	if f.YearFrom != nil && f.YearTo != nil && *f.YearFrom > *f.YearTo {
+		return invalid("year_from", CodeInconsistent, "year_from cannot be greater than year_to")
+	}
+	if f.ISBNPrefix != nil && !isbnPrefixRe.MatchString(NormalizeISBN(*f.ISBNPrefix)) {
+		return invalid("isbn_prefix", CodeInvalidFormat, "isbn_prefix must contain only digits or X")
+	}
+	if f.CreatedAfter != nil && f.CreatedBefore != nil && !f.CreatedAfter.Before(*f.CreatedBefore) {
+		return invalid("created_after", CodeInconsistent, "created_after must be before created_before")
+	}
+	if f.UpdatedSince != nil && f.UpdatedBefore != nil && !f.UpdatedSince.Before(*f.UpdatedBefore) {
+		return invalid("updated_since", CodeInconsistent, "updated_since must be before updated_before")
+	}
+	return nil
 }
 
-// ValidateBasic valida reglas de negocio esenciales del libro
+var isbnPrefixRe = regexp.MustCompile(`^[0-9X]{1,13}$`)
+
+// ValidateBasic valida reglas de negocio esenciales del libro.
+// Devuelve ValidationErrors con todos los campos inválidos, no solo el primero.
 func (b *Book) ValidateBasic() error {
+	var errs ValidationErrors
 	if strings.TrimSpace(b.Title) == "" {
-		return errors.New("title is required")
+		errs = append(errs, invalid("title", CodeRequired, "title is required"))
 	}
 	if strings.TrimSpace(b.Author) == "" {
-		return errors.New("author is required")
+		errs = append(errs, invalid("author", CodeRequired, "author is required"))
 	}
-	if err := ValidateYear(b.Year); err != nil {
+	if b.Year == 0 {
+		errs = append(errs, invalid("year", CodeRequired, "year is required"))
+	} else if err := errs.Add(ValidateYear(b.Year)); err != nil {
 		return err
 	}
-
-	if err := ValidateISBN(b.ISBN); err != nil {
+	if strings.TrimSpace(b.ISBN) == "" {
+		errs = append(errs, invalid("isbn", CodeRequired, "isbn is required"))
+	} else if err := errs.Add(ValidateISBN(b.ISBN)); err != nil {
 		return err
 	}
-	return nil
+	return errs.Err()
 }
 
+// ValidateUpdateInput valida solo los campos que vienen en in, todos a la vez
 func ValidateUpdateInput(in UpdateBookInput) error {
+	var errs ValidationErrors
 	if in.Title != nil && strings.TrimSpace(*in.Title) == "" {
-		return errors.New("title cannot be empty")
+		errs = append(errs, invalid("title", CodeEmpty, "title cannot be empty"))
 	}
 	if in.Author != nil && strings.TrimSpace(*in.Author) == "" {
-		return errors.New("author cannot be empty")
+		errs = append(errs, invalid("author", CodeEmpty, "author cannot be empty"))
 	}
 	if in.Year != nil {
-		if err := ValidateYear(*in.Year); err != nil {
+		if err := errs.Add(ValidateYear(*in.Year)); err != nil {
 			return err
 		}
 	}
 	if in.ISBN != nil {
-		if err := ValidateISBN(*in.ISBN); err != nil {
+		if err := errs.Add(ValidateISBN(*in.ISBN)); err != nil {
 			return err
 		}
 	}
-	return nil
+	return errs.Err()
 }
 
 // NormalizeISBN quita guiones/espacios y pone en mayúsculas.
@@ -140,7 +207,7 @@ func ValidateISBN(isbn string) error {
 		}
 		sum += check
 		if sum%11 != 0 {
-			return errors.New("invalid ISBN-10 checksum")
+			return invalid("isbn", CodeInvalidChecksum, "invalid ISBN-10 checksum")
 		}
 		return nil
 	case re13.MatchString(n):
@@ -156,11 +223,11 @@ func ValidateISBN(isbn string) error {
 		expected := (10 - (sum % 10)) % 10
 		actual := int(n[12] - '0')
 		if actual != expected {
-			return errors.New("invalid ISBN-13 checksum")
+			return invalid("isbn", CodeInvalidChecksum, "invalid ISBN-13 checksum")
 		}
 		return nil
 	default:
-		return errors.New("isbn must be ISBN-10 or ISBN-13 format")
+		return invalid("isbn", CodeInvalidFormat, "isbn must be ISBN-10 or ISBN-13 format")
 	}
 }
 
@@ -169,7 +236,7 @@ func ValidateYear(year uint) error {
 	const minYear = 1450
 	current := time.Now().UTC().Year()
 	if int(year) < minYear || int(year) > current {
-		return errors.New("year must be between 1450 and current year")
+		return invalid("year", CodeOutOfRange, "year must be between 1450 and current year")
 	}
 	return nil
 }
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+)
+
+// Errores del dominio. Los repositorios y el servicio los envuelven con el detalle
+// (ej. "book 7 not found") y la presentación los traduce a códigos HTTP con errors.Is/As.
+var (
+	// ErrNotFound indica que el libro pedido no existe
+	ErrNotFound = errors.New("not found")
+	// ErrDuplicateISBN indica que otro libro ya tiene ese ISBN
+	ErrDuplicateISBN = errors.New("duplicate isbn")
+	// ErrConflict indica que la operación choca con el estado actual del libro
+	ErrConflict = errors.New("conflict")
+	// ErrVersionMismatch indica que el libro cambió desde que el cliente lo leyó
+	ErrVersionMismatch = errors.New("version mismatch")
+)
+
+// Códigos de ValidationError: estables, para que los clientes no dependan del mensaje
+const (
+	CodeRequired        = "required"         // falta el valor
+	CodeEmpty           = "empty"            // el valor está en blanco
+	CodeOutOfRange      = "out_of_range"     // número fuera de los límites
+	CodeInvalidFormat   = "invalid_format"   // el valor no tiene la forma esperada
+	CodeInvalidChecksum = "invalid_checksum" // el dígito de control del ISBN no cuadra
+	CodeInvalidValue    = "invalid_value"    // el valor no es uno de los admitidos
+	CodeDuplicate       = "duplicate"        // el valor está repetido
+	CodeInconsistent    = "inconsistent"     // choca con otro parámetro (ej. year_from > year_to)
+	CodeSyntax          = "syntax"           // consulta q mal formada
+)
+
+// ValidationError indica que un dato de entrada no cumple una regla del dominio.
+type ValidationError struct {
+	Field   string // campo o parámetro inválido (ej. "isbn", "sort")
+	Code    string // una de las constantes Code*
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// invalid crea un ValidationError para field
+func invalid(field, code, message string) *ValidationError {
+	return &ValidationError{Field: field, Code: code, Message: message}
+}
+
+// ValidationErrors junta todas las reglas que no se cumplen, para que el cliente
+// corrija todos los campos de una vez. errors.As encuentra cada *ValidationError.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, v := range e {
+		messages[i] = v.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e ValidationErrors) Unwrap() []error {
+	out := make([]error, len(e))
+	for i, v := range e {
+		out[i] = v
+	}
+	return out
+}
+
+// Add suma err si es un error de validación (simple o múltiple) y devuelve nil; nil no
+// suma nada. Cualquier otro error no es del cliente: se devuelve tal cual para cortar
+// la validación.
+func (e *ValidationErrors) Add(err error) error {
+	var many ValidationErrors
+	var one *ValidationError
+	switch {
+	case err == nil:
+	case errors.As(err, &many):
+		*e = append(*e, many...)
+	case errors.As(err, &one):
+		*e = append(*e, one)
+	default:
+		return err
+	}
+	return nil
+}
+
+// Has indica si ya hay un error para field
+func (e ValidationErrors) Has(field string) bool {
+	for _, v := range e {
+		if v.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Err devuelve nil si no hubo errores; así un ValidationErrors vacío no llega como error no nil
+func (e ValidationErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Nombres de los eventos del ciclo de vida de un libro. Son estables: los ven los
+// suscriptores de fuera del proceso.
+const (
+	EventBookCreated  = "book.created"
+	EventBookUpdated  = "book.updated"
+	EventBookDeleted  = "book.deleted"
+	EventBookRestored = "book.restored"
+
+	// AllEvents, al suscribirse, recibe todos los eventos
+	AllEvents = "*"
+)
+
+// Event es algo que ya le pasó a un libro. Se publica en la transacción del cambio
+// (ver OutboxRepository), así no hay eventos de cambios deshechos ni cambios sin evento.
+type Event interface {
+	EventName() string
+	Meta() EventMeta
+}
+
+// EventMeta son los datos comunes a todos los eventos: qué libro, quién y cuándo.
+// ID es el mismo en cada entrega de un evento (ver EventID), para descartar repetidos.
+type EventMeta struct {
+	ID         string    `json:"id"`
+	BookID     uint      `json:"book_id"`
+	Actor      string    `json:"actor"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (m EventMeta) Meta() EventMeta { return m }
+
+// BookCreated: se dio de alta un libro
+type BookCreated struct {
+	EventMeta
+	Book Book `json:"book"`
+}
+
+func (BookCreated) EventName() string { return EventBookCreated }
+
+// BookUpdated: cambiaron campos de un libro, por una actualización o un revert
+type BookUpdated struct {
+	EventMeta
+	Book    Book          `json:"book"`
+	Changes []FieldChange `json:"changes"`
+	// RevertedTo es la revisión restaurada si el cambio fue un revert
+	RevertedTo uint `json:"reverted_to,omitempty"`
+}
+
+func (BookUpdated) EventName() string { return EventBookUpdated }
+
+// BookDeleted: un libro pasó a la papelera. Book es el libro tal como estaba.
+type BookDeleted struct {
+	EventMeta
+	Book Book `json:"book"`
+}
+
+func (BookDeleted) EventName() string { return EventBookDeleted }
+
+// BookRestored: un libro salió de la papelera
+type BookRestored struct {
+	EventMeta
+	Book Book `json:"book"`
+}
+
+func (BookRestored) EventName() string { return EventBookRestored }
+
+// EventHandler reacciona a un evento. Un error no deshace el cambio, que ya se guardó;
+// el relay del outbox vuelve a entregar el evento más tarde.
+type EventHandler func(ctx context.Context, event Event) error
+
+// EventPublisher entrega eventos a quien corresponda
+type EventPublisher interface {
+	Publish(ctx context.Context, events ...Event) error
+}
+
+// EventBus reparte los eventos publicados entre los suscriptores de cada nombre
+// (o de AllEvents). Las implementaciones deciden si la entrega es en la misma
+// goroutine que Publish o en segundo plano.
+type EventBus interface {
+	EventPublisher
+	// Subscribe registra handler para los eventos llamados name; se llama al arrancar
+	Subscribe(name string, handler EventHandler)
+}
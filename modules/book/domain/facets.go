@@ -0,0 +1,26 @@
+package domain
+
+// MaxFacetValues limita los valores por faceta (los más frecuentes primero)
+const MaxFacetValues = 20
+
+// BookFacets cuenta los libros que cumplen un filtro agrupados por género, década y autor.
+// Sirve para armar la barra lateral de filtros del catálogo.
+type BookFacets struct {
+	Genre  map[string]int // género -> cantidad
+	Decade map[string]int // "1960" -> cantidad de libros de 1960 a 1969
+	Author map[string]int // autor -> cantidad
+}
+
+// NewBookFacets crea facetas vacías listas para sumar.
+func NewBookFacets() *BookFacets {
+	return &BookFacets{
+		Genre:  map[string]int{},
+		Decade: map[string]int{},
+		Author: map[string]int{},
+	}
+}
+
+// Decade devuelve el año de inicio de la década de un año: 1967 -> 1960.
+func Decade(year uint) uint {
+	return year / 10 * 10
+}
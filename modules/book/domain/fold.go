@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// FoldText normaliza un texto para búsquedas: minúsculas, sin tildes ni diéresis
+// y sin espacios sobrantes. "GARCÍA  Márquez" -> "garcia marquez".
+// La ñ también se pliega a n, igual que hace el índice de texto completo.
+func FoldText(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	return strings.Join(strings.Fields(strings.ToLower(folded)), " ")
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// RelevanceField es el orden implícito de una búsqueda de texto libre sin sort explícito.
+// No está en la lista blanca: el cliente no puede pedirlo, solo se aplica con q=.
+const RelevanceField = "relevance"
+
+// BookMatch describe por qué un libro coincide con una búsqueda de texto libre.
+type BookMatch struct {
+	Score      float64           // menor es más relevante (convención de bm25)
+	Highlights map[string]string // campo -> valor con los términos marcados
+}
+
+// FullTextTerms separa una consulta libre en términos en minúsculas,
+// cortando en todo lo que no sea letra o dígito.
+func FullTextTerms(q string) []string {
+	return strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// EffectiveSort devuelve el orden a aplicar: el pedido o, si la consulta tiene
+// texto libre (no negado) y no se pidió orden, la relevancia.
+func (f BookFilter) EffectiveSort() []SortField {
+	if len(f.Sort) == 0 && f.Query != "" {
+		if node, err := ParseQuery(f.Query); err == nil && len(RankingTerms(node)) > 0 {
+			return []SortField{{Field: RelevanceField}}
+		}
+	}
+	return f.Sort
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+//go:generate mockgen -source=outbox.go -destination=../application/mocks/mock_outbox_repository.go -package=mocks
+
+// OutboxMessage es un evento guardado en el outbox, en la misma transacción que el
+// cambio del libro, hasta que el relay lo entrega a los suscriptores
+type OutboxMessage struct {
+	ID uint
+	// DedupeKey es el ID del evento: el outbox no guarda dos veces el mismo y los
+	// suscriptores lo usan para descartar repetidos (la entrega es al menos una vez)
+	DedupeKey   string
+	EventName   string
+	BookID      uint
+	Payload     []byte // el evento en JSON
+	Attempts    int
+	LastError   string
+	CreatedAt   time.Time
+	AvailableAt time.Time  // no se intenta entregar antes
+	PublishedAt *time.Time // entregado
+	FailedAt    *time.Time // se dejó de reintentar
+}
+
+// OutboxBacklog resume lo que falta entregar
+type OutboxBacklog struct {
+	Pending         int
+	Failed          int
+	OldestPendingAt *time.Time
+	// Messages son los pendientes y los fallidos, del más viejo al más nuevo
+	Messages []*OutboxMessage
+}
+
+// OutboxRepository guarda los eventos por entregar. Add corre en la transacción del
+// cambio del libro (la que viaja en ctx); el resto lo usa el relay.
+type OutboxRepository interface {
+	// Add guarda msg y le asigna ID; si ya hay uno con el mismo DedupeKey no hace nada
+	Add(ctx context.Context, msg *OutboxMessage) error
+	// Due obtiene hasta limit mensajes pendientes con AvailableAt <= now, por orden de llegada
+	Due(ctx context.Context, now time.Time, limit int) ([]*OutboxMessage, error)
+	// MarkPublished marca un mensaje como entregado
+	MarkPublished(ctx context.Context, id uint, at time.Time) error
+	// MarkRetry registra un intento fallido y deja el mensaje para retryAt
+	MarkRetry(ctx context.Context, id uint, reason string, retryAt time.Time) error
+	// MarkFailed registra el último intento fallido: el mensaje no se reintenta más
+	MarkFailed(ctx context.Context, id uint, reason string, at time.Time) error
+	// Backlog cuenta los pendientes y fallidos y devuelve hasta limit de ellos
+	Backlog(ctx context.Context, limit int) (*OutboxBacklog, error)
+}
+
+// EventID identifica un cambio de un libro: la versión sube en cada cambio, así que
+// nombre, libro y versión no se repiten
+func EventID(name string, bookID, version uint) string {
+	return fmt.Sprintf("%s:%d:%d", name, bookID, version)
+}
+
+// NewOutboxMessage serializa event para el outbox
+func NewOutboxMessage(event Event) (*OutboxMessage, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	meta := event.Meta()
+	return &OutboxMessage{
+		DedupeKey:   meta.ID,
+		EventName:   event.EventName(),
+		BookID:      meta.BookID,
+		Payload:     payload,
+		CreatedAt:   meta.OccurredAt,
+		AvailableAt: meta.OccurredAt,
+	}, nil
+}
+
+// DecodeEvent reconstruye el evento de un mensaje del outbox
+func DecodeEvent(name string, payload []byte) (Event, error) {
+	var (
+		event Event
+		err   error
+	)
+	switch name {
+	case EventBookCreated:
+		var e BookCreated
+		err = json.Unmarshal(payload, &e)
+		event = e
+	case EventBookUpdated:
+		var e BookUpdated
+		err = json.Unmarshal(payload, &e)
+		event = e
+	case EventBookDeleted:
+		var e BookDeleted
+		err = json.Unmarshal(payload, &e)
+		event = e
+	case EventBookRestored:
+		var e BookRestored
+		err = json.Unmarshal(payload, &e)
+		event = e
+	default:
+		return nil, fmt.Errorf("unknown event %q", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", name, err)
+	}
+	return event, nil
+}
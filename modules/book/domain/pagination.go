@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+const (
+	// DefaultPageLimit se usa cuando el cliente no indica limit
+	DefaultPageLimit = 20
+	// MaxPageLimit evita que un cliente pida todo el catálogo de una vez
+	MaxPageLimit = 100
+)
+
+// BookPage es una página de resultados junto con los datos para pedir la siguiente.
+type BookPage struct {
+	Books      []*Book
+	Total      int    // total de libros que cumplen el filtro (sin paginar)
+	NextCursor string // cursor opaco para la siguiente página, vacío si no hay más
+	HasMore    bool
+
+	// Matches trae relevancia y resaltados por ID de libro; solo con búsqueda de texto libre
+	Matches map[uint]BookMatch
+
+	// Suggestions propone valores parecidos cuando la búsqueda no encontró nada
+	Suggestions []Suggestion
+}
+
+// Cursor es la posición (keyset) desde la que continuar una búsqueda.
+// Guarda los valores del último libro entregado para cada campo de StableSort.
+// Se serializa como base64 para que el cliente lo trate como un valor opaco.
+type Cursor struct {
+	Sort   string `json:"s,omitempty"` // orden con el que se generó el cursor
+	Values []any  `json:"v"`
+}
+
+// NewCursor construye el cursor que apunta justo después del libro dado.
+func NewCursor(b *Book, sort []SortField) Cursor {
+	return NewRankedCursor(b, 0, sort)
+}
+
+// NewRankedCursor es como NewCursor pero incluye la relevancia del libro,
+// necesaria cuando el orden es por RelevanceField.
+func NewRankedCursor(b *Book, score float64, sort []SortField) Cursor {
+	keys := StableSort(sort)
+	values := make([]any, len(keys))
+	for i, sf := range keys {
+		if sf.Field == RelevanceField {
+			values[i] = score
+		} else {
+			values[i] = b.SortValue(sf.Field)
+		}
+	}
+	return Cursor{Sort: FormatSort(sort), Values: values}
+}
+
+// EncodeCursor convierte el cursor en un string opaco.
+func EncodeCursor(c Cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor interpreta un cursor generado por EncodeCursor para el orden dado
+// y restaura el tipo de cada valor.
+func DecodeCursor(s string, sort []SortField) (Cursor, error) {
+	var c Cursor
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, invalid("cursor", CodeInvalidFormat, "invalid cursor")
+	}
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return c, invalid("cursor", CodeInvalidFormat, "invalid cursor")
+	}
+	if c.Sort != FormatSort(sort) {
+		return c, invalid("cursor", CodeInconsistent, "cursor does not match sort")
+	}
+	keys := StableSort(sort)
+	if len(c.Values) != len(keys) {
+		return c, invalid("cursor", CodeInvalidFormat, "invalid cursor")
+	}
+	for i, sf := range keys {
+		v, err := sortValueFromJSON(sf.Field, c.Values[i])
+		if err != nil {
+			return c, err
+		}
+		c.Values[i] = v
+	}
+	return c, nil
+}
+
+// NormalizePage valida la paginación del filtro y aplica valores por defecto.
+func (f *BookFilter) NormalizePage() error {
+	if f.Limit < 0 {
+		return invalid("limit", CodeOutOfRange, "limit must be positive")
+	}
+	if f.Offset < 0 {
+		return invalid("offset", CodeOutOfRange, "offset must be positive")
+	}
+	if f.Limit == 0 {
+		f.Limit = DefaultPageLimit
+	}
+	if f.Limit > MaxPageLimit {
+		f.Limit = MaxPageLimit
+	}
+	if f.Cursor != "" {
+		if f.Offset > 0 {
+			return invalid("cursor", CodeInconsistent, "offset and cursor cannot be used together")
+		}
+		if _, err := DecodeCursor(f.Cursor, f.EffectiveSort()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
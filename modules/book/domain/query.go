@@ -0,0 +1,405 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Lenguaje de consulta para búsqueda avanzada (q=). Ejemplo:
+//
+//	author:"Borges" AND (genre:cuento OR genre:ensayo) AND year>=1940 -title:antología
+//
+// Gramática (NOT liga más fuerte que AND, y AND más que OR):
+//
+//	query   := or
+//	or      := and ( "OR" and )*
+//	and     := unary ( ["AND"] unary )*      palabras seguidas = AND implícito
+//	unary   := ("NOT" | "-") unary | primary
+//	primary := "(" or ")" | field op value | value
+//	op      := ":" | "=" | ">" | ">=" | "<" | "<="
+//	value   := palabra | "frase entre comillas"
+//
+// Un valor sin campo es texto libre (índice de texto completo, por prefijo). Una
+// palabra que no es un campo conocido es texto aunque lleve ":" pegado.
+// title/author/genre con ":" buscan el texto contenido y con "=" el valor exacto
+// (sin tildes ni mayúsculas); isbn con ":" es prefijo; year admite comparaciones.
+// Los operadores AND/OR/NOT van en mayúsculas: "guerra y paz" son tres palabras.
+
+// QueryNode es un nodo del árbol de una consulta.
+type QueryNode interface {
+	queryNode()
+}
+
+// AndNode se cumple si se cumplen todos sus hijos.
+type AndNode struct{ Children []QueryNode }
+
+// OrNode se cumple si se cumple alguno de sus hijos.
+type OrNode struct{ Children []QueryNode }
+
+// NotNode niega a su hijo.
+type NotNode struct{ Child QueryNode }
+
+// FieldTerm compara un campo de Book con un valor: author:borges, year>=1940.
+type FieldTerm struct {
+	Field string
+	Op    string // ":", "=", ">", ">=", "<", "<="
+	Value string
+	Pos   int // posición (1 = primer carácter) en la consulta original
+}
+
+// TextTerm es texto libre, buscado en título, autor y género.
+type TextTerm struct {
+	Text   string
+	Phrase bool // entre comillas: las palabras deben ir juntas y completas
+	Pos    int
+}
+
+func (AndNode) queryNode()   {}
+func (OrNode) queryNode()    {}
+func (NotNode) queryNode()   {}
+func (FieldTerm) queryNode() {}
+func (TextTerm) queryNode()  {}
+
+// QuerySyntaxError indica qué está mal en la consulta y dónde.
+type QuerySyntaxError struct {
+	Pos int // posición (1 = primer carácter)
+	Msg string
+}
+
+func (e *QuerySyntaxError) Error() string {
+	return fmt.Sprintf("query syntax error at position %d: %s", e.Pos, e.Msg)
+}
+
+// queryFields son los campos consultables y los operadores que aceptan.
+var queryFields = map[string]map[string]bool{
+	"title":  {":": true, "=": true},
+	"author": {":": true, "=": true},
+	"genre":  {":": true, "=": true},
+	"isbn":   {":": true, "=": true},
+	"year":   {":": true, "=": true, ">": true, ">=": true, "<": true, "<=": true},
+}
+
+// ParseQuery convierte una consulta en su árbol. Una consulta vacía devuelve nil.
+func ParseQuery(q string) (QueryNode, error) {
+	tokens, err := lexQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	p := &queryParser{tokens: tokens, end: len([]rune(q)) + 1}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t != nil {
+		if t.kind == tokRParen {
+			return nil, &QuerySyntaxError{Pos: t.pos, Msg: "unexpected ')'"}
+		}
+		return nil, &QuerySyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %q", t.text)}
+	}
+	return node, nil
+}
+
+// RankingTerms devuelve los términos de texto libre que no están negados:
+// son los que sirven para ordenar por relevancia y resaltar.
+func RankingTerms(node QueryNode) []TextTerm {
+	out := []TextTerm{}
+	var walk func(n QueryNode, negated bool)
+	walk = func(n QueryNode, negated bool) {
+		switch v := n.(type) {
+		case AndNode:
+			for _, c := range v.Children {
+				walk(c, negated)
+			}
+		case OrNode:
+			for _, c := range v.Children {
+				walk(c, negated)
+			}
+		case NotNode:
+			walk(v.Child, !negated)
+		case TextTerm:
+			if !negated && len(FullTextTerms(v.Text)) > 0 {
+				out = append(out, v)
+			}
+		}
+	}
+	if node != nil {
+		walk(node, false)
+	}
+	return out
+}
+
+// FieldValues devuelve los valores buscados en un campo (sin negar), útil para sugerencias.
+func FieldValues(node QueryNode, field string) []string {
+	out := []string{}
+	var walk func(n QueryNode, negated bool)
+	walk = func(n QueryNode, negated bool) {
+		switch v := n.(type) {
+		case AndNode:
+			for _, c := range v.Children {
+				walk(c, negated)
+			}
+		case OrNode:
+			for _, c := range v.Children {
+				walk(c, negated)
+			}
+		case NotNode:
+			walk(v.Child, !negated)
+		case FieldTerm:
+			if !negated && v.Field == field {
+				out = append(out, v.Value)
+			}
+		}
+	}
+	if node != nil {
+		walk(node, false)
+	}
+	return out
+}
+
+// Lexer
+
+type queryTokenKind int
+
+const (
+	tokWord queryTokenKind = iota
+	tokPhrase
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+	tokOp
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	pos  int
+	// spaceBefore indica si hubo espacio antes: "author:x" es un término, "author: x" no
+	spaceBefore bool
+}
+
+func lexQuery(q string) ([]queryToken, error) {
+	runes := []rune(q)
+	tokens := []queryToken{}
+	space := true
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: tokLParen, text: "(", pos: pos, spaceBefore: space})
+			i++
+			space = true // tras "(" puede venir una negación con guion
+			continue
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: tokRParen, text: ")", pos: pos, spaceBefore: space})
+			i++
+		case r == '"':
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' {
+				j++
+			}
+			if j >= len(runes) {
+				return nil, &QuerySyntaxError{Pos: pos, Msg: "unterminated quote"}
+			}
+			tokens = append(tokens, queryToken{kind: tokPhrase, text: string(runes[i+1 : j]), pos: pos, spaceBefore: space})
+			i = j + 1
+		case r == ':' || r == '=' || r == '>' || r == '<':
+			op := string(r)
+			if (r == '>' || r == '<') && i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			tokens = append(tokens, queryToken{kind: tokOp, text: op, pos: pos, spaceBefore: space})
+			i += len([]rune(op))
+		case r == '-' && space:
+			// Un guion al inicio de un término es negación; dentro de una palabra es parte de ella (978-84)
+			tokens = append(tokens, queryToken{kind: tokNot, text: "-", pos: pos, spaceBefore: space})
+			i++
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune(`()":=<>`, runes[j]) {
+				j++
+			}
+			word := string(runes[i:j])
+			t := queryToken{kind: tokWord, text: word, pos: pos, spaceBefore: space}
+			switch word {
+			case "AND":
+				t.kind = tokAnd
+			case "OR":
+				t.kind = tokOr
+			case "NOT":
+				t.kind = tokNot
+			}
+			tokens = append(tokens, t)
+			i = j
+		}
+		space = false
+	}
+	return tokens, nil
+}
+
+// Parser (descenso recursivo)
+
+type queryParser struct {
+	tokens []queryToken
+	i      int
+	end    int // posición del final, para errores de "falta algo"
+}
+
+func (p *queryParser) peek() *queryToken {
+	if p.i >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.i]
+}
+
+func (p *queryParser) next() *queryToken {
+	t := p.peek()
+	if t != nil {
+		p.i++
+	}
+	return t
+}
+
+func (p *queryParser) parseOr() (QueryNode, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []QueryNode{first}
+	for t := p.peek(); t != nil && t.kind == tokOr; t = p.peek() {
+		p.next()
+		if p.peek() == nil {
+			return nil, &QuerySyntaxError{Pos: p.end, Msg: "expected term after OR"}
+		}
+		n, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, n)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return OrNode{Children: children}, nil
+}
+
+func (p *queryParser) parseAnd() (QueryNode, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	children := []QueryNode{first}
+	for {
+		t := p.peek()
+		if t == nil || t.kind == tokOr || t.kind == tokRParen {
+			break
+		}
+		if t.kind == tokAnd {
+			p.next()
+			if p.peek() == nil {
+				return nil, &QuerySyntaxError{Pos: p.end, Msg: "expected term after AND"}
+			}
+		}
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, n)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return AndNode{Children: children}, nil
+}
+
+func (p *queryParser) parseUnary() (QueryNode, error) {
+	if t := p.peek(); t != nil && t.kind == tokNot {
+		p.next()
+		if p.peek() == nil {
+			return nil, &QuerySyntaxError{Pos: p.end, Msg: fmt.Sprintf("expected term after %q", t.text)}
+		}
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return NotNode{Child: child}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (QueryNode, error) {
+	t := p.next()
+	if t == nil {
+		return nil, &QuerySyntaxError{Pos: p.end, Msg: "expected term"}
+	}
+	switch t.kind {
+	case tokLParen:
+		if n := p.peek(); n != nil && n.kind == tokRParen {
+			return nil, &QuerySyntaxError{Pos: n.pos, Msg: "empty parentheses"}
+		}
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing := p.next()
+		if closing == nil || closing.kind != tokRParen {
+			return nil, &QuerySyntaxError{Pos: t.pos, Msg: "missing closing parenthesis"}
+		}
+		return node, nil
+	case tokPhrase:
+		return TextTerm{Text: t.text, Phrase: true, Pos: t.pos}, nil
+	case tokWord:
+		// ¿Es campo+operador pegados? (author:borges, year>=1940)
+		if op := p.peek(); op != nil && op.kind == tokOp && !op.spaceBefore {
+			if _, ok := queryFields[strings.ToLower(t.text)]; ok {
+				return p.parseFieldTerm(t)
+			}
+			// Solo los campos conocidos: en "Harry Potter: la piedra" los dos puntos
+			// son parte del texto y se descartan, como el resto de la puntuación
+			for op := p.peek(); op != nil && op.kind == tokOp && !op.spaceBefore; op = p.peek() {
+				p.next()
+			}
+		}
+		return TextTerm{Text: t.text, Pos: t.pos}, nil
+	case tokRParen:
+		return nil, &QuerySyntaxError{Pos: t.pos, Msg: "unexpected ')'"}
+	case tokOp:
+		return nil, &QuerySyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %q", t.text)}
+	default:
+		return nil, &QuerySyntaxError{Pos: t.pos, Msg: fmt.Sprintf("expected term, found %q", t.text)}
+	}
+}
+
+func (p *queryParser) parseFieldTerm(fieldTok *queryToken) (QueryNode, error) {
+	op := p.next()
+	field := strings.ToLower(fieldTok.text)
+	ops := queryFields[field]
+	if !ops[op.text] {
+		return nil, &QuerySyntaxError{Pos: op.pos, Msg: fmt.Sprintf("operator %q not allowed for %s", op.text, field)}
+	}
+	v := p.next()
+	if v == nil || v.spaceBefore || (v.kind != tokWord && v.kind != tokPhrase) {
+		pos := p.end
+		if v != nil {
+			pos = v.pos
+		}
+		return nil, &QuerySyntaxError{Pos: pos, Msg: fmt.Sprintf("expected value after %s%s", field, op.text)}
+	}
+	if field == "year" {
+		if _, err := strconv.ParseUint(v.text, 10, 32); err != nil {
+			return nil, &QuerySyntaxError{Pos: v.pos, Msg: fmt.Sprintf("year must be a number, found %q", v.text)}
+		}
+	}
+	return FieldTerm{Field: field, Op: op.text, Value: v.text, Pos: fieldTok.pos}, nil
+}
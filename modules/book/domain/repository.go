@@ -1,6 +1,9 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 //go:generate mockgen -source=repository.go -destination=../application/mocks/mock_book_repository.go -package=mocks
 
@@ -10,14 +13,32 @@ type BookRepository interface {
 	Create(ctx context.Context, book *Book) error
 	// Update actualiza un libro existente en el repositorio
 	Update(ctx context.Context, book *Book) (*Book, error)
-	// Delete elimina un libro existente en el repositorio
+	// Delete mueve un libro a la papelera (borrado lógico): deja de aparecer en las
+	// lecturas y su ISBN queda libre para otro libro
 	Delete(ctx context.Context, id uint) error
+	// ListDeleted obtiene los libros de la papelera, del borrado más reciente al más viejo
+	ListDeleted(ctx context.Context) ([]*Book, error)
+	// Restore saca un libro de la papelera y sube su versión. Devuelve ErrNotFound si no
+	// está en la papelera y ErrConflict, que envuelve ErrDuplicateISBN, si otro libro tomó
+	// su ISBN mientras tanto
+	Restore(ctx context.Context, id uint) (*Book, error)
+	// Purge elimina definitivamente los libros que están en la papelera desde antes de
+	// deletedBefore y devuelve cuántos eliminó
+	Purge(ctx context.Context, deletedBefore time.Time) (int, error)
 	// GetAll obtiene todos los libros del repositorio
 	GetAll(ctx context.Context) ([]*Book, error)
 	// GetByID obtiene un libro por su ID del repositorio
 	GetByID(ctx context.Context, id uint) (*Book, error)
-	// FindByFilter obtiene libros por filtros del repositorio
-	FindByFilter(ctx context.Context, filter BookFilter) ([]*Book, error)
+	// FindByFilter obtiene una página de libros por filtros del repositorio
+	FindByFilter(ctx context.Context, filter BookFilter) (*BookPage, error)
 	// GetByISBN obtiene libros por ISBN del repositorio
 	GetByISBN(ctx context.Context, isbn string) (*Book, error)
+	// CountFacets cuenta los libros que cumplen el filtro por género, década y autor
+	CountFacets(ctx context.Context, filter BookFilter) (*BookFacets, error)
+	// SuggestByPrefix obtiene los valores distintos de title o author que empiezan por el prefijo
+	// (el valor completo o alguna de sus palabras), de más a menos frecuente
+	SuggestByPrefix(ctx context.Context, field, prefix string, limit int) ([]ValueCount, error)
+	// SuggestionCandidates obtiene hasta limit valores distintos de title o author con alguna
+	// palabra que empieza por uno de prefixes (ver CandidatePrefixes), de más a menos frecuente
+	SuggestionCandidates(ctx context.Context, field string, prefixes []string, limit int) ([]string, error)
 }
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// RetryPolicy decide cuántas veces y cada cuánto se reintenta una entrega que falló:
+// la espera se duplica en cada intento, desde BaseDelay hasta MaxDelay
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy reintenta durante unas horas antes de rendirse
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 10, BaseDelay: 5 * time.Second, MaxDelay: time.Hour}
+
+// Delay es la espera antes del intento siguiente a attempts intentos fallidos (desde 1)
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// GiveUp indica si tras attempts intentos fallidos ya no se reintenta
+func (p RetryPolicy) GiveUp(attempts int) bool {
+	return attempts >= p.MaxAttempts
+}
@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+//go:generate mockgen -source=revision.go -destination=../application/mocks/mock_revision_repository.go -package=mocks
+
+// Acciones que registra una revisión
+const (
+	RevisionCreate  = "create"
+	RevisionUpdate  = "update"
+	RevisionDelete  = "delete"
+	RevisionRevert  = "revert"
+	RevisionRestore = "restore" // el libro sale de la papelera
+)
+
+// FieldChange es el cambio de un campo del libro. Los valores se guardan como texto
+// (el año también); From está vacío al crear y To al borrar.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Revision es un cambio del historial de un libro: quién, cuándo y qué campos.
+// Number cuenta las revisiones de cada libro desde 1.
+type Revision struct {
+	ID         uint
+	BookID     uint
+	Number     uint
+	Action     string // una de las constantes Revision*
+	Actor      string
+	Changes    []FieldChange
+	RevertedTo uint // revisión restaurada, solo en RevisionRevert
+	CreatedAt  time.Time
+}
+
+// RevisionRepository guarda el historial de cambios de los libros. Las revisiones
+// no se modifican ni se borran, ni siquiera al borrar el libro.
+type RevisionRepository interface {
+	// Append guarda rev con el siguiente Number de su libro y le asigna ID y Number
+	Append(ctx context.Context, rev *Revision) error
+	// ListByBook obtiene las revisiones de un libro, de la más vieja a la más nueva
+	ListByBook(ctx context.Context, bookID uint) ([]*Revision, error)
+}
+
+// bookFields son los campos con historial, en el orden en que aparecen en los cambios
+var bookFields = []string{"title", "author", "year", "genre", "isbn"}
+
+// fieldValues devuelve el valor de cada campo con historial como texto; nil no tiene valores
+func (b *Book) fieldValues() map[string]string {
+	if b == nil {
+		return map[string]string{}
+	}
+	return map[string]string{
+		"title":  b.Title,
+		"author": b.Author,
+		"year":   strconv.FormatUint(uint64(b.Year), 10),
+		"genre":  b.Genre,
+		"isbn":   b.ISBN,
+	}
+}
+
+// DiffBooks lista los campos que cambian de before a after. before es nil al crear
+// y after al borrar.
+func DiffBooks(before, after *Book) []FieldChange {
+	from, to := before.fieldValues(), after.fieldValues()
+	changes := []FieldChange{}
+	for _, field := range bookFields {
+		if from[field] != to[field] {
+			changes = append(changes, FieldChange{Field: field, From: from[field], To: to[field]})
+		}
+	}
+	return changes
+}
+
+// StateAt reconstruye los campos del libro tal como quedaron en la revisión number,
+// aplicando en orden los cambios de revs (el historial completo de un libro).
+// Los libros anteriores al historial no tienen revisión create: los campos que no
+// cambiaron hasta number toman el valor previo al primer cambio posterior o, si
+// nunca cambiaron, el de current. El resultado sirve para volver a esa versión con
+// Book.Update.
+func StateAt(current *Book, revs []*Revision, number uint) (UpdateBookInput, error) {
+	values := map[string]string{}
+	for i, rev := range revs {
+		if rev.Number > number {
+			break
+		}
+		if rev.Number == number && rev.Action == RevisionDelete {
+			return UpdateBookInput{}, invalid("revision", CodeInvalidValue, fmt.Sprintf("revision %d deleted the book", number))
+		}
+		for _, c := range rev.Changes {
+			values[c.Field] = c.To
+		}
+		if rev.Number == number {
+			seedUnchanged(values, current, revs[i+1:])
+			year, err := strconv.ParseUint(values["year"], 10, 32)
+			if err != nil {
+				return UpdateBookInput{}, invalid("revision", CodeInvalidValue,
+					fmt.Sprintf("revision %d of book %d has an invalid year %q", number, rev.BookID, values["year"]))
+			}
+			y := uint(year)
+			title, author, genre, isbn := values["title"], values["author"], values["genre"], values["isbn"]
+			return UpdateBookInput{Title: &title, Author: &author, Year: &y, Genre: &genre, ISBN: &isbn}, nil
+		}
+	}
+	return UpdateBookInput{}, fmt.Errorf("revision %d %w", number, ErrNotFound)
+}
+
+// seedUnchanged completa en values los campos sin cambios hasta ahora: el From del
+// primer cambio en later o, si no cambian después, el valor actual
+func seedUnchanged(values map[string]string, current *Book, later []*Revision) {
+	for _, rev := range later {
+		for _, c := range rev.Changes {
+			if _, ok := values[c.Field]; !ok {
+				values[c.Field] = c.From
+			}
+		}
+	}
+	for field, v := range current.fieldValues() {
+		if _, ok := values[field]; !ok {
+			values[field] = v
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	// SuggestionThreshold es la similitud mínima para sugerir un valor (igual que pg_trgm)
+	SuggestionThreshold = 0.3
+	// MaxSuggestions limita las sugerencias por búsqueda
+	MaxSuggestions = 5
+	// MaxSuggestionCandidates limita los valores del catálogo que se puntúan por búsqueda
+	MaxSuggestionCandidates = 200
+	// candidatePrefixLen es cuántas letras de cada palabra buscada deben coincidir con
+	// el comienzo de una palabra del candidato
+	candidatePrefixLen = 2
+)
+
+// Suggestion es un valor existente del catálogo parecido a lo que se buscó ("quizás quisiste decir").
+type Suggestion struct {
+	Field string  // "title" o "author"
+	Value string  // valor tal cual está en el catálogo
+	Score float64 // similitud entre 0 y 1
+}
+
+// trigrams devuelve los trigramas de una palabra al estilo pg_trgm: "  borges " -> "  b", " bo", "bor", ...
+func trigrams(word string) map[string]bool {
+	out := map[string]bool{}
+	r := []rune("  " + word + " ")
+	for i := 0; i+3 <= len(r); i++ {
+		out[string(r[i:i+3])] = true
+	}
+	return out
+}
+
+// TrigramSimilarity compara dos textos por trigramas tras plegarlos (sin tildes ni mayúsculas).
+// Devuelve un valor entre 0 (nada en común) y 1 (iguales).
+func TrigramSimilarity(a, b string) float64 {
+	ta, tb := map[string]bool{}, map[string]bool{}
+	for _, w := range strings.Fields(FoldText(a)) {
+		for t := range trigrams(w) {
+			ta[t] = true
+		}
+	}
+	for _, w := range strings.Fields(FoldText(b)) {
+		for t := range trigrams(w) {
+			tb[t] = true
+		}
+	}
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// WordSimilarity compara la búsqueda contra el candidato completo y contra cada
+// tramo de palabras consecutivas del mismo largo que la búsqueda, y se queda con
+// la mejor. Así "borjes" se parece a "Jorge Luis Borges" aunque sea mucho más corto.
+func WordSimilarity(query, candidate string) float64 {
+	best := TrigramSimilarity(query, candidate)
+	qWords := len(strings.Fields(query))
+	cWords := strings.Fields(candidate)
+	if qWords == 0 {
+		return 0
+	}
+	for i := 0; i+qWords <= len(cWords); i++ {
+		if s := TrigramSimilarity(query, strings.Join(cWords[i:i+qWords], " ")); s > best {
+			best = s
+		}
+	}
+	return best
+}
+
+// RankSuggestions puntúa los candidatos contra la búsqueda y devuelve los mejores
+// que superan SuggestionThreshold, del más al menos parecido.
+func RankSuggestions(field, query string, candidates []string) []Suggestion {
+	out := []Suggestion{}
+	folded := FoldText(query)
+	for _, c := range candidates {
+		if FoldText(c) == folded {
+			continue // si fuera idéntico ya habría resultados
+		}
+		if score := WordSimilarity(query, c); score >= SuggestionThreshold {
+			out = append(out, Suggestion{Field: field, Value: c, Score: score})
+		}
+	}
+	return topSuggestions(out)
+}
+
+// CandidatePrefixes devuelve el comienzo plegado de cada palabra de la búsqueda, sin
+// repetir. Los repositorios buscan candidatos con alguna palabra que empiece así:
+// un error de tipeo en las primeras letras no se sugiere, a cambio de no recorrer
+// todo el catálogo.
+func CandidatePrefixes(query string) []string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, term := range FullTextTerms(FoldText(query)) {
+		r := []rune(term)
+		if len(r) > candidatePrefixLen {
+			r = r[:candidatePrefixLen]
+		}
+		if p := string(r); !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// MergeSuggestions junta las sugerencias de varias búsquedas: un mismo valor de un
+// campo aparece una vez, con su mejor puntaje, y en total no más de MaxSuggestions.
+func MergeSuggestions(lists ...[]Suggestion) []Suggestion {
+	best := map[[2]string]int{}
+	out := []Suggestion{}
+	for _, list := range lists {
+		for _, s := range list {
+			key := [2]string{s.Field, s.Value}
+			if i, ok := best[key]; ok {
+				if s.Score > out[i].Score {
+					out[i].Score = s.Score
+				}
+				continue
+			}
+			best[key] = len(out)
+			out = append(out, s)
+		}
+	}
+	return topSuggestions(out)
+}
+
+// topSuggestions ordena del más al menos parecido y se queda con MaxSuggestions
+func topSuggestions(out []Suggestion) []Suggestion {
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		if out[i].Value != out[j].Value {
+			return out[i].Value < out[j].Value
+		}
+		return out[i].Field < out[j].Field
+	})
+	if len(out) > MaxSuggestions {
+		out = out[:MaxSuggestions]
+	}
+	return out
+}
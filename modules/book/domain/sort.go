@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SortField es un criterio de ordenamiento sobre un campo de Book.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// sortableFields es la lista blanca de campos por los que se puede ordenar.
+// Coinciden con los nombres de columna (tag db) de Book.
+var sortableFields = map[string]bool{
+	"id":         true,
+	"title":      true,
+	"author":     true,
+	"year":       true,
+	"genre":      true,
+	"isbn":       true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// IsSortable indica si el campo está en la lista blanca de ordenamiento.
+func IsSortable(field string) bool {
+	return sortableFields[field]
+}
+
+// ParseSort interpreta expresiones como "-year,title": un guion indica orden descendente.
+func ParseSort(s string) ([]SortField, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	out := []SortField{}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		sf := SortField{}
+		if strings.HasPrefix(part, "-") {
+			sf.Desc = true
+			part = part[1:]
+		} else {
+			part = strings.TrimPrefix(part, "+")
+		}
+		field := strings.ToLower(part)
+		if !IsSortable(field) {
+			return nil, invalid("sort", CodeInvalidValue, fmt.Sprintf("cannot sort by %q", part))
+		}
+		if seen[field] {
+			return nil, invalid("sort", CodeDuplicate, fmt.Sprintf("duplicate sort field %q", field))
+		}
+		seen[field] = true
+		sf.Field = field
+		out = append(out, sf)
+	}
+	return out, nil
+}
+
+// FormatSort es la inversa de ParseSort.
+func FormatSort(sort []SortField) string {
+	parts := make([]string, len(sort))
+	for i, sf := range sort {
+		if sf.Desc {
+			parts[i] = "-" + sf.Field
+		} else {
+			parts[i] = sf.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// StableSort devuelve el orden efectivo: el pedido más id como desempate,
+// para que la paginación por cursor sea determinista.
+func StableSort(sort []SortField) []SortField {
+	for _, sf := range sort {
+		if sf.Field == "id" {
+			return sort
+		}
+	}
+	out := make([]SortField, 0, len(sort)+1)
+	out = append(out, sort...)
+	return append(out, SortField{Field: "id"})
+}
+
+// SortValue devuelve el valor de un campo ordenable del libro.
+func (b *Book) SortValue(field string) any {
+	switch field {
+	case "id":
+		return int64(b.ID)
+	case "title":
+		return b.Title
+	case "author":
+		return b.Author
+	case "year":
+		return int64(b.Year)
+	case "genre":
+		return b.Genre
+	case "isbn":
+		return b.ISBN
+	case "created_at":
+		return b.CreatedAt
+	case "updated_at":
+		return b.UpdatedAt
+	}
+	return nil
+}
+
+// sortValueFromJSON restaura el tipo original de un valor leído desde un cursor.
+func sortValueFromJSON(field string, v any) (any, error) {
+	switch field {
+	case RelevanceField:
+		n, ok := v.(float64)
+		if !ok {
+			return nil, invalid("cursor", CodeInvalidFormat, "invalid cursor")
+		}
+		return n, nil
+	case "id", "year":
+		n, ok := v.(float64)
+		if !ok {
+			return nil, invalid("cursor", CodeInvalidFormat, "invalid cursor")
+		}
+		return int64(n), nil
+	case "created_at", "updated_at":
+		s, ok := v.(string)
+		if !ok {
+			return nil, invalid("cursor", CodeInvalidFormat, "invalid cursor")
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, invalid("cursor", CodeInvalidFormat, "invalid cursor")
+		}
+		return t.UTC(), nil
+	default:
+		s, ok := v.(string)
+		if !ok {
+			return nil, invalid("cursor", CodeInvalidFormat, "invalid cursor")
+		}
+		return s, nil
+	}
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "context"
+
+//go:generate mockgen -source=transaction.go -destination=../application/mocks/mock_tx_manager.go -package=mocks
+
+// TxManager ejecuta una unidad de trabajo de forma atómica: si fn devuelve error
+// no queda ningún cambio. Los repositorios usan la transacción que viaja en el ctx
+// que recibe fn, así varias llamadas ven y escriben el mismo estado.
+type TxManager interface {
+	// WithinTx ejecuta fn dentro de una transacción; si ctx ya trae una, fn se une a ella
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,217 @@
+package domain
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+//go:generate mockgen -source=webhook.go -destination=../application/mocks/mock_webhook_repository.go -package=mocks
+
+// Webhook es una suscripción de un sistema externo a los eventos de libros: cada evento
+// que pasa el filtro se le envía por POST, firmado con Secret
+type Webhook struct {
+	ID  uint
+	URL string
+	// Events son los nombres de eventos que recibe; AllEvents los recibe todos
+	Events []string
+	Secret string
+	Active bool
+	// Failures son los intentos fallidos seguidos, de cualquier entrega; al llegar al
+	// límite el webhook se desactiva solo y DisabledAt indica cuándo
+	Failures   int
+	DisabledAt *time.Time
+	// RetryAt es hasta cuándo espera el webhook tras su último fallo: hasta entonces
+	// ninguna de sus entregas sale, así un receptor caído suma un fallo por espera y no
+	// uno por entrega pendiente. nil si la última entrega llegó.
+	RetryAt   *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WebhookInput son los campos de un webhook que se pueden enviar; nil si no vienen
+type WebhookInput struct {
+	URL    *string
+	Events []string
+	Secret *string
+	Active *bool
+}
+
+// MinWebhookSecret es el largo mínimo de un secreto elegido por el cliente
+const MinWebhookSecret = 16
+
+// NewWebhook crea un webhook activo; sin eventos recibe todos
+func NewWebhook(in WebhookInput) *Webhook {
+	now := time.Now().UTC()
+	w := &Webhook{Events: []string{AllEvents}, Active: true, CreatedAt: now, UpdatedAt: now}
+	w.Update(in)
+	return w
+}
+
+// Update aplica los campos que vienen en in. Reactivar un webhook pone en cero sus
+// fallos y su espera.
+func (w *Webhook) Update(in WebhookInput) {
+	if in.URL != nil {
+		w.URL = strings.TrimSpace(*in.URL)
+	}
+	if in.Events != nil {
+		w.Events = normalizeEvents(in.Events)
+	}
+	if in.Secret != nil {
+		w.Secret = *in.Secret
+	}
+	if in.Active != nil {
+		if *in.Active && !w.Active {
+			w.Failures, w.DisabledAt, w.RetryAt = 0, nil, nil
+		}
+		w.Active = *in.Active
+	}
+	w.UpdatedAt = time.Now().UTC()
+}
+
+// Matches indica si el webhook recibe los eventos llamados name
+func (w *Webhook) Matches(name string) bool {
+	return slices.Contains(w.Events, AllEvents) || slices.Contains(w.Events, name)
+}
+
+// Validate revisa la URL, el filtro y el secreto, todos a la vez
+func (w *Webhook) Validate() error {
+	var errs ValidationErrors
+	if w.URL == "" {
+		errs = append(errs, invalid("url", CodeRequired, "url is required"))
+	} else if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, invalid("url", CodeInvalidFormat, "url must be an absolute http or https URL"))
+	}
+	for _, name := range w.Events {
+		if !slices.Contains(webhookEvents, name) {
+			errs = append(errs, invalid("events", CodeInvalidValue, fmt.Sprintf("unknown event %q (use %s)", name, strings.Join(webhookEvents, ", "))))
+		}
+	}
+	if len(w.Secret) < MinWebhookSecret {
+		errs = append(errs, invalid("secret", CodeOutOfRange, fmt.Sprintf("secret must have at least %d characters", MinWebhookSecret)))
+	}
+	return errs.Err()
+}
+
+// webhookEvents son los valores admitidos en Webhook.Events
+var webhookEvents = []string{EventBookCreated, EventBookUpdated, EventBookDeleted, EventBookRestored, AllEvents}
+
+// normalizeEvents quita espacios y repetidos; una lista vacía equivale a todos
+func normalizeEvents(events []string) []string {
+	out := []string{}
+	for _, name := range events {
+		name = strings.TrimSpace(name)
+		if name != "" && !slices.Contains(out, name) {
+			out = append(out, name)
+		}
+	}
+	if len(out) == 0 {
+		return []string{AllEvents}
+	}
+	return out
+}
+
+// Estados de una entrega de webhook
+const (
+	DeliveryPending   = "pending"   // falta entregarla o se va a reintentar
+	DeliveryDelivered = "delivered" // el receptor respondió 2xx
+	DeliveryFailed    = "failed"    // se acabaron los reintentos
+)
+
+// WebhookDelivery es el envío de un evento a un webhook, con el resultado del último intento
+type WebhookDelivery struct {
+	ID        uint
+	WebhookID uint
+	// EventID es el ID del evento: el receptor lo recibe en cada intento y en cada
+	// reentrega, para descartar repetidos
+	EventID   string
+	EventName string
+	Payload   []byte // el cuerpo que se envía, siempre el mismo
+	Status    string
+	Attempts  int
+	// ResponseStatus es el código HTTP del último intento; 0 si no hubo respuesta
+	ResponseStatus int
+	LastError      string
+	NextAttemptAt  time.Time // mientras está pendiente
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+	// RedeliveryOf es la entrega original cuando esta es un reenvío manual; 0 si no
+	RedeliveryOf uint
+}
+
+// WebhookRepository guarda los webhooks y el registro de sus entregas
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *Webhook) error
+	GetByID(ctx context.Context, id uint) (*Webhook, error)
+	List(ctx context.Context) ([]*Webhook, error)
+	// Update guarda todos los campos del webhook
+	Update(ctx context.Context, webhook *Webhook) error
+	// Delete elimina el webhook y sus entregas
+	Delete(ctx context.Context, id uint) error
+
+	// RecordFailure suma un fallo seguido, hace esperar al webhook hasta retryAt y lo
+	// desactiva si llega a disableAfter
+	RecordFailure(ctx context.Context, id uint, disableAfter int, at, retryAt time.Time) error
+	// ResetFailures vuelve a cero los fallos seguidos y la espera tras una entrega correcta
+	ResetFailures(ctx context.Context, id uint) error
+
+	// AddDelivery guarda una entrega nueva; si no es un reenvío y el webhook ya tiene una
+	// original del mismo evento no hace nada
+	AddDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	GetDelivery(ctx context.Context, webhookID, id uint) (*WebhookDelivery, error)
+	// DueDeliveries obtiene hasta limit entregas pendientes con NextAttemptAt <= now de
+	// webhooks activos que no están esperando (RetryAt <= now), por orden de llegada
+	DueDeliveries(ctx context.Context, now time.Time, limit int) ([]*WebhookDelivery, error)
+	// UpdateDelivery guarda el resultado de un intento
+	UpdateDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	// ListDeliveries obtiene hasta limit entregas de un webhook, de la más nueva a la más vieja
+	ListDeliveries(ctx context.Context, webhookID uint, limit int) ([]*WebhookDelivery, error)
+}
+
+// WebhookClient envía el cuerpo de una entrega a url. Devuelve el código HTTP de la
+// respuesta; error solo si no hubo respuesta (conexión, timeout).
+type WebhookClient interface {
+	Post(ctx context.Context, url string, body []byte, headers map[string]string) (int, error)
+}
+
+// Cabeceras de cada entrega de webhook
+const (
+	WebhookSignatureHeader = "X-Signature" // sha256=<HMAC-SHA256 del cuerpo con el secreto, en hex>
+	WebhookEventHeader     = "X-Event"     // nombre del evento
+	WebhookEventIDHeader   = "X-Event-ID"  // ID del evento, para descartar repetidos
+	WebhookDeliveryHeader  = "X-Delivery"  // ID de la entrega en el registro
+)
+
+// SignWebhook firma body con secret para la cabecera X-Signature
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature comprueba en tiempo constante que signature sea la firma de
+// body con secret; es lo que debe hacer el receptor
+func VerifyWebhookSignature(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhook(secret, body)), []byte(signature))
+}
+
+// webhookPayload es el cuerpo de una entrega: el evento con su nombre
+type webhookPayload struct {
+	ID         string    `json:"id"`
+	Event      string    `json:"event"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       Event     `json:"data"`
+}
+
+// NewWebhookPayload arma el cuerpo JSON con que se envía event a los webhooks
+func NewWebhookPayload(event Event) ([]byte, error) {
+	meta := event.Meta()
+	return json.Marshal(webhookPayload{ID: meta.ID, Event: event.EventName(), OccurredAt: meta.OccurredAt, Data: event})
+}
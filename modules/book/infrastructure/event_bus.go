@@ -0,0 +1,138 @@
+package infrastructure
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrEventBusClosed indica que se publicó en un AsyncEventBus ya cerrado
+var ErrEventBusClosed = errors.New("event bus closed")
+
+// SyncEventBus entrega cada evento a sus suscriptores en la goroutine de Publish, en el
+// orden en que se suscribieron. Publish espera a todos y devuelve sus errores juntos;
+// el pánico de un suscriptor se devuelve como error y no corta a los demás.
+type SyncEventBus struct {
+	mu       sync.RWMutex
+	handlers map[string][]domain.EventHandler
+}
+
+func NewSyncEventBus() *SyncEventBus {
+	return &SyncEventBus{handlers: map[string][]domain.EventHandler{}}
+}
+
+// Subscribe registra handler para los eventos llamados name, o para todos con domain.AllEvents
+func (b *SyncEventBus) Subscribe(name string, handler domain.EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+// Publish entrega los eventos en orden
+func (b *SyncEventBus) Publish(ctx context.Context, events ...domain.Event) error {
+	var errs []error
+	for _, event := range events {
+		for _, handler := range b.subscribers(event.EventName()) {
+			if err := deliver(ctx, handler, event); err != nil {
+				errs = append(errs, fmt.Errorf("%s for book %d: %w", event.EventName(), event.Meta().BookID, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// subscribers devuelve los suscriptores del nombre y luego los de todos los eventos
+func (b *SyncEventBus) subscribers(name string) []domain.EventHandler {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	out := append([]domain.EventHandler{}, b.handlers[name]...)
+	return append(out, b.handlers[domain.AllEvents]...)
+}
+
+// deliver llama a handler convirtiendo un pánico en error
+func deliver(ctx context.Context, handler domain.EventHandler, event domain.Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("subscriber panic: %v", r)
+		}
+	}()
+	return handler(ctx, event)
+}
+
+// AsyncEventBus entrega los eventos en segundo plano: Publish los encola y vuelve
+// enseguida, y una goroutine los reparte con un SyncEventBus, de a uno y en orden.
+// Los errores de los suscriptores van a onError. Close espera a que se vacíe la cola.
+// Es el puerto asíncrono de domain.EventBus para suscriptores que no necesitan la
+// garantía del outbox (cachés, métricas): lo que está en la cola se pierde si el
+// proceso se cae. BookService publica por el outbox, que entrega al SyncEventBus.
+type AsyncEventBus struct {
+	bus     *SyncEventBus
+	queue   chan queuedEvent
+	onError func(error)
+	done    chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+type queuedEvent struct {
+	ctx   context.Context
+	event domain.Event
+}
+
+// NewAsyncEventBus crea el bus con una cola de buffer eventos; si la cola está llena,
+// Publish espera. onError puede ser nil.
+func NewAsyncEventBus(buffer int, onError func(error)) *AsyncEventBus {
+	if onError == nil {
+		onError = func(error) {}
+	}
+	b := &AsyncEventBus{
+		bus:     NewSyncEventBus(),
+		queue:   make(chan queuedEvent, buffer),
+		onError: onError,
+		done:    make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// Subscribe registra handler para los eventos llamados name, o para todos con domain.AllEvents
+func (b *AsyncEventBus) Subscribe(name string, handler domain.EventHandler) {
+	b.bus.Subscribe(name, handler)
+}
+
+// Publish encola los eventos. La entrega no se corta si ctx se cancela (la petición
+// que los originó puede terminar antes), pero conserva sus valores.
+func (b *AsyncEventBus) Publish(ctx context.Context, events ...domain.Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrEventBusClosed
+	}
+	for _, event := range events {
+		b.queue <- queuedEvent{ctx: context.WithoutCancel(ctx), event: event}
+	}
+	return nil
+}
+
+// Close deja de aceptar eventos y espera a entregar los encolados
+func (b *AsyncEventBus) Close() {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.queue)
+	}
+	b.mu.Unlock()
+	<-b.done
+}
+
+func (b *AsyncEventBus) run() {
+	defer close(b.done)
+	for q := range b.queue {
+		if err := b.bus.Publish(q.ctx, q.event); err != nil {
+			b.onError(err)
+		}
+	}
+}
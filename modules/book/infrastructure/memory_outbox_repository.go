@@ -0,0 +1,142 @@
+package infrastructure
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MemoryOutboxRepository guarda el outbox en memoria, para --storage=memory y tests.
+// Igual que MemoryRevisionRepository participa de MemoryBookRepository.WithinTx: los
+// mensajes de una unidad de trabajo se guardan al confirmarla y se descartan si falla.
+type MemoryOutboxRepository struct {
+	mu       sync.Mutex
+	messages []*domain.OutboxMessage // por orden de llegada
+	keys     map[string]bool
+}
+
+func NewMemoryOutboxRepository() *MemoryOutboxRepository {
+	return &MemoryOutboxRepository{keys: map[string]bool{}}
+}
+
+// Add guarda msg; con un DedupeKey repetido no hace nada y deja msg.ID en 0. Dentro
+// de una unidad de trabajo msg queda pendiente y su ID se asigna al confirmarla.
+func (r *MemoryOutboxRepository) Add(ctx context.Context, msg *domain.OutboxMessage) error {
+	if tx := memoryTxFrom(ctx); tx != nil {
+		staged := tx.stage(r, func() any { return &[]*domain.OutboxMessage{} }, func(staged any) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			for _, msg := range *staged.(*[]*domain.OutboxMessage) {
+				r.add(msg)
+			}
+		}).(*[]*domain.OutboxMessage)
+		*staged = append(*staged, copyOutboxMessage(msg))
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.add(msg)
+	return nil
+}
+
+// add guarda una copia de msg si su DedupeKey es nuevo; r.mu debe estar tomado
+func (r *MemoryOutboxRepository) add(msg *domain.OutboxMessage) {
+	if r.keys[msg.DedupeKey] {
+		return
+	}
+	r.keys[msg.DedupeKey] = true
+	msg.ID = uint(len(r.messages)) + 1
+	r.messages = append(r.messages, copyOutboxMessage(msg))
+}
+
+// Due obtiene hasta limit mensajes pendientes con AvailableAt <= now, por orden de llegada
+func (r *MemoryOutboxRepository) Due(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := []*domain.OutboxMessage{}
+	for _, msg := range r.messages {
+		if len(out) == limit {
+			break
+		}
+		if outboxPending(msg) && !msg.AvailableAt.After(now) {
+			out = append(out, copyOutboxMessage(msg))
+		}
+	}
+	return out, nil
+}
+
+// MarkPublished marca un mensaje como entregado
+func (r *MemoryOutboxRepository) MarkPublished(ctx context.Context, id uint, at time.Time) error {
+	return r.update(id, func(msg *domain.OutboxMessage) {
+		at := at.UTC()
+		msg.PublishedAt = &at
+	})
+}
+
+// MarkRetry registra un intento fallido y deja el mensaje para retryAt
+func (r *MemoryOutboxRepository) MarkRetry(ctx context.Context, id uint, reason string, retryAt time.Time) error {
+	return r.update(id, func(msg *domain.OutboxMessage) {
+		msg.Attempts++
+		msg.LastError, msg.AvailableAt = reason, retryAt.UTC()
+	})
+}
+
+// MarkFailed registra el último intento fallido: el mensaje no se reintenta más
+func (r *MemoryOutboxRepository) MarkFailed(ctx context.Context, id uint, reason string, at time.Time) error {
+	return r.update(id, func(msg *domain.OutboxMessage) {
+		at := at.UTC()
+		msg.Attempts++
+		msg.LastError, msg.FailedAt = reason, &at
+	})
+}
+
+// Backlog cuenta los pendientes y fallidos y devuelve hasta limit de ellos
+func (r *MemoryOutboxRepository) Backlog(ctx context.Context, limit int) (*domain.OutboxBacklog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	backlog := &domain.OutboxBacklog{Messages: []*domain.OutboxMessage{}}
+	for _, msg := range r.messages {
+		switch {
+		case msg.PublishedAt != nil:
+			continue
+		case msg.FailedAt != nil:
+			backlog.Failed++
+		default:
+			backlog.Pending++
+			if backlog.OldestPendingAt == nil {
+				created := msg.CreatedAt
+				backlog.OldestPendingAt = &created
+			}
+		}
+		if len(backlog.Messages) < limit {
+			backlog.Messages = append(backlog.Messages, copyOutboxMessage(msg))
+		}
+	}
+	return backlog, nil
+}
+
+func (r *MemoryOutboxRepository) update(id uint, change func(*domain.OutboxMessage)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id == 0 || int(id) > len(r.messages) {
+		return fmt.Errorf("outbox message %d %w", id, domain.ErrNotFound)
+	}
+	change(r.messages[id-1])
+	return nil
+}
+
+func outboxPending(msg *domain.OutboxMessage) bool {
+	return msg.PublishedAt == nil && msg.FailedAt == nil
+}
+
+func copyOutboxMessage(msg *domain.OutboxMessage) *domain.OutboxMessage {
+	c := *msg
+	c.Payload = append([]byte(nil), msg.Payload...)
+	return &c
+}
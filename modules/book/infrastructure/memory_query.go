@@ -0,0 +1,242 @@
+package infrastructure
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Búsqueda de texto para MemoryBookRepository. Imita al índice FTS5 de SqlBookRepository:
+// las palabras se comparan plegadas (sin tildes ni mayúsculas), el texto libre coincide
+// por prefijo de palabra y las frases por palabras completas y consecutivas.
+
+// textToken es una palabra de un campo, con su posición en el texto original
+type textToken struct {
+	folded     string
+	start, end int // bytes en el texto original
+}
+
+// tokenize separa un texto en palabras como el tokenizer unicode61 de FTS5
+func tokenize(s string) []textToken {
+	out := []textToken{}
+	start := -1
+	for i, r := range s {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+		if isWord && start < 0 {
+			start = i
+		}
+		if !isWord && start >= 0 {
+			out = append(out, textToken{folded: domain.FoldText(s[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		out = append(out, textToken{folded: domain.FoldText(s[start:]), start: start, end: len(s)})
+	}
+	return out
+}
+
+// textColumns son los campos indexados para texto libre, en el orden del índice FTS5
+var textColumns = []string{"title", "author", "genre"}
+
+// memoryRecord es un libro guardado junto con sus datos derivados para búsqueda
+type memoryRecord struct {
+	book   domain.Book
+	norm   map[string]string      // campo -> valor plegado
+	tokens map[string][]textToken // campo -> palabras
+}
+
+func newMemoryRecord(b domain.Book) *memoryRecord {
+	rec := &memoryRecord{book: b, norm: map[string]string{}, tokens: map[string][]textToken{}}
+	for _, field := range textColumns {
+		v := textValue(&b, field)
+		rec.norm[field] = domain.FoldText(v)
+		rec.tokens[field] = tokenize(v)
+	}
+	return rec
+}
+
+func textValue(b *domain.Book, field string) string {
+	switch field {
+	case "title":
+		return b.Title
+	case "author":
+		return b.Author
+	case "genre":
+		return b.Genre
+	}
+	return ""
+}
+
+// span es un tramo de palabras [from, to] de un campo que coincide con un término
+type span struct {
+	field    string
+	from, to int
+}
+
+// textMatches devuelve los tramos en que un término de texto libre coincide con el libro,
+// o nil si no coincide. Sin frase, cada palabra del término debe aparecer (por prefijo)
+// en algún campo; con frase, todas seguidas en un mismo campo.
+func (rec *memoryRecord) textMatches(t domain.TextTerm, fields []string) []span {
+	terms := domain.FullTextTerms(domain.FoldText(t.Text))
+	if len(terms) == 0 {
+		return nil
+	}
+	spans := []span{}
+	if t.Phrase {
+		for _, field := range fields {
+			tokens := rec.tokens[field]
+			for i := 0; i+len(terms) <= len(tokens); i++ {
+				ok := true
+				for j, term := range terms {
+					if tokens[i+j].folded != term {
+						ok = false
+						break
+					}
+				}
+				if ok {
+					spans = append(spans, span{field: field, from: i, to: i + len(terms) - 1})
+				}
+			}
+		}
+		if len(spans) == 0 {
+			return nil
+		}
+		return spans
+	}
+	for _, term := range terms {
+		found := false
+		for _, field := range fields {
+			for i, tok := range rec.tokens[field] {
+				if strings.HasPrefix(tok.folded, term) {
+					spans = append(spans, span{field: field, from: i, to: i})
+					found = true
+				}
+			}
+		}
+		if !found {
+			return nil
+		}
+	}
+	return spans
+}
+
+// matchQuery evalúa el árbol de la consulta avanzada contra un libro, con la misma
+// semántica que compileQuery
+func (rec *memoryRecord) matchQuery(node domain.QueryNode) bool {
+	switch n := node.(type) {
+	case nil:
+		return true
+	case domain.AndNode:
+		for _, c := range n.Children {
+			if !rec.matchQuery(c) {
+				return false
+			}
+		}
+		return true
+	case domain.OrNode:
+		for _, c := range n.Children {
+			if rec.matchQuery(c) {
+				return true
+			}
+		}
+		return false
+	case domain.NotNode:
+		return !rec.matchQuery(n.Child)
+	case domain.FieldTerm:
+		return rec.matchFieldTerm(n)
+	case domain.TextTerm:
+		if len(domain.FullTextTerms(n.Text)) == 0 {
+			return true // sin palabras buscables, igual que "1 = 1"
+		}
+		return rec.textMatches(n, textColumns) != nil
+	}
+	return true
+}
+
+func (rec *memoryRecord) matchFieldTerm(t domain.FieldTerm) bool {
+	switch t.Field {
+	case "title", "author", "genre":
+		if t.Op == "=" {
+			return rec.norm[t.Field] == domain.FoldText(t.Value)
+		}
+		return strings.Contains(rec.norm[t.Field], domain.FoldText(t.Value))
+	case "isbn":
+		isbn := domain.NormalizeISBN(t.Value)
+		if t.Op == "=" {
+			return rec.book.ISBN == isbn
+		}
+		return strings.HasPrefix(rec.book.ISBN, isbn)
+	case "year":
+		year, _ := strconv.Atoi(t.Value)
+		y := int(rec.book.Year)
+		switch t.Op {
+		case ">":
+			return y > year
+		case ">=":
+			return y >= year
+		case "<":
+			return y < year
+		case "<=":
+			return y <= year
+		}
+		return y == year
+	}
+	return true
+}
+
+// rank calcula relevancia y resaltados para los términos positivos de la consulta.
+// Como bm25, menor es más relevante y 0 significa sin coincidencia de texto; la escala
+// es más simple: menos la cantidad de palabras que coinciden.
+func (rec *memoryRecord) rank(terms []domain.TextTerm) (domain.BookMatch, bool) {
+	spans := []span{}
+	for _, t := range terms {
+		spans = append(spans, rec.textMatches(t, textColumns)...)
+	}
+	if len(spans) == 0 {
+		return domain.BookMatch{}, false
+	}
+	highlights := map[string]string{}
+	score := 0.0
+	for _, field := range textColumns {
+		fieldSpans := []span{}
+		for _, s := range spans {
+			if s.field == field {
+				fieldSpans = append(fieldSpans, s)
+				score -= float64(s.to - s.from + 1)
+			}
+		}
+		if len(fieldSpans) > 0 {
+			highlights[field] = highlight(textValue(&rec.book, field), rec.tokens[field], fieldSpans)
+		}
+	}
+	return domain.BookMatch{Score: score, Highlights: highlights}, true
+}
+
+// highlight marca los tramos de palabras en el texto original; los tramos que se
+// superponen se marcan una sola vez
+func highlight(text string, tokens []textToken, spans []span) string {
+	sort.Slice(spans, func(i, j int) bool { return spans[i].from < spans[j].from })
+	merged := []span{}
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s.from <= merged[n-1].to {
+			if s.to > merged[n-1].to {
+				merged[n-1].to = s.to
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	var b strings.Builder
+	last := 0
+	for _, s := range merged {
+		start, end := tokens[s.from].start, tokens[s.to].end
+		b.WriteString(text[last:start])
+		b.WriteString(highlightOpen + text[start:end] + highlightClose)
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
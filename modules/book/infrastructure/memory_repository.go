@@ -0,0 +1,651 @@
+package infrastructure
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryBookRepository guarda los libros en memoria. Implementa domain.BookRepository
+// con las mismas reglas que SqlBookRepository (ISBN único, filtros, orden y paginación),
+// así sirve para demos y tests sin base de datos. Es seguro para uso concurrente.
+// También es su propio domain.TxManager (ver WithinTx).
+type MemoryBookRepository struct {
+	mu    sync.RWMutex
+	state *memoryState // lo confirmado: lo que leen los que no están en una transacción
+
+	// writer serializa las escrituras, como el único escritor de SQLite: las unidades de
+	// trabajo de WithinTx y las escrituras sueltas no se intercalan
+	writer sync.Mutex
+}
+
+// memoryState son los libros de un MemoryBookRepository
+type memoryState struct {
+	books  map[uint]*memoryRecord
+	nextID uint
+
+	// trash guarda los libros borrados (con DeletedAt) fuera de books, así ninguna
+	// lectura ni la unicidad del ISBN los ve
+	trash map[uint]*memoryRecord
+}
+
+// clone copia el estado; los registros no se modifican, se reemplazan: basta copiar los mapas
+func (s *memoryState) clone() *memoryState {
+	c := &memoryState{books: make(map[uint]*memoryRecord, len(s.books)), nextID: s.nextID,
+		trash: make(map[uint]*memoryRecord, len(s.trash))}
+	for id, rec := range s.books {
+		c.books[id] = rec
+	}
+	for id, rec := range s.trash {
+		c.trash[id] = rec
+	}
+	return c
+}
+
+// memoryTx es una unidad de trabajo de MemoryBookRepository.WithinTx. Escribe sobre su
+// propia copia de los libros, que reemplaza a la confirmada solo si fn termina bien.
+// Los otros repositorios en memoria dejan sus escrituras en onCommit.
+type memoryTx struct {
+	repo     *MemoryBookRepository
+	mu       sync.RWMutex
+	state    *memoryState
+	staged   map[any]any // lo que cada repositorio dejó pendiente, por repositorio
+	onCommit []func()
+}
+
+type memoryTxKey struct{}
+
+// memoryTxFrom devuelve la unidad de trabajo en memoria de ctx, o nil
+func memoryTxFrom(ctx context.Context) *memoryTx {
+	tx, _ := ctx.Value(memoryTxKey{}).(*memoryTx)
+	return tx
+}
+
+// stage devuelve lo que owner dejó pendiente en la unidad de trabajo; la primera vez lo
+// crea con init y registra commit, que lo aplica al confirmar
+func (tx *memoryTx) stage(owner any, init func() any, commit func(staged any)) any {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if staged, ok := tx.staged[owner]; ok {
+		return staged
+	}
+	staged := init()
+	tx.staged[owner] = staged
+	tx.onCommit = append(tx.onCommit, func() { commit(staged) })
+	return staged
+}
+
+// WithinTx ejecuta fn como una unidad de trabajo: las de distintas goroutines no se
+// intercalan, nadie fuera de fn ve sus cambios hasta que termina bien y, si falla, se
+// descartan. Si ctx ya viene de un WithinTx, fn se une a esa unidad.
+func (r *MemoryBookRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if tx := memoryTxFrom(ctx); tx != nil && tx.repo == r {
+		return fn(ctx)
+	}
+	r.writer.Lock()
+	defer r.writer.Unlock()
+
+	r.mu.RLock()
+	tx := &memoryTx{repo: r, state: r.state.clone(), staged: map[any]any{}}
+	r.mu.RUnlock()
+
+	if err := fn(context.WithValue(ctx, memoryTxKey{}, tx)); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.state = tx.state
+	r.mu.Unlock()
+	for _, commit := range tx.onCommit {
+		commit()
+	}
+	return nil
+}
+
+// read devuelve los libros que ve ctx (los de su unidad de trabajo o los confirmados)
+// y la función que libera el bloqueo de lectura
+func (r *MemoryBookRepository) read(ctx context.Context) (*memoryState, func()) {
+	if tx := memoryTxFrom(ctx); tx != nil && tx.repo == r {
+		tx.mu.RLock()
+		return tx.state, tx.mu.RUnlock
+	}
+	r.mu.RLock()
+	return r.state, r.mu.RUnlock
+}
+
+// write ejecuta change sobre los libros que ve ctx. Fuera de una unidad de trabajo espera
+// a la que esté en curso y escribe sobre lo confirmado, así un commit no la pisa.
+func (r *MemoryBookRepository) write(ctx context.Context, change func(s *memoryState) error) error {
+	if tx := memoryTxFrom(ctx); tx != nil && tx.repo == r {
+		tx.mu.Lock()
+		defer tx.mu.Unlock()
+		return change(tx.state)
+	}
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return change(r.state)
+}
+
+func NewMemoryBookRepository() *MemoryBookRepository {
+	return &MemoryBookRepository{state: &memoryState{books: map[uint]*memoryRecord{}, trash: map[uint]*memoryRecord{}, nextID: 1}}
+}
+
+// Create crea un nuevo libro en el repositorio
+func (r *MemoryBookRepository) Create(ctx context.Context, book *domain.Book) error {
+	return r.write(ctx, func(s *memoryState) error {
+		stored := normalizedBook(*book)
+		if s.findByISBN(stored.ISBN) != nil {
+			return fmt.Errorf("%w: %s", domain.ErrDuplicateISBN, stored.ISBN)
+		}
+		// Como AUTOINCREMENT: los IDs no se reutilizan aunque se borren libros
+		stored.ID = s.nextID
+		stored.Version = 1
+		s.nextID++
+		s.books[stored.ID] = newMemoryRecord(stored)
+		book.ID = stored.ID
+		book.Version = stored.Version
+		return nil
+	})
+}
+
+// Update actualiza un libro existente en el repositorio si sigue en book.Version,
+// y sube la versión. Si otro lo cambió antes devuelve domain.ErrVersionMismatch.
+func (r *MemoryBookRepository) Update(ctx context.Context, book *domain.Book) (*domain.Book, error) {
+	var out domain.Book
+	err := r.write(ctx, func(s *memoryState) error {
+		current, ok := s.books[book.ID]
+		if !ok {
+			return fmt.Errorf("book %d %w", book.ID, domain.ErrNotFound)
+		}
+		if current.book.Version != book.Version {
+			return versionMismatch(book, current.book.Version)
+		}
+		stored := normalizedBook(*book)
+		if other := s.findByISBN(stored.ISBN); other != nil && other.book.ID != book.ID {
+			return fmt.Errorf("%w: %s", domain.ErrDuplicateISBN, stored.ISBN)
+		}
+		stored.CreatedAt = current.book.CreatedAt
+		stored.UpdatedAt = time.Now().UTC()
+		stored.Version = current.book.Version + 1
+		s.books[stored.ID] = newMemoryRecord(stored)
+		out = stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Delete mueve un libro a la papelera: marca DeletedAt y sube la versión
+func (r *MemoryBookRepository) Delete(ctx context.Context, id uint) error {
+	return r.write(ctx, func(s *memoryState) error {
+		rec, ok := s.books[id]
+		if !ok {
+			return fmt.Errorf("book %d %w", id, domain.ErrNotFound)
+		}
+		stored := rec.book
+		now := time.Now().UTC()
+		stored.DeletedAt = &now
+		stored.Version++
+		delete(s.books, id)
+		s.trash[id] = newMemoryRecord(stored)
+		return nil
+	})
+}
+
+// ListDeleted obtiene los libros de la papelera, del borrado más reciente al más viejo
+func (r *MemoryBookRepository) ListDeleted(ctx context.Context) ([]*domain.Book, error) {
+	s, unlock := r.read(ctx)
+	defer unlock()
+
+	out := make([]*domain.Book, 0, len(s.trash))
+	for _, rec := range s.trash {
+		b := rec.book
+		out = append(out, &b)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].DeletedAt.Equal(*out[j].DeletedAt) {
+			return out[i].DeletedAt.After(*out[j].DeletedAt)
+		}
+		return out[i].ID > out[j].ID
+	})
+	return out, nil
+}
+
+// Restore saca un libro de la papelera y sube su versión; falla con restoreConflict
+// si otro libro activo tomó su ISBN
+func (r *MemoryBookRepository) Restore(ctx context.Context, id uint) (*domain.Book, error) {
+	var out domain.Book
+	err := r.write(ctx, func(s *memoryState) error {
+		rec, ok := s.trash[id]
+		if !ok {
+			return fmt.Errorf("book %d %w in trash", id, domain.ErrNotFound)
+		}
+		if s.findByISBN(rec.book.ISBN) != nil {
+			return restoreConflict(id)
+		}
+		stored := rec.book
+		stored.DeletedAt = nil
+		stored.UpdatedAt = time.Now().UTC()
+		stored.Version++
+		delete(s.trash, id)
+		s.books[id] = newMemoryRecord(stored)
+		out = stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Purge elimina definitivamente los libros que están en la papelera desde antes de deletedBefore
+func (r *MemoryBookRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	n := 0
+	err := r.write(ctx, func(s *memoryState) error {
+		for id, rec := range s.trash {
+			if rec.book.DeletedAt.Before(deletedBefore) {
+				delete(s.trash, id)
+				n++
+			}
+		}
+		return nil
+	})
+	return n, err
+}
+
+// GetAll obtiene todos los libros del repositorio
+func (r *MemoryBookRepository) GetAll(ctx context.Context) ([]*domain.Book, error) {
+	s, unlock := r.read(ctx)
+	defer unlock()
+
+	out := []*domain.Book{}
+	for _, rec := range s.sorted([]domain.SortField{{Field: "id"}}, nil) {
+		b := rec.book
+		out = append(out, &b)
+	}
+	return out, nil
+}
+
+// GetByISBN obtiene un libro por ISBN del repositorio
+func (r *MemoryBookRepository) GetByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
+	s, unlock := r.read(ctx)
+	defer unlock()
+
+	rec := s.findByISBN(domain.NormalizeISBN(isbn))
+	if rec == nil {
+		return nil, fmt.Errorf("book with isbn %s %w", isbn, domain.ErrNotFound)
+	}
+	b := rec.book
+	return &b, nil
+}
+
+// GetByID obtiene un libro por ID
+func (r *MemoryBookRepository) GetByID(ctx context.Context, id uint) (*domain.Book, error) {
+	s, unlock := r.read(ctx)
+	defer unlock()
+
+	rec, ok := s.books[id]
+	if !ok {
+		return nil, fmt.Errorf("book %d %w", id, domain.ErrNotFound)
+	}
+	b := rec.book
+	return &b, nil
+}
+
+// FindByFilter obtiene una página de libros por filtros, con el mismo orden,
+// relevancia y paginación (offset o cursor) que SqlBookRepository
+func (r *MemoryBookRepository) FindByFilter(ctx context.Context, filter domain.BookFilter) (*domain.BookPage, error) {
+	query, err := domain.ParseQuery(filter.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	s, unlock := r.read(ctx)
+	defer unlock()
+
+	matched, matches := s.filter(filter, query)
+	sort := filter.EffectiveSort()
+	keys := domain.StableSort(sort)
+	records := sortRecords(matched, keys, matches)
+	total := len(records)
+
+	// Keyset: continuar después del último libro entregado
+	if filter.Cursor != "" {
+		cur, err := domain.DecodeCursor(filter.Cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+		after := records[:0:0]
+		for _, rec := range records {
+			if compareKeys(sortValues(rec, keys, matches), cur.Values, keys) > 0 {
+				after = append(after, rec)
+			}
+		}
+		records = after
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = domain.DefaultPageLimit
+	}
+	if filter.Offset >= len(records) {
+		records = nil
+	} else {
+		records = records[filter.Offset:]
+	}
+	// Uno más que el límite, para saber si hay otra página
+	if len(records) > limit+1 {
+		records = records[:limit+1]
+	}
+
+	books := make([]*domain.Book, len(records))
+	for i, rec := range records {
+		b := rec.book
+		books[i] = &b
+	}
+	var pageMatches map[uint]domain.BookMatch
+	if len(domain.RankingTerms(query)) > 0 {
+		pageMatches = map[uint]domain.BookMatch{}
+		for _, b := range books {
+			m, ok := matches[b.ID]
+			if !ok {
+				m = domain.BookMatch{Highlights: map[string]string{}}
+			}
+			pageMatches[b.ID] = m
+		}
+	}
+	return newBookPage(books, pageMatches, total, limit, sort), nil
+}
+
+// CountFacets cuenta los libros que cumplen el filtro por género, década y autor
+func (r *MemoryBookRepository) CountFacets(ctx context.Context, filter domain.BookFilter) (*domain.BookFacets, error) {
+	query, err := domain.ParseQuery(filter.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	s, unlock := r.read(ctx)
+	defer unlock()
+
+	matched, _ := s.filter(filter, query)
+	facets := domain.NewBookFacets()
+	genres := newValueCounter()
+	authors := newValueCounter()
+	for _, rec := range matched {
+		genres.add(rec.norm["genre"], rec.book.Genre)
+		authors.add(rec.norm["author"], rec.book.Author)
+		facets.Decade[strconv.Itoa(int(domain.Decade(rec.book.Year)))]++
+	}
+	for _, vc := range genres.top(domain.MaxFacetValues) {
+		facets.Genre[vc.Value] = vc.Count
+	}
+	for _, vc := range authors.top(domain.MaxFacetValues) {
+		facets.Author[vc.Value] = vc.Count
+	}
+	return facets, nil
+}
+
+// SuggestByPrefix obtiene los valores de title o author que empiezan por el prefijo,
+// o que tienen palabras que empiezan por él, por frecuencia
+func (r *MemoryBookRepository) SuggestByPrefix(ctx context.Context, field, prefix string, limit int) ([]domain.ValueCount, error) {
+	if err := domain.ValidateAutocompleteField(field); err != nil {
+		return nil, err
+	}
+	folded := domain.FoldText(prefix)
+	if len(domain.FullTextTerms(folded)) == 0 {
+		return []domain.ValueCount{}, nil
+	}
+
+	s, unlock := r.read(ctx)
+	defer unlock()
+
+	counter := newValueCounter()
+	words := domain.TextTerm{Text: folded}
+	for _, rec := range s.books {
+		if strings.HasPrefix(rec.norm[field], folded) || rec.textMatches(words, []string{field}) != nil {
+			counter.add(rec.norm[field], textValue(&rec.book, field))
+		}
+	}
+	return counter.top(limit), nil
+}
+
+// SuggestionCandidates obtiene los valores de title o author con alguna palabra que
+// empieza por uno de los prefijos, por frecuencia
+func (r *MemoryBookRepository) SuggestionCandidates(ctx context.Context, field string, prefixes []string, limit int) ([]string, error) {
+	if err := domain.ValidateAutocompleteField(field); err != nil {
+		return nil, err
+	}
+
+	s, unlock := r.read(ctx)
+	defer unlock()
+
+	counter := newValueCounter()
+	for _, rec := range s.books {
+		if hasWordPrefix(rec.norm[field], prefixes) {
+			counter.add(rec.norm[field], textValue(&rec.book, field))
+		}
+	}
+	out := []string{}
+	for _, vc := range counter.top(limit) {
+		out = append(out, vc.Value)
+	}
+	return out, nil
+}
+
+// hasWordPrefix indica si alguna palabra de folded empieza por alguno de los prefijos
+func hasWordPrefix(folded string, prefixes []string) bool {
+	for _, word := range domain.FullTextTerms(folded) {
+		for _, p := range prefixes {
+			if strings.HasPrefix(word, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Helpers
+
+// normalizedBook aplica las mismas normalizaciones que SqlBookRepository al guardar
+func normalizedBook(b domain.Book) domain.Book {
+	b.Title = strings.TrimSpace(b.Title)
+	b.Author = strings.TrimSpace(b.Author)
+	b.Genre = strings.TrimSpace(b.Genre)
+	b.ISBN = domain.NormalizeISBN(b.ISBN)
+	b.CreatedAt = b.CreatedAt.UTC()
+	b.UpdatedAt = b.UpdatedAt.UTC()
+	return b
+}
+
+func (s *memoryState) findByISBN(isbn string) *memoryRecord {
+	for _, rec := range s.books {
+		if rec.book.ISBN == isbn {
+			return rec
+		}
+	}
+	return nil
+}
+
+// filter devuelve los libros que cumplen el filtro y, si la consulta tiene
+// texto libre, la relevancia y los resaltados de los que coinciden con él
+func (s *memoryState) filter(filter domain.BookFilter, query domain.QueryNode) ([]*memoryRecord, map[uint]domain.BookMatch) {
+	ranking := domain.RankingTerms(query)
+	out := []*memoryRecord{}
+	matches := map[uint]domain.BookMatch{}
+	for _, rec := range s.books {
+		if !rec.matchFilter(filter) || !rec.matchQuery(query) {
+			continue
+		}
+		out = append(out, rec)
+		if m, ok := rec.rank(ranking); ok {
+			matches[rec.book.ID] = m
+		}
+	}
+	return out, matches
+}
+
+// matchFilter aplica los criterios simples del filtro, como filterClauses
+func (rec *memoryRecord) matchFilter(f domain.BookFilter) bool {
+	b := &rec.book
+	contains := func(field string, p *string) bool {
+		return p == nil || strings.Contains(rec.norm[field], domain.FoldText(*p))
+	}
+	if !contains("title", f.Title) || !contains("author", f.Author) || !contains("genre", f.Genre) {
+		return false
+	}
+	if f.Year != nil && b.Year != *f.Year {
+		return false
+	}
+	if f.YearFrom != nil && b.Year < *f.YearFrom {
+		return false
+	}
+	if f.YearTo != nil && b.Year > *f.YearTo {
+		return false
+	}
+	if len(f.Genres) > 0 {
+		found := false
+		for _, g := range f.Genres {
+			if rec.norm["genre"] == domain.FoldText(g) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.ISBNPrefix != nil && !strings.HasPrefix(b.ISBN, domain.NormalizeISBN(*f.ISBNPrefix)) {
+		return false
+	}
+	if f.CreatedAfter != nil && !b.CreatedAt.After(*f.CreatedAfter) {
+		return false
+	}
+	if f.CreatedBefore != nil && !b.CreatedAt.Before(*f.CreatedBefore) {
+		return false
+	}
+	if f.UpdatedSince != nil && b.UpdatedAt.Before(*f.UpdatedSince) {
+		return false
+	}
+	if f.UpdatedBefore != nil && !b.UpdatedAt.Before(*f.UpdatedBefore) {
+		return false
+	}
+	return true
+}
+
+// sorted devuelve todos los libros en el orden dado
+func (s *memoryState) sorted(keys []domain.SortField, matches map[uint]domain.BookMatch) []*memoryRecord {
+	all := make([]*memoryRecord, 0, len(s.books))
+	for _, rec := range s.books {
+		all = append(all, rec)
+	}
+	return sortRecords(all, keys, matches)
+}
+
+func sortRecords(records []*memoryRecord, keys []domain.SortField, matches map[uint]domain.BookMatch) []*memoryRecord {
+	sort.Slice(records, func(i, j int) bool {
+		return compareKeys(sortValues(records[i], keys, matches), sortValues(records[j], keys, matches), keys) < 0
+	})
+	return records
+}
+
+// sortValues devuelve los valores de orden de un libro; la relevancia sale de matches
+// (0 si no coincidió con el texto libre, como COALESCE(h.score, 0))
+func sortValues(rec *memoryRecord, keys []domain.SortField, matches map[uint]domain.BookMatch) []any {
+	values := make([]any, len(keys))
+	for i, sf := range keys {
+		if sf.Field == domain.RelevanceField {
+			values[i] = matches[rec.book.ID].Score
+		} else {
+			values[i] = rec.book.SortValue(sf.Field)
+		}
+	}
+	return values
+}
+
+// compareKeys compara dos tuplas de valores según el orden (negativo: a va antes)
+func compareKeys(a, b []any, keys []domain.SortField) int {
+	for i, sf := range keys {
+		c := compareValues(a[i], b[i])
+		if sf.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareValues(a, b any) int {
+	switch x := a.(type) {
+	case int64:
+		y := b.(int64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	case float64:
+		y := b.(float64)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	case string:
+		return strings.Compare(x, b.(string))
+	case time.Time:
+		return x.Compare(b.(time.Time))
+	}
+	return 0
+}
+
+// valueCounter agrupa valores por su forma plegada, como GROUP BY *_norm con MIN(valor)
+type valueCounter struct {
+	counts map[string]*domain.ValueCount
+}
+
+func newValueCounter() *valueCounter {
+	return &valueCounter{counts: map[string]*domain.ValueCount{}}
+}
+
+func (c *valueCounter) add(key, value string) {
+	vc, ok := c.counts[key]
+	if !ok {
+		c.counts[key] = &domain.ValueCount{Value: value, Count: 1}
+		return
+	}
+	vc.Count++
+	if value < vc.Value {
+		vc.Value = value
+	}
+}
+
+// top devuelve los más frecuentes primero y, a igual cantidad, por valor
+func (c *valueCounter) top(limit int) []domain.ValueCount {
+	out := make([]domain.ValueCount, 0, len(c.counts))
+	for _, vc := range c.counts {
+		out = append(out, *vc)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Value < out[j].Value
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
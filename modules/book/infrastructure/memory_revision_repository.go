@@ -0,0 +1,93 @@
+package infrastructure
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"sync"
+)
+
+// MemoryRevisionRepository guarda el historial en memoria, para --storage=memory y tests.
+// Participa de MemoryBookRepository.WithinTx: las revisiones de una unidad de trabajo
+// se guardan al confirmarla y se descartan si falla.
+type MemoryRevisionRepository struct {
+	mu     sync.RWMutex
+	byBook map[uint][]domain.Revision
+	nextID uint
+}
+
+func NewMemoryRevisionRepository() *MemoryRevisionRepository {
+	return &MemoryRevisionRepository{byBook: map[uint][]domain.Revision{}, nextID: 1}
+}
+
+// Append guarda rev con el siguiente número de su libro. Dentro de una unidad de trabajo
+// rev recibe su número enseguida y su ID al confirmarla.
+func (r *MemoryRevisionRepository) Append(ctx context.Context, rev *domain.Revision) error {
+	stored := *rev
+	stored.Changes = append([]domain.FieldChange(nil), rev.Changes...)
+
+	if tx := memoryTxFrom(ctx); tx != nil {
+		staged := r.staged(tx)
+		r.mu.RLock()
+		rev.Number = uint(len(r.byBook[rev.BookID])+len(stagedFor(*staged, rev.BookID))) + 1
+		r.mu.RUnlock()
+		*staged = append(*staged, stored)
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.append(&stored)
+	rev.ID, rev.Number = stored.ID, stored.Number
+	return nil
+}
+
+// staged devuelve las revisiones que tx guarda al confirmarse, en orden
+func (r *MemoryRevisionRepository) staged(tx *memoryTx) *[]domain.Revision {
+	return tx.stage(r, func() any { return &[]domain.Revision{} }, func(staged any) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, rev := range *staged.(*[]domain.Revision) {
+			r.append(&rev)
+		}
+	}).(*[]domain.Revision)
+}
+
+// stagedFor filtra las revisiones pendientes de un libro
+func stagedFor(staged []domain.Revision, bookID uint) []domain.Revision {
+	out := []domain.Revision{}
+	for _, rev := range staged {
+		if rev.BookID == bookID {
+			out = append(out, rev)
+		}
+	}
+	return out
+}
+
+// append numera y guarda rev; r.mu debe estar tomado
+func (r *MemoryRevisionRepository) append(rev *domain.Revision) {
+	rev.ID = r.nextID
+	rev.Number = uint(len(r.byBook[rev.BookID])) + 1
+	r.nextID++
+	r.byBook[rev.BookID] = append(r.byBook[rev.BookID], *rev)
+}
+
+// ListByBook obtiene las revisiones de un libro, de la más vieja a la más nueva. Dentro
+// de una unidad de trabajo incluye las que esta todavía no confirmó.
+func (r *MemoryRevisionRepository) ListByBook(ctx context.Context, bookID uint) ([]*domain.Revision, error) {
+	r.mu.RLock()
+	revs := append([]domain.Revision(nil), r.byBook[bookID]...)
+	r.mu.RUnlock()
+	if tx := memoryTxFrom(ctx); tx != nil {
+		for _, rev := range stagedFor(*r.staged(tx), bookID) {
+			rev.Number = uint(len(revs)) + 1
+			revs = append(revs, rev)
+		}
+	}
+
+	out := make([]*domain.Revision, len(revs))
+	for i, rev := range revs {
+		rev.Changes = append([]domain.FieldChange(nil), rev.Changes...)
+		out[i] = &rev
+	}
+	return out, nil
+}
@@ -0,0 +1,205 @@
+package infrastructure
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryWebhookRepository guarda los webhooks y sus entregas en memoria, para
+// --storage=memory y tests
+type MemoryWebhookRepository struct {
+	mu             sync.Mutex
+	webhooks       map[uint]*domain.Webhook
+	deliveries     []*domain.WebhookDelivery // por orden de llegada
+	nextWebhookID  uint
+	nextDeliveryID uint
+}
+
+func NewMemoryWebhookRepository() *MemoryWebhookRepository {
+	return &MemoryWebhookRepository{webhooks: map[uint]*domain.Webhook{}, nextWebhookID: 1, nextDeliveryID: 1}
+}
+
+func (r *MemoryWebhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhook.ID = r.nextWebhookID
+	r.nextWebhookID++
+	r.webhooks[webhook.ID] = copyWebhook(webhook)
+	return nil
+}
+
+func (r *MemoryWebhookRepository) GetByID(ctx context.Context, id uint) (*domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return nil, fmt.Errorf("webhook %d %w", id, domain.ErrNotFound)
+	}
+	return copyWebhook(webhook), nil
+}
+
+// List obtiene todos los webhooks, por orden de alta
+func (r *MemoryWebhookRepository) List(ctx context.Context) ([]*domain.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]*domain.Webhook, 0, len(r.webhooks))
+	for _, webhook := range r.webhooks {
+		out = append(out, copyWebhook(webhook))
+	}
+	slices.SortFunc(out, func(a, b *domain.Webhook) int { return int(a.ID) - int(b.ID) })
+	return out, nil
+}
+
+func (r *MemoryWebhookRepository) Update(ctx context.Context, webhook *domain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[webhook.ID]; !ok {
+		return fmt.Errorf("webhook %d %w", webhook.ID, domain.ErrNotFound)
+	}
+	r.webhooks[webhook.ID] = copyWebhook(webhook)
+	return nil
+}
+
+// Delete elimina el webhook y sus entregas
+func (r *MemoryWebhookRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[id]; !ok {
+		return fmt.Errorf("webhook %d %w", id, domain.ErrNotFound)
+	}
+	delete(r.webhooks, id)
+	r.deliveries = slices.DeleteFunc(r.deliveries, func(d *domain.WebhookDelivery) bool { return d.WebhookID == id })
+	return nil
+}
+
+// RecordFailure suma un fallo seguido, hace esperar al webhook hasta retryAt y lo
+// desactiva si llega a disableAfter
+func (r *MemoryWebhookRepository) RecordFailure(ctx context.Context, id uint, disableAfter int, at, retryAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return fmt.Errorf("webhook %d %w", id, domain.ErrNotFound)
+	}
+	retryAt = retryAt.UTC()
+	webhook.Failures, webhook.RetryAt = webhook.Failures+1, &retryAt
+	if webhook.Active && webhook.Failures >= disableAfter {
+		at := at.UTC()
+		webhook.Active, webhook.DisabledAt = false, &at
+	}
+	return nil
+}
+
+func (r *MemoryWebhookRepository) ResetFailures(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhook, ok := r.webhooks[id]
+	if !ok {
+		return fmt.Errorf("webhook %d %w", id, domain.ErrNotFound)
+	}
+	webhook.Failures, webhook.RetryAt = 0, nil
+	return nil
+}
+
+// AddDelivery guarda una entrega nueva; con un evento repetido no hace nada y deja delivery.ID
+// en 0. Los reenvíos siempre se guardan.
+func (r *MemoryWebhookRepository) AddDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, d := range r.deliveries {
+		if delivery.RedeliveryOf == 0 && d.RedeliveryOf == 0 && d.WebhookID == delivery.WebhookID && d.EventID == delivery.EventID {
+			return nil
+		}
+	}
+	delivery.ID = r.nextDeliveryID
+	r.nextDeliveryID++
+	r.deliveries = append(r.deliveries, copyDelivery(delivery))
+	return nil
+}
+
+func (r *MemoryWebhookRepository) GetDelivery(ctx context.Context, webhookID, id uint) (*domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, d := range r.deliveries {
+		if d.ID == id && d.WebhookID == webhookID {
+			return copyDelivery(d), nil
+		}
+	}
+	return nil, fmt.Errorf("delivery %d of webhook %d %w", id, webhookID, domain.ErrNotFound)
+}
+
+// DueDeliveries obtiene hasta limit entregas pendientes con NextAttemptAt <= now de
+// webhooks activos que no están esperando, por orden de llegada
+func (r *MemoryWebhookRepository) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := []*domain.WebhookDelivery{}
+	for _, d := range r.deliveries {
+		if len(out) == limit {
+			break
+		}
+		webhook := r.webhooks[d.WebhookID]
+		waiting := webhook.RetryAt != nil && webhook.RetryAt.After(now)
+		if d.Status == domain.DeliveryPending && webhook.Active && !waiting && !d.NextAttemptAt.After(now) {
+			out = append(out, copyDelivery(d))
+		}
+	}
+	return out, nil
+}
+
+func (r *MemoryWebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, d := range r.deliveries {
+		if d.ID == delivery.ID {
+			r.deliveries[i] = copyDelivery(delivery)
+			return nil
+		}
+	}
+	return fmt.Errorf("delivery %d %w", delivery.ID, domain.ErrNotFound)
+}
+
+// ListDeliveries obtiene hasta limit entregas de un webhook, de la más nueva a la más vieja
+func (r *MemoryWebhookRepository) ListDeliveries(ctx context.Context, webhookID uint, limit int) ([]*domain.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := []*domain.WebhookDelivery{}
+	for i := len(r.deliveries) - 1; i >= 0 && len(out) < limit; i-- {
+		if r.deliveries[i].WebhookID == webhookID {
+			out = append(out, copyDelivery(r.deliveries[i]))
+		}
+	}
+	return out, nil
+}
+
+func copyWebhook(webhook *domain.Webhook) *domain.Webhook {
+	c := *webhook
+	c.Events = append([]string(nil), webhook.Events...)
+	if webhook.RetryAt != nil {
+		retryAt := *webhook.RetryAt
+		c.RetryAt = &retryAt
+	}
+	return &c
+}
+
+func copyDelivery(delivery *domain.WebhookDelivery) *domain.WebhookDelivery {
+	c := *delivery
+	c.Payload = append([]byte(nil), delivery.Payload...)
+	return &c
+}
@@ -0,0 +1,154 @@
+package infrastructure
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"api-go-gestion-libros-hexagonal/shared/database"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SqlOutboxRepository guarda los eventos por entregar en la tabla outbox. Como
+// SqlRevisionRepository, sirve para SQLite/Turso y Postgres cambiando los placeholders.
+type SqlOutboxRepository struct {
+	db     *sql.DB
+	rebind func(string) string
+}
+
+// NewSqlOutboxRepository crea el repositorio para SQLite y Turso
+func NewSqlOutboxRepository(db *sql.DB) *SqlOutboxRepository {
+	return &SqlOutboxRepository{db: db, rebind: func(q string) string { return q }}
+}
+
+// NewPostgresOutboxRepository crea el repositorio para PostgreSQL ($n en lugar de ?)
+func NewPostgresOutboxRepository(db *sql.DB) *SqlOutboxRepository {
+	return &SqlOutboxRepository{db: db, rebind: database.Rebind}
+}
+
+// conn devuelve la transacción abierta por database.TxManager, si ctx trae una
+func (r *SqlOutboxRepository) conn(ctx context.Context) database.Conn {
+	return database.ConnFrom(ctx, r.db)
+}
+
+const outboxColumns = `id, dedupe_key, event_name, book_id, payload, attempts, last_error,
+	created_at, available_at, published_at, failed_at`
+
+// pendingOutbox filtra los mensajes que todavía se van a intentar entregar
+const pendingOutbox = `published_at IS NULL AND failed_at IS NULL`
+
+// Add guarda msg; con un DedupeKey repetido no hace nada y deja msg.ID en 0
+func (r *SqlOutboxRepository) Add(ctx context.Context, msg *domain.OutboxMessage) error {
+	q := `INSERT INTO outbox (dedupe_key, event_name, book_id, payload, created_at, available_at)
+	      VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (dedupe_key) DO NOTHING RETURNING id`
+	var id int64
+	err := r.conn(ctx).QueryRowContext(ctx, r.rebind(q),
+		msg.DedupeKey, msg.EventName, int64(msg.BookID), string(msg.Payload), msg.CreatedAt.UTC(), msg.AvailableAt.UTC(),
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	msg.ID = uint(id)
+	return nil
+}
+
+// Due obtiene hasta limit mensajes pendientes con available_at <= now, por orden de llegada
+func (r *SqlOutboxRepository) Due(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxMessage, error) {
+	q := `SELECT ` + outboxColumns + ` FROM outbox
+	      WHERE ` + pendingOutbox + ` AND available_at <= ? ORDER BY id LIMIT ?`
+	return r.query(ctx, q, now.UTC(), limit)
+}
+
+// MarkPublished marca un mensaje como entregado
+func (r *SqlOutboxRepository) MarkPublished(ctx context.Context, id uint, at time.Time) error {
+	return r.update(ctx, id, `UPDATE outbox SET published_at = ? WHERE id = ?`, at.UTC())
+}
+
+// MarkRetry registra un intento fallido y deja el mensaje para retryAt
+func (r *SqlOutboxRepository) MarkRetry(ctx context.Context, id uint, reason string, retryAt time.Time) error {
+	return r.update(ctx, id, `UPDATE outbox SET attempts = attempts + 1, last_error = ?, available_at = ? WHERE id = ?`,
+		reason, retryAt.UTC())
+}
+
+// MarkFailed registra el último intento fallido: el mensaje no se reintenta más
+func (r *SqlOutboxRepository) MarkFailed(ctx context.Context, id uint, reason string, at time.Time) error {
+	return r.update(ctx, id, `UPDATE outbox SET attempts = attempts + 1, last_error = ?, failed_at = ? WHERE id = ?`,
+		reason, at.UTC())
+}
+
+// Backlog cuenta los pendientes y fallidos y devuelve hasta limit de ellos
+func (r *SqlOutboxRepository) Backlog(ctx context.Context, limit int) (*domain.OutboxBacklog, error) {
+	var backlog domain.OutboxBacklog
+	q := `SELECT
+	        (SELECT COUNT(*) FROM outbox WHERE ` + pendingOutbox + `),
+	        (SELECT COUNT(*) FROM outbox WHERE failed_at IS NOT NULL)`
+	if err := r.conn(ctx).QueryRowContext(ctx, q).Scan(&backlog.Pending, &backlog.Failed); err != nil {
+		return nil, err
+	}
+	// Sin MIN(created_at): SQLite lo devolvería como texto y no como fecha
+	var oldest time.Time
+	q = `SELECT created_at FROM outbox WHERE ` + pendingOutbox + ` ORDER BY id LIMIT 1`
+	switch err := r.conn(ctx).QueryRowContext(ctx, q).Scan(&oldest); {
+	case err == nil:
+		oldest = oldest.UTC()
+		backlog.OldestPendingAt = &oldest
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	q = `SELECT ` + outboxColumns + ` FROM outbox WHERE published_at IS NULL ORDER BY id LIMIT ?`
+	messages, err := r.query(ctx, q, limit)
+	if err != nil {
+		return nil, err
+	}
+	backlog.Messages = messages
+	return &backlog, nil
+}
+
+func (r *SqlOutboxRepository) update(ctx context.Context, id uint, q string, args ...any) error {
+	res, err := r.conn(ctx).ExecContext(ctx, r.rebind(q), append(args, int64(id))...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("outbox message %d %w", id, domain.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *SqlOutboxRepository) query(ctx context.Context, q string, args ...any) ([]*domain.OutboxMessage, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, r.rebind(q), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []*domain.OutboxMessage{}
+	for rows.Next() {
+		var (
+			msg                    domain.OutboxMessage
+			id, bookID             int64
+			payload                string
+			createdAt, availableAt time.Time
+			publishedAt, failedAt  sql.NullTime
+		)
+		err := rows.Scan(&id, &msg.DedupeKey, &msg.EventName, &bookID, &payload, &msg.Attempts, &msg.LastError,
+			&createdAt, &availableAt, &publishedAt, &failedAt)
+		if err != nil {
+			return nil, err
+		}
+		msg.ID, msg.BookID, msg.Payload = uint(id), uint(bookID), []byte(payload)
+		msg.CreatedAt, msg.AvailableAt = createdAt.UTC(), availableAt.UTC()
+		msg.PublishedAt, msg.FailedAt = nullTime(publishedAt), nullTime(failedAt)
+		out = append(out, &msg)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,193 @@
+package infrastructure
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Consultas de PostgresBookRepository. Los textos se comparan sin tildes con f_unaccent
+// (envoltorio IMMUTABLE de unaccent) y sin mayúsculas con ILIKE o lower(); el texto libre
+// usa la columna tsvector search con la configuración books_search.
+// Los placeholders se escriben como "?" y se traducen a $n con database.Rebind.
+
+// pgTextSearchConfig es la configuración de texto completo creada por las migraciones
+const pgTextSearchConfig = "books_search"
+
+// pgFieldWeights es el peso con que cada campo entra en la columna search
+var pgFieldWeights = map[string]string{"title": "A", "author": "B", "genre": "C"}
+
+// pgFolded es la expresión SQL del valor plegado de un campo de texto, comparable con domain.FoldText
+func pgFolded(field string) string {
+	return "lower(f_unaccent(b." + field + "))"
+}
+
+// pgFilterClauses es la versión Postgres de filterClauses
+func pgFilterClauses(filter domain.BookFilter, query domain.QueryNode) ([]string, []any) {
+	// Los libros de la papelera no aparecen en ninguna búsqueda
+	clauses := []string{"b.deleted_at IS NULL"}
+	args := []any{}
+
+	// ILIKE ignora mayúsculas y f_unaccent las tildes del valor guardado;
+	// el texto buscado ya viene plegado
+	addLike := func(field string, p *string) {
+		if p != nil {
+			clauses = append(clauses, fmt.Sprintf(`f_unaccent(b.%s) ILIKE ? ESCAPE '\'`, field))
+			args = append(args, "%"+escapeLike(domain.FoldText(*p))+"%")
+		}
+	}
+	addCmpUint := func(col, op string, p *uint) {
+		if p != nil {
+			clauses = append(clauses, fmt.Sprintf("%s %s ?", col, op))
+			args = append(args, int(*p))
+		}
+	}
+	addCmpTime := func(col, op string, p *time.Time) {
+		if p != nil {
+			clauses = append(clauses, fmt.Sprintf("%s %s ?", col, op))
+			args = append(args, p.UTC())
+		}
+	}
+
+	addLike("title", filter.Title)
+	addLike("author", filter.Author)
+	addCmpUint("b.year", "=", filter.Year)
+	addLike("genre", filter.Genre)
+	addCmpUint("b.year", ">=", filter.YearFrom)
+	addCmpUint("b.year", "<=", filter.YearTo)
+	if len(filter.Genres) > 0 {
+		marks := make([]string, len(filter.Genres))
+		for i, g := range filter.Genres {
+			marks[i] = "?"
+			args = append(args, domain.FoldText(g))
+		}
+		clauses = append(clauses, fmt.Sprintf("%s IN (%s)", pgFolded("genre"), strings.Join(marks, ", ")))
+	}
+	if filter.ISBNPrefix != nil {
+		// El dominio garantiza que el prefijo solo tiene dígitos o X, sin comodines
+		clauses = append(clauses, "b.isbn LIKE ?")
+		args = append(args, domain.NormalizeISBN(*filter.ISBNPrefix)+"%")
+	}
+	addCmpTime("b.created_at", ">", filter.CreatedAfter)
+	addCmpTime("b.created_at", "<", filter.CreatedBefore)
+	addCmpTime("b.updated_at", ">=", filter.UpdatedSince)
+	addCmpTime("b.updated_at", "<", filter.UpdatedBefore)
+
+	if query != nil {
+		clause, queryArgs := compilePgQuery(query)
+		clauses = append(clauses, clause)
+		args = append(args, queryArgs...)
+	}
+
+	return clauses, args
+}
+
+// compilePgQuery es la versión Postgres de compileQuery, con la misma semántica
+func compilePgQuery(node domain.QueryNode) (string, []any) {
+	switch n := node.(type) {
+	case domain.AndNode:
+		return compilePgGroup(n.Children, " AND ")
+	case domain.OrNode:
+		return compilePgGroup(n.Children, " OR ")
+	case domain.NotNode:
+		clause, args := compilePgQuery(n.Child)
+		return "NOT " + clause, args
+	case domain.FieldTerm:
+		return compilePgFieldTerm(n)
+	case domain.TextTerm:
+		expr := tsTermExpr(n)
+		if expr == "" {
+			return "1 = 1", nil // sin palabras buscables (ej. solo signos)
+		}
+		return "b.search @@ to_tsquery('" + pgTextSearchConfig + "', ?)", []any{expr}
+	}
+	return "1 = 1", nil
+}
+
+func compilePgGroup(children []domain.QueryNode, sep string) (string, []any) {
+	parts := make([]string, len(children))
+	args := []any{}
+	for i, c := range children {
+		clause, a := compilePgQuery(c)
+		parts[i] = clause
+		args = append(args, a...)
+	}
+	return "(" + strings.Join(parts, sep) + ")", args
+}
+
+func compilePgFieldTerm(t domain.FieldTerm) (string, []any) {
+	switch t.Field {
+	case "title", "author", "genre":
+		if t.Op == "=" {
+			return pgFolded(t.Field) + " = ?", []any{domain.FoldText(t.Value)}
+		}
+		return fmt.Sprintf(`f_unaccent(b.%s) ILIKE ? ESCAPE '\'`, t.Field), []any{"%" + escapeLike(domain.FoldText(t.Value)) + "%"}
+	case "isbn":
+		isbn := domain.NormalizeISBN(t.Value)
+		if t.Op == "=" {
+			return "b.isbn = ?", []any{isbn}
+		}
+		return `b.isbn LIKE ? ESCAPE '\'`, []any{escapeLike(isbn) + "%"}
+	case "year":
+		// El parser ya validó que el valor es numérico
+		year, _ := strconv.Atoi(t.Value)
+		op := t.Op
+		if op == ":" {
+			op = "="
+		}
+		return fmt.Sprintf("b.year %s ?", op), []any{year}
+	}
+	return "1 = 1", nil
+}
+
+// tsQueryExpr arma una expresión de to_tsquery: cada término entre comillas (sin operadores
+// del usuario) y con :* para coincidir por prefijo. weights restringe los campos (ej. "A").
+func tsQueryExpr(terms []string, weights string) string {
+	parts := make([]string, len(terms))
+	for i, t := range terms {
+		parts[i] = "'" + strings.ReplaceAll(t, "'", "''") + "':*" + weights
+	}
+	return strings.Join(parts, " & ")
+}
+
+// tsTermExpr es la versión Postgres de ftsTermExpr: una frase exacta si venía entre
+// comillas (palabras seguidas, <->), o cada palabra por prefijo
+func tsTermExpr(t domain.TextTerm) string {
+	terms := domain.FullTextTerms(t.Text)
+	if len(terms) == 0 {
+		return ""
+	}
+	if t.Phrase {
+		parts := make([]string, len(terms))
+		for i, term := range terms {
+			parts[i] = "'" + strings.ReplaceAll(term, "'", "''") + "'"
+		}
+		return strings.Join(parts, " <-> ")
+	}
+	return tsQueryExpr(terms, "")
+}
+
+// tsRankingExpr une los términos positivos con OR (|), como ftsRankingExpr
+func tsRankingExpr(terms []domain.TextTerm) string {
+	parts := []string{}
+	for _, t := range terms {
+		if expr := tsTermExpr(t); expr != "" {
+			parts = append(parts, "("+expr+")")
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+// pgSortColumn es la versión Postgres de sortColumn. Los textos se ordenan con
+// COLLATE "C" (por bytes), igual que SQLite y el repositorio en memoria.
+func pgSortColumn(field string) string {
+	switch field {
+	case domain.RelevanceField:
+		return "COALESCE(h.score, 0)"
+	case "title", "author", "genre", "isbn":
+		return "b." + field + ` COLLATE "C"`
+	}
+	return "b." + field
+}
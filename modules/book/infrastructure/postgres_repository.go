@@ -0,0 +1,383 @@
+package infrastructure
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"api-go-gestion-libros-hexagonal/shared/database"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// pgUniqueViolation es el SQLSTATE de una violación de restricción UNIQUE
+const pgUniqueViolation = "23505"
+
+// PostgresBookRepository guarda los libros en PostgreSQL. Se comporta igual que
+// SqlBookRepository (ver repotest), pero con SQL propio de Postgres: unaccent para
+// buscar sin tildes y tsvector en lugar de FTS5.
+type PostgresBookRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresBookRepository(db *sql.DB) *PostgresBookRepository {
+	return &PostgresBookRepository{db: db}
+}
+
+// conn devuelve la transacción abierta por database.TxManager, si ctx trae una
+func (r *PostgresBookRepository) conn(ctx context.Context) database.Conn {
+	return database.ConnFrom(ctx, r.db)
+}
+
+// Create crea un nuevo libro en el repositorio
+func (r *PostgresBookRepository) Create(ctx context.Context, book *domain.Book) error {
+	isbn := domain.NormalizeISBN(book.ISBN)
+	q := `INSERT INTO books (title, author, year, genre, isbn, created_at, updated_at)
+	      VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id, version`
+	var id, version int64
+	err := r.conn(ctx).QueryRowContext(ctx, database.Rebind(q),
+		strings.TrimSpace(book.Title),
+		strings.TrimSpace(book.Author),
+		int(book.Year),
+		strings.TrimSpace(book.Genre),
+		isbn,
+		book.CreatedAt.UTC(),
+		book.UpdatedAt.UTC(),
+	).Scan(&id, &version)
+	if err != nil {
+		if isPgUniqueViolation(err) {
+			return fmt.Errorf("%w: %s", domain.ErrDuplicateISBN, isbn)
+		}
+		return err
+	}
+	book.ID = uint(id)
+	book.Version = uint(version)
+	return nil
+}
+
+// Update actualiza un libro existente en el repositorio si sigue en book.Version,
+// y sube la versión. Si otro lo cambió antes devuelve domain.ErrVersionMismatch.
+func (r *PostgresBookRepository) Update(ctx context.Context, book *domain.Book) (*domain.Book, error) {
+	isbn := domain.NormalizeISBN(book.ISBN)
+	q := `UPDATE books SET title = ?, author = ?, year = ?, genre = ?, isbn = ?, updated_at = ?, version = version + 1
+	      WHERE id = ? AND version = ? AND deleted_at IS NULL`
+	res, err := r.conn(ctx).ExecContext(ctx, database.Rebind(q),
+		strings.TrimSpace(book.Title),
+		strings.TrimSpace(book.Author),
+		int(book.Year),
+		strings.TrimSpace(book.Genre),
+		isbn,
+		time.Now().UTC(),
+		int64(book.ID),
+		int(book.Version),
+	)
+	if err != nil {
+		if isPgUniqueViolation(err) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrDuplicateISBN, isbn)
+		}
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		// No existe (ErrNotFound) o cambió de versión
+		current, err := r.GetByID(ctx, book.ID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, versionMismatch(book, current.Version)
+	}
+	return r.GetByID(ctx, book.ID)
+}
+
+// Delete mueve un libro a la papelera: marca deleted_at y sube la versión
+func (r *PostgresBookRepository) Delete(ctx context.Context, id uint) error {
+	q := `UPDATE books SET deleted_at = $1, version = version + 1 WHERE id = $2 AND deleted_at IS NULL`
+	res, err := r.conn(ctx).ExecContext(ctx, q, time.Now().UTC(), int64(id))
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("book %d %w", id, domain.ErrNotFound)
+	}
+	return nil
+}
+
+// ListDeleted obtiene los libros de la papelera, del borrado más reciente al más viejo
+func (r *PostgresBookRepository) ListDeleted(ctx context.Context) ([]*domain.Book, error) {
+	q := "SELECT " + bookColumns + " FROM books b WHERE b.deleted_at IS NOT NULL ORDER BY b.deleted_at DESC, b.id DESC"
+	rows, err := r.conn(ctx).QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanBooks(rows)
+}
+
+// Restore saca un libro de la papelera y sube su versión. Si otro libro activo tomó
+// su ISBN, el índice único parcial lo rechaza: ver restoreConflict.
+func (r *PostgresBookRepository) Restore(ctx context.Context, id uint) (*domain.Book, error) {
+	q := `UPDATE books SET deleted_at = NULL, updated_at = $1, version = version + 1 WHERE id = $2 AND deleted_at IS NOT NULL`
+	res, err := r.conn(ctx).ExecContext(ctx, q, time.Now().UTC(), int64(id))
+	if err != nil {
+		if isPgUniqueViolation(err) {
+			return nil, restoreConflict(id)
+		}
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, fmt.Errorf("book %d %w in trash", id, domain.ErrNotFound)
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Purge elimina definitivamente los libros que están en la papelera desde antes de deletedBefore
+func (r *PostgresBookRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	res, err := r.conn(ctx).ExecContext(ctx, `DELETE FROM books WHERE deleted_at < $1`, deletedBefore.UTC())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// GetAll obtiene todos los libros del repositorio
+func (r *PostgresBookRepository) GetAll(ctx context.Context) ([]*domain.Book, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, "SELECT "+bookColumns+" FROM books b WHERE b.deleted_at IS NULL ORDER BY b.id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanBooks(rows)
+}
+
+// GetByISBN obtiene un libro por ISBN del repositorio
+func (r *PostgresBookRepository) GetByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
+	row := r.conn(ctx).QueryRowContext(ctx, "SELECT "+bookColumns+" FROM books b WHERE b.isbn = $1 AND b.deleted_at IS NULL", domain.NormalizeISBN(isbn))
+	book, err := scanBook(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("book with isbn %s %w", isbn, domain.ErrNotFound)
+	}
+	return book, err
+}
+
+// GetByID obtiene un libro por ID
+func (r *PostgresBookRepository) GetByID(ctx context.Context, id uint) (*domain.Book, error) {
+	row := r.conn(ctx).QueryRowContext(ctx, "SELECT "+bookColumns+" FROM books b WHERE b.id = $1 AND b.deleted_at IS NULL", int64(id))
+	book, err := scanBook(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("book %d %w", id, domain.ErrNotFound)
+	}
+	return book, err
+}
+
+// FindByFilter obtiene una página de libros por filtros del repositorio.
+// El texto libre de filter.Query se busca en la columna search y, sin orden
+// explícito, se ordena por relevancia (ts_rank).
+func (r *PostgresBookRepository) FindByFilter(ctx context.Context, filter domain.BookFilter) (*domain.BookPage, error) {
+	query, err := domain.ParseQuery(filter.Query)
+	if err != nil {
+		return nil, err
+	}
+	clauses, args := pgFilterClauses(filter, query)
+
+	from, fromArgs := pgFromClause(query)
+	ranked := len(fromArgs) > 0
+
+	where := ""
+	if len(clauses) > 0 {
+		where = " WHERE " + strings.Join(clauses, " AND ")
+	}
+
+	// Total sin paginar; el join de relevancia no cambia las filas, así que no hace falta
+	var total int
+	if err := r.conn(ctx).QueryRowContext(ctx, database.Rebind("SELECT COUNT(*) FROM books b"+where), args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	// Keyset: continuar después del último libro entregado, según el orden pedido
+	sort := filter.EffectiveSort()
+	keys := domain.StableSort(sort)
+	if filter.Cursor != "" {
+		cur, err := domain.DecodeCursor(filter.Cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+		clause, keyArgs := keysetClause(keys, cur.Values, pgSortColumn)
+		clauses = append(clauses, clause)
+		args = append(args, keyArgs...)
+		where = " WHERE " + strings.Join(clauses, " AND ")
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = domain.DefaultPageLimit
+	}
+
+	// Pedimos uno más para saber si hay otra página
+	cols := bookColumns
+	if ranked {
+		cols += ", COALESCE(h.score, 0), COALESCE(h.hl_title, ''), COALESCE(h.hl_author, ''), COALESCE(h.hl_genre, '')"
+	}
+	q := "SELECT " + cols + from + where + orderByClause(keys, pgSortColumn) + " LIMIT ? OFFSET ?"
+	args = append(append(fromArgs, args...), limit+1, filter.Offset)
+
+	rows, err := r.conn(ctx).QueryContext(ctx, database.Rebind(q), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !ranked {
+		books, err := scanBooks(rows)
+		if err != nil {
+			return nil, err
+		}
+		return newBookPage(books, nil, total, limit, sort), nil
+	}
+	books, matches, err := scanRankedBooks(rows)
+	if err != nil {
+		return nil, err
+	}
+	return newBookPage(books, matches, total, limit, sort), nil
+}
+
+// pgFromClause es la versión Postgres de fromClause: el join lateral (alias h) calcula
+// relevancia y resaltado solo para los libros que coinciden con el texto libre.
+// Como en bm25, menor es más relevante: el score es ts_rank con signo negativo.
+func pgFromClause(query domain.QueryNode) (string, []any) {
+	ranking := domain.RankingTerms(query)
+	if len(ranking) == 0 {
+		return " FROM books b", nil
+	}
+	opts := "'StartSel=" + highlightOpen + ", StopSel=" + highlightClose + ", HighlightAll=true'"
+	headline := func(field string) string {
+		return "ts_headline('" + pgTextSearchConfig + "', b." + field + ", q.q, " + opts + ") AS hl_" + field
+	}
+	from := ` FROM books b LEFT JOIN LATERAL (
+		SELECT -ts_rank(b.search, q.q)::float8 AS score,
+		       ` + headline("title") + `,
+		       ` + headline("author") + `,
+		       ` + headline("genre") + `
+		FROM to_tsquery('` + pgTextSearchConfig + `', ?) AS q(q)
+		WHERE b.search @@ q.q
+	) h ON true`
+	return from, []any{tsRankingExpr(ranking)}
+}
+
+// CountFacets cuenta los libros que cumplen el filtro por género, década y autor en una sola consulta
+func (r *PostgresBookRepository) CountFacets(ctx context.Context, filter domain.BookFilter) (*domain.BookFacets, error) {
+	query, err := domain.ParseQuery(filter.Query)
+	if err != nil {
+		return nil, err
+	}
+	clauses, args := pgFilterClauses(filter, query)
+	where := ""
+	if len(clauses) > 0 {
+		where = " WHERE " + strings.Join(clauses, " AND ")
+	}
+
+	// Género y autor se agrupan por su forma plegada para no separar "Ficción" de "ficcion";
+	// se muestra la forma menor en bytes, como MIN en SQLite
+	facet := func(name, label, group string, limit int) string {
+		q := fmt.Sprintf(`SELECT '%s' AS facet, %s AS value, COUNT(*) AS n FROM books b%s GROUP BY %s ORDER BY n DESC, value`,
+			name, label, where, group)
+		if limit > 0 {
+			q += fmt.Sprintf(" LIMIT %d", limit)
+		}
+		return "SELECT * FROM (" + q + ") AS " + name
+	}
+	q := strings.Join([]string{
+		facet("genre", `MIN(b.genre COLLATE "C")`, pgFolded("genre"), domain.MaxFacetValues),
+		facet("decade", "((b.year / 10) * 10)::text", "(b.year / 10) * 10", 0),
+		facet("author", `MIN(b.author COLLATE "C")`, pgFolded("author"), domain.MaxFacetValues),
+	}, " UNION ALL ")
+	allArgs := append(append(append([]any{}, args...), args...), args...)
+
+	rows, err := r.conn(ctx).QueryContext(ctx, database.Rebind(q), allArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	facets := domain.NewBookFacets()
+	for rows.Next() {
+		var (
+			name, label string
+			n           int
+		)
+		if err := rows.Scan(&name, &label, &n); err != nil {
+			return nil, err
+		}
+		switch name {
+		case "genre":
+			facets.Genre[label] = n
+		case "decade":
+			facets.Decade[label] = n
+		case "author":
+			facets.Author[label] = n
+		}
+	}
+	return facets, rows.Err()
+}
+
+// SuggestByPrefix obtiene los valores de title o author que empiezan por el prefijo, por frecuencia.
+// El valor completo se busca con LIKE sobre el índice lower(f_unaccent(...)) y las palabras
+// sueltas con la columna search restringida al peso del campo.
+func (r *PostgresBookRepository) SuggestByPrefix(ctx context.Context, field, prefix string, limit int) ([]domain.ValueCount, error) {
+	if err := domain.ValidateAutocompleteField(field); err != nil {
+		return nil, err
+	}
+	folded := domain.FoldText(prefix)
+	terms := domain.FullTextTerms(folded)
+	if len(terms) == 0 {
+		return []domain.ValueCount{}, nil
+	}
+	norm := pgFolded(field)
+	q := fmt.Sprintf(`SELECT MIN(b.%s COLLATE "C") AS value, COUNT(*) AS n FROM books b
+		WHERE b.deleted_at IS NULL AND (%s LIKE ? ESCAPE '\' OR b.search @@ to_tsquery('%s', ?))
+		GROUP BY %s ORDER BY n DESC, value LIMIT ?`, field, norm, pgTextSearchConfig, norm)
+	match := tsQueryExpr(terms, pgFieldWeights[field])
+
+	rows, err := r.conn(ctx).QueryContext(ctx, database.Rebind(q), escapeLike(folded)+"%", match, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.ValueCount{}
+	for rows.Next() {
+		var vc domain.ValueCount
+		if err := rows.Scan(&vc.Value, &vc.Count); err != nil {
+			return nil, err
+		}
+		out = append(out, vc)
+	}
+	return out, rows.Err()
+}
+
+// SuggestionCandidates obtiene los candidatos a sugerencia con la columna search (índice GIN)
+// restringida al peso del campo: palabras que empiezan por alguno de los prefijos.
+func (r *PostgresBookRepository) SuggestionCandidates(ctx context.Context, field string, prefixes []string, limit int) ([]string, error) {
+	if err := domain.ValidateAutocompleteField(field); err != nil {
+		return nil, err
+	}
+	if len(prefixes) == 0 {
+		return []string{}, nil
+	}
+	parts := make([]string, len(prefixes))
+	for i, p := range prefixes {
+		parts[i] = tsQueryExpr([]string{p}, pgFieldWeights[field])
+	}
+	norm := pgFolded(field)
+	q := fmt.Sprintf(`SELECT MIN(b.%s COLLATE "C") AS value FROM books b
+		WHERE b.deleted_at IS NULL AND b.search @@ to_tsquery('%s', ?)
+		GROUP BY %s ORDER BY COUNT(*) DESC, value LIMIT ?`, field, pgTextSearchConfig, norm)
+	return queryStrings(ctx, r.conn(ctx), database.Rebind(q), strings.Join(parts, " | "), limit)
+}
+
+// isPgUniqueViolation detecta la violación de UNIQUE por su SQLSTATE, no por el mensaje
+func isPgUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation
+}
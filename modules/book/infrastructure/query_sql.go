@@ -0,0 +1,93 @@
+package infrastructure
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// compileQuery traduce el árbol de una consulta avanzada a una condición SQL
+// parametrizada sobre books b. Los valores nunca se interpolan en el SQL.
+func compileQuery(node domain.QueryNode) (string, []any) {
+	switch n := node.(type) {
+	case domain.AndNode:
+		return compileGroup(n.Children, " AND ")
+	case domain.OrNode:
+		return compileGroup(n.Children, " OR ")
+	case domain.NotNode:
+		clause, args := compileQuery(n.Child)
+		return "NOT " + clause, args
+	case domain.FieldTerm:
+		return compileFieldTerm(n)
+	case domain.TextTerm:
+		expr := ftsTermExpr(n)
+		if expr == "" {
+			return "1 = 1", nil // sin palabras buscables (ej. solo signos)
+		}
+		return "b.id IN (SELECT rowid FROM books_fts WHERE books_fts MATCH ?)", []any{expr}
+	}
+	return "1 = 1", nil
+}
+
+func compileGroup(children []domain.QueryNode, sep string) (string, []any) {
+	parts := make([]string, len(children))
+	args := []any{}
+	for i, c := range children {
+		clause, a := compileQuery(c)
+		parts[i] = clause
+		args = append(args, a...)
+	}
+	return "(" + strings.Join(parts, sep) + ")", args
+}
+
+func compileFieldTerm(t domain.FieldTerm) (string, []any) {
+	switch t.Field {
+	case "title", "author", "genre":
+		col := "b." + t.Field + "_norm"
+		if t.Op == "=" {
+			return col + " = ?", []any{domain.FoldText(t.Value)}
+		}
+		return col + ` LIKE ? ESCAPE '\'`, []any{"%" + escapeLike(domain.FoldText(t.Value)) + "%"}
+	case "isbn":
+		isbn := domain.NormalizeISBN(t.Value)
+		if t.Op == "=" {
+			return "b.isbn = ?", []any{isbn}
+		}
+		return `b.isbn LIKE ? ESCAPE '\'`, []any{escapeLike(isbn) + "%"}
+	case "year":
+		// El parser ya validó que el valor es numérico
+		year, _ := strconv.Atoi(t.Value)
+		op := t.Op
+		if op == ":" {
+			op = "="
+		}
+		return fmt.Sprintf("b.year %s ?", op), []any{year}
+	}
+	return "1 = 1", nil
+}
+
+// ftsTermExpr arma la expresión MATCH de un término de texto libre:
+// una frase exacta si venía entre comillas, o cada palabra por prefijo
+func ftsTermExpr(t domain.TextTerm) string {
+	terms := domain.FullTextTerms(t.Text)
+	if len(terms) == 0 {
+		return ""
+	}
+	if t.Phrase {
+		return `"` + strings.Join(terms, " ") + `"`
+	}
+	return ftsMatchExpr(terms)
+}
+
+// ftsRankingExpr une los términos positivos con OR: cualquier libro que coincida
+// con alguno obtiene relevancia y resaltado
+func ftsRankingExpr(terms []domain.TextTerm) string {
+	parts := []string{}
+	for _, t := range terms {
+		if expr := ftsTermExpr(t); expr != "" {
+			parts = append(parts, "("+expr+")")
+		}
+	}
+	return strings.Join(parts, " OR ")
+}
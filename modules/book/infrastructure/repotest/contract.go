@@ -0,0 +1,566 @@
+// Package repotest es la suite de conformidad de domain.BookRepository.
+// Cada adaptador la ejecuta desde sus tests, así todos cumplen las mismas reglas
+// (las que los mocks de gomock no verifican):
+//
+//	repotest.RunBookRepositoryContract(t, func(t *testing.T) domain.BookRepository {
+//		return infrastructure.NewMemoryBookRepository()
+//	})
+package repotest
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory crea un repositorio vacío para cada test
+type Factory func(t *testing.T) domain.BookRepository
+
+// base es la fecha de creación del primer libro de ejemplo
+var base = time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+// catalog son los libros de ejemplo; se crean en este orden, una hora después uno de otro
+func catalog() []*domain.Book {
+	books := []*domain.Book{
+		{Title: "Ficciones", Author: "Jorge Luis Borges", Year: 1944, Genre: "Cuento", ISBN: "9788420633114"},
+		{Title: "El Aleph", Author: "Jorge Luis Borges", Year: 1949, Genre: "Cuento", ISBN: "9788420633121"},
+		{Title: "El libro de arena", Author: "Jorge Luis Borges", Year: 1975, Genre: "Cuento", ISBN: "9788420633138"},
+		{Title: "Cien años de soledad", Author: "Gabriel García Márquez", Year: 1967, Genre: "Novela", ISBN: "9780307474728"},
+		{Title: "Rayuela", Author: "Julio Cortázar", Year: 1963, Genre: "Novela", ISBN: "9788437604572"},
+		{Title: "Otras inquisiciones", Author: "Jorge Luis Borges", Year: 1952, Genre: "Ensayo", ISBN: "9788420633145"},
+		{Title: "Pedro Páramo", Author: "Juan Rulfo", Year: 1955, Genre: "novela", ISBN: "0307474720"},
+	}
+	for i, b := range books {
+		b.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+		b.UpdatedAt = b.CreatedAt
+	}
+	return books
+}
+
+func seed(t *testing.T, repo domain.BookRepository) []*domain.Book {
+	t.Helper()
+	books := catalog()
+	for _, b := range books {
+		require.NoError(t, repo.Create(context.Background(), b))
+	}
+	return books
+}
+
+func titles(books []*domain.Book) []string {
+	out := make([]string, len(books))
+	for i, b := range books {
+		out[i] = b.Title
+	}
+	return out
+}
+
+func sortedTitles(books []*domain.Book) []string {
+	out := titles(books)
+	sort.Strings(out)
+	return out
+}
+
+func strPtr(s string) *string        { return &s }
+func uintPtr(u uint) *uint           { return &u }
+func timePtr(t time.Time) *time.Time { return &t }
+
+// RunBookRepositoryContract ejecuta la suite completa contra los repositorios que crea newRepo
+func RunBookRepositoryContract(t *testing.T, newRepo Factory) {
+	t.Run("CRUD", func(t *testing.T) { testCRUD(t, newRepo) })
+	t.Run("NotFound", func(t *testing.T) { testNotFound(t, newRepo) })
+	t.Run("DuplicateISBN", func(t *testing.T) { testDuplicateISBN(t, newRepo) })
+	t.Run("Timestamps", func(t *testing.T) { testTimestamps(t, newRepo) })
+	t.Run("Versioning", func(t *testing.T) { testVersioning(t, newRepo) })
+	t.Run("SoftDelete", func(t *testing.T) { testSoftDelete(t, newRepo) })
+	t.Run("TrashAndRestore", func(t *testing.T) { testTrashAndRestore(t, newRepo) })
+	t.Run("Purge", func(t *testing.T) { testPurge(t, newRepo) })
+	t.Run("Filters", func(t *testing.T) { testFilters(t, newRepo) })
+	t.Run("Sorting", func(t *testing.T) { testSorting(t, newRepo) })
+	t.Run("Pagination", func(t *testing.T) { testPagination(t, newRepo) })
+	t.Run("Relevance", func(t *testing.T) { testRelevance(t, newRepo) })
+	t.Run("Facets", func(t *testing.T) { testFacets(t, newRepo) })
+	t.Run("SuggestAndCandidates", func(t *testing.T) { testSuggestAndCandidates(t, newRepo) })
+}
+
+func testCRUD(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t)
+
+	// Create asigna IDs crecientes y guarda el ISBN normalizado y el texto sin espacios de más
+	book := &domain.Book{Title: "  Ficciones ", Author: "Jorge Luis Borges", Year: 1944, Genre: "Cuento",
+		ISBN: "978-84-206-3311-4", CreatedAt: base, UpdatedAt: base}
+	require.NoError(t, repo.Create(ctx, book))
+	other := catalog()[3]
+	require.NoError(t, repo.Create(ctx, other))
+	assert.NotZero(t, book.ID)
+	assert.Greater(t, other.ID, book.ID)
+
+	found, err := repo.GetByID(ctx, book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Ficciones", found.Title)
+	assert.Equal(t, "9788420633114", found.ISBN)
+
+	byISBN, err := repo.GetByISBN(ctx, "978-84-206-3311-4")
+	require.NoError(t, err)
+	assert.Equal(t, book.ID, byISBN.ID)
+
+	// Update cambia los campos, conserva created_at y actualiza updated_at
+	found.Title = "Ficciones (1944)"
+	found.Year = 1945
+	updated, err := repo.Update(ctx, found)
+	require.NoError(t, err)
+	assert.Equal(t, "Ficciones (1944)", updated.Title)
+	assert.Equal(t, uint(1945), updated.Year)
+	assert.Equal(t, base, updated.CreatedAt)
+	assert.True(t, updated.UpdatedAt.After(base))
+
+	all, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []uint{book.ID, other.ID}, []uint{all[0].ID, all[1].ID})
+
+	// Delete
+	require.NoError(t, repo.Delete(ctx, book.ID))
+	_, err = repo.GetByID(ctx, book.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	all, err = repo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+}
+
+func testNotFound(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t)
+	seed(t, repo)
+
+	book, err := repo.GetByID(ctx, 9999)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.Nil(t, book)
+
+	book, err = repo.GetByISBN(ctx, "9780000000002")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.Nil(t, book)
+
+	missing := catalog()[0]
+	missing.ID = 9999
+	missing.ISBN = "9780000000002"
+	book, err = repo.Update(ctx, missing)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.Nil(t, book)
+
+	assert.ErrorIs(t, repo.Delete(ctx, 9999), domain.ErrNotFound)
+}
+
+func testDuplicateISBN(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t)
+	books := seed(t, repo)
+
+	// El mismo ISBN con guiones sigue siendo el mismo
+	dup := catalog()[1]
+	dup.ISBN = "978-84-206-3311-4"
+	err := repo.Create(ctx, dup)
+	assert.ErrorIs(t, err, domain.ErrDuplicateISBN)
+	assert.EqualError(t, err, "duplicate isbn: 9788420633114")
+
+	// Update no puede tomar el ISBN de otro libro
+	other := *books[1]
+	other.ISBN = books[0].ISBN
+	_, err = repo.Update(ctx, &other)
+	assert.ErrorIs(t, err, domain.ErrDuplicateISBN)
+	assert.EqualError(t, err, "duplicate isbn: 9788420633114")
+
+	// Pero sí conservar el propio
+	same := *books[1]
+	_, err = repo.Update(ctx, &same)
+	assert.NoError(t, err)
+
+	all, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, len(books))
+}
+
+func testTimestamps(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t)
+
+	// Microsegundos (la precisión que guardan todos los adaptadores, Postgres incluido) y
+	// zona horaria distinta de UTC: se leen como el mismo instante en UTC
+	zone := time.FixedZone("ART", -3*60*60)
+	created := time.Date(2024, 3, 15, 9, 30, 15, 123456000, zone)
+	book := &domain.Book{Title: "Rayuela", Author: "Julio Cortázar", Year: 1963, Genre: "Novela",
+		ISBN: "9788437604572", CreatedAt: created, UpdatedAt: created}
+	require.NoError(t, repo.Create(ctx, book))
+
+	found, err := repo.GetByID(ctx, book.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created.UTC(), found.CreatedAt)
+	assert.Equal(t, created.UTC(), found.UpdatedAt)
+	assert.Equal(t, time.UTC, found.CreatedAt.Location())
+
+	// Las ventanas de tiempo comparan instantes, no texto
+	page, err := repo.FindByFilter(ctx, domain.BookFilter{CreatedAfter: timePtr(created.Add(-time.Microsecond)), Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Total)
+	page, err = repo.FindByFilter(ctx, domain.BookFilter{CreatedAfter: timePtr(created), Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 0, page.Total)
+}
+
+func testVersioning(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t)
+	books := seed(t, repo)
+
+	// Create empieza en la versión 1 y cada Update la sube
+	assert.Equal(t, uint(1), books[0].Version)
+	first := *books[0]
+	first.Title = "Ficciones (1944)"
+	updated, err := repo.Update(ctx, &first)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), updated.Version)
+
+	found, err := repo.GetByID(ctx, books[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), found.Version)
+
+	// Un Update con la versión vieja no pisa el cambio anterior
+	stale := *books[0]
+	stale.Title = "Ficciones (otra edición)"
+	_, err = repo.Update(ctx, &stale)
+	assert.ErrorIs(t, err, domain.ErrVersionMismatch)
+	found, err = repo.GetByID(ctx, books[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Ficciones (1944)", found.Title)
+	assert.Equal(t, uint(2), found.Version)
+}
+
+func testSoftDelete(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t)
+	books := seed(t, repo)
+	ficciones := books[0]
+	require.NoError(t, repo.Delete(ctx, ficciones.ID))
+
+	// Un libro en la papelera no aparece en ninguna lectura
+	_, err := repo.GetByID(ctx, ficciones.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = repo.GetByISBN(ctx, ficciones.ISBN)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	all, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, len(books)-1)
+
+	page, err := repo.FindByFilter(ctx, domain.BookFilter{Query: "ficciones", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 0, page.Total)
+	page, err = repo.FindByFilter(ctx, domain.BookFilter{Author: strPtr("borges"), Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total)
+	assert.NotContains(t, titles(page.Books), "Ficciones")
+
+	facets, err := repo.CountFacets(ctx, domain.BookFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, facets.Author["Jorge Luis Borges"])
+	assert.Equal(t, 2, facets.Genre["Cuento"])
+
+	suggested, err := repo.SuggestByPrefix(ctx, "title", "fic", 10)
+	require.NoError(t, err)
+	assert.Empty(t, suggested)
+	candidates, err := repo.SuggestionCandidates(ctx, "title", []string{"fi"}, 10)
+	require.NoError(t, err)
+	assert.Empty(t, candidates)
+
+	// Ni se puede volver a borrar ni actualizar
+	assert.ErrorIs(t, repo.Delete(ctx, ficciones.ID), domain.ErrNotFound)
+	trashed := *ficciones
+	trashed.Version = 2
+	_, err = repo.Update(ctx, &trashed)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testTrashAndRestore(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t)
+	books := seed(t, repo)
+	require.NoError(t, repo.Delete(ctx, books[1].ID))
+	require.NoError(t, repo.Delete(ctx, books[2].ID))
+
+	// La papelera lista del borrado más reciente al más viejo, con la fecha de borrado
+	trash, err := repo.ListDeleted(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"El libro de arena", "El Aleph"}, titles(trash))
+	for _, b := range trash {
+		require.NotNil(t, b.DeletedAt)
+		assert.Equal(t, time.UTC, b.DeletedAt.Location())
+		assert.Equal(t, uint(2), b.Version, "borrar sube la versión")
+	}
+
+	// El ISBN de un libro borrado queda libre
+	reprint := catalog()[1]
+	reprint.Title = "El Aleph (reedición)"
+	require.NoError(t, repo.Create(ctx, reprint))
+
+	// Y mientras otro libro lo use, el borrado no se puede restaurar
+	_, err = repo.Restore(ctx, books[1].ID)
+	assert.ErrorIs(t, err, domain.ErrConflict)
+	assert.ErrorIs(t, err, domain.ErrDuplicateISBN)
+
+	require.NoError(t, repo.Delete(ctx, reprint.ID))
+	restored, err := repo.Restore(ctx, books[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "El Aleph", restored.Title)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, uint(3), restored.Version)
+
+	found, err := repo.GetByISBN(ctx, books[1].ISBN)
+	require.NoError(t, err)
+	assert.Equal(t, books[1].ID, found.ID)
+	trash, err = repo.ListDeleted(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"El Aleph (reedición)", "El libro de arena"}, titles(trash))
+
+	// Solo se restaura lo que está en la papelera
+	_, err = repo.Restore(ctx, books[1].ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = repo.Restore(ctx, 9999)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func testPurge(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t)
+	books := seed(t, repo)
+	require.NoError(t, repo.Delete(ctx, books[0].ID))
+	require.NoError(t, repo.Delete(ctx, books[1].ID))
+
+	// Borrados hace menos de una hora: todavía no se eliminan
+	n, err := repo.Purge(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	// Solo se eliminan los de la papelera, no los activos
+	n, err = repo.Purge(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	trash, err := repo.ListDeleted(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+	_, err = repo.Restore(ctx, books[0].ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	all, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, len(books)-2)
+}
+
+func testFilters(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t)
+	seed(t, repo)
+
+	cases := []struct {
+		name   string
+		filter domain.BookFilter
+		want   []string
+	}{
+		{"empty", domain.BookFilter{}, sortedTitles(catalog())},
+		{"title folded", domain.BookFilter{Title: strPtr("PARAMO")}, []string{"Pedro Páramo"}},
+		{"author contains", domain.BookFilter{Author: strPtr("garcía")}, []string{"Cien años de soledad"}},
+		{"genre folded", domain.BookFilter{Genre: strPtr("NOVELA")}, []string{"Cien años de soledad", "Pedro Páramo", "Rayuela"}},
+		{"like wildcards are literal", domain.BookFilter{Title: strPtr("%")}, []string{}},
+		{"year", domain.BookFilter{Year: uintPtr(1949)}, []string{"El Aleph"}},
+		{"year range", domain.BookFilter{YearFrom: uintPtr(1950), YearTo: uintPtr(1963)}, []string{"Otras inquisiciones", "Pedro Páramo", "Rayuela"}},
+		{"genres", domain.BookFilter{Genres: []string{"ensayo", "Novela"}}, []string{"Cien años de soledad", "Otras inquisiciones", "Pedro Páramo", "Rayuela"}},
+		{"isbn prefix", domain.BookFilter{ISBNPrefix: strPtr("978-84-206")}, []string{"El Aleph", "El libro de arena", "Ficciones", "Otras inquisiciones"}},
+		{"created window", domain.BookFilter{CreatedAfter: timePtr(base.Add(time.Hour)), CreatedBefore: timePtr(base.Add(4 * time.Hour))},
+			[]string{"Cien años de soledad", "El libro de arena"}},
+		{"updated window", domain.BookFilter{UpdatedSince: timePtr(base.Add(5 * time.Hour)), UpdatedBefore: timePtr(base.Add(6 * time.Hour))},
+			[]string{"Otras inquisiciones"}},
+		{"query field and", domain.BookFilter{Query: "author:borges genre=cuento year>=1949"}, []string{"El Aleph", "El libro de arena"}},
+		{"query or and not", domain.BookFilter{Query: "(genre:ensayo OR genre:novela) -author:rulfo"},
+			[]string{"Cien años de soledad", "Otras inquisiciones", "Rayuela"}},
+		{"query exact", domain.BookFilter{Query: "title=aleph"}, []string{}},
+		{"query isbn", domain.BookFilter{Query: "isbn:0307 OR isbn=9788437604572"}, []string{"Pedro Páramo", "Rayuela"}},
+		{"query text prefix", domain.BookFilter{Query: "borg inquis"}, []string{"Otras inquisiciones"}},
+		{"query phrase", domain.BookFilter{Query: `"libro de"`}, []string{"El libro de arena"}},
+		{"query negated text", domain.BookFilter{Query: "borges -cuento"}, []string{"Otras inquisiciones"}},
+		{"query combined with filter", domain.BookFilter{Query: "novela", YearFrom: uintPtr(1960)}, []string{"Cien años de soledad", "Rayuela"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f := c.filter
+			f.Limit = domain.MaxPageLimit
+			page, err := repo.FindByFilter(ctx, f)
+			require.NoError(t, err)
+			assert.Equal(t, c.want, sortedTitles(page.Books))
+			assert.Equal(t, len(c.want), page.Total)
+		})
+	}
+}
+
+func testSorting(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t)
+	seed(t, repo)
+
+	cases := []struct {
+		sort string
+		want []string
+	}{
+		{"", []string{"Ficciones", "El Aleph", "El libro de arena", "Cien años de soledad", "Rayuela", "Otras inquisiciones", "Pedro Páramo"}},
+		{"-year", []string{"El libro de arena", "Cien años de soledad", "Rayuela", "Pedro Páramo", "Otras inquisiciones", "El Aleph", "Ficciones"}},
+		{"genre,-title", []string{"Ficciones", "El libro de arena", "El Aleph", "Otras inquisiciones", "Rayuela", "Cien años de soledad", "Pedro Páramo"}},
+		{"-created_at", []string{"Pedro Páramo", "Otras inquisiciones", "Rayuela", "Cien años de soledad", "El libro de arena", "El Aleph", "Ficciones"}},
+	}
+	for _, c := range cases {
+		t.Run(c.sort, func(t *testing.T) {
+			sortFields, err := domain.ParseSort(c.sort)
+			require.NoError(t, err)
+			page, err := repo.FindByFilter(ctx, domain.BookFilter{Sort: sortFields, Limit: domain.MaxPageLimit})
+			require.NoError(t, err)
+			assert.Equal(t, c.want, titles(page.Books))
+		})
+	}
+}
+
+func testPagination(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t)
+	seed(t, repo)
+	sortFields, _ := domain.ParseSort("author,-year")
+
+	full, err := repo.FindByFilter(ctx, domain.BookFilter{Sort: sortFields, Limit: domain.MaxPageLimit})
+	require.NoError(t, err)
+
+	// Por cursor: las páginas concatenadas dan el listado completo, sin repetir
+	collected := []*domain.Book{}
+	filter := domain.BookFilter{Sort: sortFields, Limit: 3}
+	for pages := 0; pages < 10; pages++ {
+		page, err := repo.FindByFilter(ctx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, len(full.Books), page.Total)
+		collected = append(collected, page.Books...)
+		if !page.HasMore {
+			assert.Empty(t, page.NextCursor)
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+	assert.Equal(t, titles(full.Books), titles(collected))
+
+	// Por offset
+	page, err := repo.FindByFilter(ctx, domain.BookFilter{Sort: sortFields, Limit: 2, Offset: 5})
+	require.NoError(t, err)
+	assert.Equal(t, titles(full.Books[5:7]), titles(page.Books))
+	assert.False(t, page.HasMore)
+
+	page, err = repo.FindByFilter(ctx, domain.BookFilter{Sort: sortFields, Limit: 2, Offset: 50})
+	require.NoError(t, err)
+	assert.Empty(t, page.Books)
+}
+
+func testRelevance(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t)
+	seed(t, repo)
+
+	// Sin orden explícito, primero los que coinciden con el texto; con OR pueden
+	// aparecer libros sin coincidencia de texto, con relevancia 0 y sin resaltado
+	page, err := repo.FindByFilter(ctx, domain.BookFilter{Query: "soledad OR author:rulfo", Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+	require.Len(t, page.Books, 1)
+	first := page.Books[0]
+	assert.Equal(t, "Cien años de soledad", first.Title)
+	assert.Less(t, page.Matches[first.ID].Score, 0.0)
+	assert.Equal(t, "Cien años de <mark>soledad</mark>", page.Matches[first.ID].Highlights["title"])
+
+	filter := domain.BookFilter{Query: "soledad OR author:rulfo", Limit: 1, Cursor: page.NextCursor}
+	page, err = repo.FindByFilter(ctx, filter)
+	require.NoError(t, err)
+	require.Len(t, page.Books, 1)
+	assert.Equal(t, "Pedro Páramo", page.Books[0].Title)
+	assert.Equal(t, 0.0, page.Matches[page.Books[0].ID].Score)
+	assert.Empty(t, page.Matches[page.Books[0].ID].Highlights)
+
+	// Sin texto libre no hay relevancia
+	page, err = repo.FindByFilter(ctx, domain.BookFilter{Query: "author:rulfo", Limit: 10})
+	require.NoError(t, err)
+	assert.Nil(t, page.Matches)
+
+	// Un título con dos puntos se busca como texto libre
+	potter := &domain.Book{Title: "Harry Potter: la piedra filosofal", Author: "J. K. Rowling", Year: 1997,
+		Genre: "Novela", ISBN: "9788478884452", CreatedAt: base, UpdatedAt: base}
+	require.NoError(t, repo.Create(ctx, potter))
+	page, err = repo.FindByFilter(ctx, domain.BookFilter{Query: "Harry Potter: la piedra", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []string{potter.Title}, titles(page.Books))
+}
+
+func testFacets(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t)
+	seed(t, repo)
+
+	facets, err := repo.CountFacets(ctx, domain.BookFilter{})
+	require.NoError(t, err)
+	// "Novela" y "novela" son el mismo género; se muestra la forma menor en bytes
+	assert.Equal(t, map[string]int{"Cuento": 3, "Novela": 3, "Ensayo": 1}, facets.Genre)
+	assert.Equal(t, map[string]int{"1940": 2, "1950": 2, "1960": 2, "1970": 1}, facets.Decade)
+	assert.Equal(t, 4, facets.Author["Jorge Luis Borges"])
+
+	facets, err = repo.CountFacets(ctx, domain.BookFilter{Query: "author:borges", YearFrom: uintPtr(1950)})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"Cuento": 1, "Ensayo": 1}, facets.Genre)
+	assert.Equal(t, map[string]int{"Jorge Luis Borges": 2}, facets.Author)
+}
+
+func testSuggestAndCandidates(t *testing.T, newRepo Factory) {
+	ctx := context.Background()
+	repo := newRepo(t)
+	seed(t, repo)
+
+	// Por prefijo del valor completo o de cualquier palabra, los más frecuentes primero
+	got, err := repo.SuggestByPrefix(ctx, "author", "ju", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ValueCount{{Value: "Juan Rulfo", Count: 1}, {Value: "Julio Cortázar", Count: 1}}, got)
+
+	got, err = repo.SuggestByPrefix(ctx, "author", "LUIS", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.ValueCount{{Value: "Jorge Luis Borges", Count: 4}}, got)
+
+	got, err = repo.SuggestByPrefix(ctx, "title", "el", 1)
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+
+	got, err = repo.SuggestByPrefix(ctx, "title", "  ", 10)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = repo.SuggestByPrefix(ctx, "isbn", "978", 10)
+	assert.Error(t, err)
+
+	// Candidatos a sugerencia: alguna palabra empieza por alguno de los prefijos (ya plegados)
+	candidates, err := repo.SuggestionCandidates(ctx, "author", []string{"bo", "ma"}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Jorge Luis Borges", "Gabriel García Márquez"}, candidates)
+
+	candidates, err = repo.SuggestionCandidates(ctx, "title", []string{"pa"}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Pedro Páramo"}, candidates)
+
+	candidates, err = repo.SuggestionCandidates(ctx, "author", []string{"ju"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Juan Rulfo"}, candidates)
+
+	candidates, err = repo.SuggestionCandidates(ctx, "author", nil, 10)
+	require.NoError(t, err)
+	assert.Empty(t, candidates)
+
+	_, err = repo.SuggestionCandidates(ctx, "isbn", []string{"97"}, 10)
+	assert.Error(t, err)
+}
@@ -0,0 +1,91 @@
+package infrastructure
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"api-go-gestion-libros-hexagonal/shared/database"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// SqlRevisionRepository guarda el historial de los libros en la tabla book_revisions,
+// con los cambios como JSON. El SQL es el mismo para SQLite/Turso y Postgres salvo
+// los placeholders, así que un solo adaptador sirve para ambos.
+type SqlRevisionRepository struct {
+	db     *sql.DB
+	rebind func(string) string
+}
+
+// NewSqlRevisionRepository crea el repositorio para SQLite y Turso
+func NewSqlRevisionRepository(db *sql.DB) *SqlRevisionRepository {
+	return &SqlRevisionRepository{db: db, rebind: func(q string) string { return q }}
+}
+
+// NewPostgresRevisionRepository crea el repositorio para PostgreSQL ($n en lugar de ?)
+func NewPostgresRevisionRepository(db *sql.DB) *SqlRevisionRepository {
+	return &SqlRevisionRepository{db: db, rebind: database.Rebind}
+}
+
+// conn devuelve la transacción abierta por database.TxManager, si ctx trae una
+func (r *SqlRevisionRepository) conn(ctx context.Context) database.Conn {
+	return database.ConnFrom(ctx, r.db)
+}
+
+// Append guarda rev con el siguiente número de su libro. Corre en la transacción del
+// cambio del libro, que ya bloqueó la fila, así dos cambios no toman el mismo número;
+// el índice UNIQUE (book_id, number) lo garantiza igual.
+func (r *SqlRevisionRepository) Append(ctx context.Context, rev *domain.Revision) error {
+	changes, err := json.Marshal(rev.Changes)
+	if err != nil {
+		return err
+	}
+	var number int64
+	q := `SELECT COALESCE(MAX(number), 0) + 1 FROM book_revisions WHERE book_id = ?`
+	if err := r.conn(ctx).QueryRowContext(ctx, r.rebind(q), int64(rev.BookID)).Scan(&number); err != nil {
+		return err
+	}
+
+	q = `INSERT INTO book_revisions (book_id, number, action, actor, changes, reverted_to, created_at)
+	     VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`
+	var id int64
+	err = r.conn(ctx).QueryRowContext(ctx, r.rebind(q),
+		int64(rev.BookID), number, rev.Action, rev.Actor, string(changes), int64(rev.RevertedTo), rev.CreatedAt.UTC(),
+	).Scan(&id)
+	if err != nil {
+		return err
+	}
+	rev.ID, rev.Number = uint(id), uint(number)
+	return nil
+}
+
+// ListByBook obtiene las revisiones de un libro, de la más vieja a la más nueva
+func (r *SqlRevisionRepository) ListByBook(ctx context.Context, bookID uint) ([]*domain.Revision, error) {
+	q := `SELECT id, book_id, number, action, actor, changes, reverted_to, created_at
+	      FROM book_revisions WHERE book_id = ? ORDER BY number`
+	rows, err := r.conn(ctx).QueryContext(ctx, r.rebind(q), int64(bookID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []*domain.Revision{}
+	for rows.Next() {
+		var (
+			rev                          domain.Revision
+			id, book, number, revertedTo int64
+			changes                      string
+			createdAt                    time.Time
+		)
+		if err := rows.Scan(&id, &book, &number, &rev.Action, &rev.Actor, &changes, &revertedTo, &createdAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(changes), &rev.Changes); err != nil {
+			return nil, err
+		}
+		rev.ID, rev.BookID, rev.Number, rev.RevertedTo = uint(id), uint(book), uint(number), uint(revertedTo)
+		rev.CreatedAt = createdAt.UTC()
+		out = append(out, &rev)
+	}
+	return out, rows.Err()
+}
@@ -2,13 +2,24 @@ package infrastructure
 
 import (
 	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"api-go-gestion-libros-hexagonal/shared/database"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 )
 
+// bookColumns son las columnas de books (alias b) en el orden que esperan scanBook/scanBooks
+const bookColumns = "b.id, b.title, b.author, b.year, b.genre, b.isbn, b.created_at, b.updated_at, b.version, b.deleted_at"
+
+// Marcas con las que FTS5 resalta los términos encontrados
+const (
+	highlightOpen  = "<mark>"
+	highlightClose = "</mark>"
+)
+
 type SqlBookRepository struct {
 	db *sql.DB
 }
@@ -17,81 +28,135 @@ func NewSqlBookRepository(db *sql.DB) *SqlBookRepository {
 	return &SqlBookRepository{db: db}
 }
 
+// conn devuelve la transacción abierta por database.TxManager, si ctx trae una
+func (r *SqlBookRepository) conn(ctx context.Context) database.Conn {
+	return database.ConnFrom(ctx, r.db)
+}
+
 // Create crea un nuevo libro en el repositorio
 func (r *SqlBookRepository) Create(ctx context.Context, book *domain.Book) error {
 	isbn := domain.NormalizeISBN(book.ISBN)
-	q := `INSERT INTO books (title, author, year, genre, isbn, created_at, updated_at)
-	      VALUES (?, ?, ?, ?, ?, ?, ?)`
-	_, err := r.db.ExecContext(ctx, q,
+	q := `INSERT INTO books (title, author, year, genre, isbn, created_at, updated_at, title_norm, author_norm, genre_norm)
+	      VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id, version`
+	var id, version int64
+	err := r.conn(ctx).QueryRowContext(ctx, q,
 		strings.TrimSpace(book.Title),
 		strings.TrimSpace(book.Author),
 		book.Year,
 		strings.TrimSpace(book.Genre),
 		isbn,
-		book.CreatedAt,
-		book.UpdatedAt,
-	)
+		// Siempre en UTC: las ventanas de tiempo comparan el texto guardado
+		book.CreatedAt.UTC(),
+		book.UpdatedAt.UTC(),
+		domain.FoldText(book.Title),
+		domain.FoldText(book.Author),
+		domain.FoldText(book.Genre),
+	).Scan(&id, &version)
 	if err != nil {
 		if isUniqueViolation(err) {
-			return fmt.Errorf("duplicate isbn: %s", isbn)
+			return fmt.Errorf("%w: %s", domain.ErrDuplicateISBN, isbn)
 		}
 		return err
 	}
-
-	// Recuperar ID asignado por ISBN
-	row := r.db.QueryRowContext(ctx, "SELECT id FROM books WHERE isbn = ?", isbn)
-	var id int64
-	if err := row.Scan(&id); err != nil {
-		return err
-	}
 	book.ID = uint(id)
+	book.Version = uint(version)
 	return nil
 }
 
-// Update actualiza un libro existente en el repositorio
+// Update actualiza un libro existente en el repositorio si sigue en book.Version,
+// y sube la versión. Si otro lo cambió antes devuelve domain.ErrVersionMismatch.
 func (r *SqlBookRepository) Update(ctx context.Context, book *domain.Book) (*domain.Book, error) {
 	isbn := domain.NormalizeISBN(book.ISBN)
 	q := `UPDATE books
-	      SET title = ?, author = ?, year = ?, genre = ?, isbn = ?, updated_at = ?
-	      WHERE id = ?`
-	res, err := r.db.ExecContext(ctx, q,
+	      SET title = ?, author = ?, year = ?, genre = ?, isbn = ?, updated_at = ?,
+	          title_norm = ?, author_norm = ?, genre_norm = ?, version = version + 1
+	      WHERE id = ? AND version = ? AND deleted_at IS NULL`
+	res, err := r.conn(ctx).ExecContext(ctx, q,
 		strings.TrimSpace(book.Title),
 		strings.TrimSpace(book.Author),
 		int(book.Year),
 		strings.TrimSpace(book.Genre),
 		isbn,
 		time.Now().UTC(),
+		domain.FoldText(book.Title),
+		domain.FoldText(book.Author),
+		domain.FoldText(book.Genre),
 		int(book.ID),
+		int(book.Version),
 	)
 	if err != nil {
 		if isUniqueViolation(err) {
-			return nil, fmt.Errorf("duplicate isbn: %s", isbn)
+			return nil, fmt.Errorf("%w: %s", domain.ErrDuplicateISBN, isbn)
 		}
 		return nil, err
 	}
 	if n, _ := res.RowsAffected(); n == 0 {
-		return nil, fmt.Errorf("book %d not found", book.ID)
+		// No existe (ErrNotFound) o cambió de versión
+		current, err := r.GetByID(ctx, book.ID)
+		if err != nil {
+			return nil, err
+		}
+		return nil, versionMismatch(book, current.Version)
 	}
 	return r.GetByID(ctx, book.ID)
 }
 
-// Delete elimina un libro existente en el repositorio
+// Delete mueve un libro a la papelera: marca deleted_at y sube la versión
 func (r *SqlBookRepository) Delete(ctx context.Context, id uint) error {
-	q := `DELETE FROM books WHERE id = ?`
-	res, err := r.db.ExecContext(ctx, q, int(id))
+	q := `UPDATE books SET deleted_at = ?, version = version + 1 WHERE id = ? AND deleted_at IS NULL`
+	res, err := r.conn(ctx).ExecContext(ctx, q, time.Now().UTC(), int(id))
 	if err != nil {
 		return err
 	}
 	if n, _ := res.RowsAffected(); n == 0 {
-		return fmt.Errorf("book %d not found", id)
+		return fmt.Errorf("book %d %w", id, domain.ErrNotFound)
 	}
 	return nil
 }
 
+// ListDeleted obtiene los libros de la papelera, del borrado más reciente al más viejo
+func (r *SqlBookRepository) ListDeleted(ctx context.Context) ([]*domain.Book, error) {
+	q := "SELECT " + bookColumns + " FROM books b WHERE b.deleted_at IS NOT NULL ORDER BY b.deleted_at DESC, b.id DESC"
+	rows, err := r.conn(ctx).QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanBooks(rows)
+}
+
+// Restore saca un libro de la papelera y sube su versión. Si otro libro activo tomó
+// su ISBN, el índice único parcial lo rechaza: ver restoreConflict.
+func (r *SqlBookRepository) Restore(ctx context.Context, id uint) (*domain.Book, error) {
+	q := `UPDATE books SET deleted_at = NULL, updated_at = ?, version = version + 1 WHERE id = ? AND deleted_at IS NOT NULL`
+	res, err := r.conn(ctx).ExecContext(ctx, q, time.Now().UTC(), int(id))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, restoreConflict(id)
+		}
+		return nil, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, fmt.Errorf("book %d %w in trash", id, domain.ErrNotFound)
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Purge elimina definitivamente los libros que están en la papelera desde antes de deletedBefore
+func (r *SqlBookRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	q := `DELETE FROM books WHERE deleted_at IS NOT NULL AND deleted_at < ?`
+	res, err := r.conn(ctx).ExecContext(ctx, q, deletedBefore.UTC())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
 // GetAll obtiene todos los libros del repositorio
 func (r *SqlBookRepository) GetAll(ctx context.Context) ([]*domain.Book, error) {
-	q := `SELECT id, title, author, year, genre, isbn, created_at, updated_at FROM books ORDER BY id`
-	rows, err := r.db.QueryContext(ctx, q)
+	q := "SELECT " + bookColumns + " FROM books b WHERE b.deleted_at IS NULL ORDER BY b.id"
+	rows, err := r.conn(ctx).QueryContext(ctx, q)
 	if err != nil {
 		return nil, err
 	}
@@ -102,27 +167,131 @@ func (r *SqlBookRepository) GetAll(ctx context.Context) ([]*domain.Book, error)
 // GetByISBN obtiene un libro por ISBN del repositorio
 func (r *SqlBookRepository) GetByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
 	n := domain.NormalizeISBN(isbn)
-	q := `SELECT id, title, author, year, genre, isbn, created_at, updated_at FROM books WHERE isbn = ?`
-	row := r.db.QueryRowContext(ctx, q, n)
-	return scanBook(row)
+	q := "SELECT " + bookColumns + " FROM books b WHERE b.isbn = ? AND b.deleted_at IS NULL"
+	row := r.conn(ctx).QueryRowContext(ctx, q, n)
+	book, err := scanBook(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("book with isbn %s %w", isbn, domain.ErrNotFound)
+	}
+	return book, err
 }
 
 // GetByID obtiene un libro por ID
 func (r *SqlBookRepository) GetByID(ctx context.Context, id uint) (*domain.Book, error) {
-	q := `SELECT id, title, author, year, genre, isbn, created_at, updated_at FROM books WHERE id = ?`
-	row := r.db.QueryRowContext(ctx, q, int(id))
-	return scanBook(row)
+	q := "SELECT " + bookColumns + " FROM books b WHERE b.id = ? AND b.deleted_at IS NULL"
+	row := r.conn(ctx).QueryRowContext(ctx, q, int(id))
+	book, err := scanBook(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("book %d %w", id, domain.ErrNotFound)
+	}
+	return book, err
+}
+
+// FindByFilter obtiene una página de libros por filtros del repositorio.
+// filter.Query se compila a SQL (ver compileQuery); su texto libre se busca en el
+// índice FTS5 y, sin orden explícito, se ordena por relevancia (bm25).
+func (r *SqlBookRepository) FindByFilter(ctx context.Context, filter domain.BookFilter) (*domain.BookPage, error) {
+	query, err := domain.ParseQuery(filter.Query)
+	if err != nil {
+		return nil, err
+	}
+	clauses, args := filterClauses(filter, query)
+
+	from, fromArgs := fromClause(query)
+	ranked := len(fromArgs) > 0
+
+	where := ""
+	if len(clauses) > 0 {
+		where = " WHERE " + strings.Join(clauses, " AND ")
+	}
+
+	// Total sin paginar
+	var total int
+	countArgs := append(append([]any{}, fromArgs...), args...)
+	if err := r.conn(ctx).QueryRowContext(ctx, "SELECT COUNT(*)"+from+where, countArgs...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	// Keyset: continuar después del último libro entregado, según el orden pedido
+	sort := filter.EffectiveSort()
+	keys := domain.StableSort(sort)
+	if filter.Cursor != "" {
+		cur, err := domain.DecodeCursor(filter.Cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+		clause, keyArgs := keysetClause(keys, cur.Values, sortColumn)
+		clauses = append(clauses, clause)
+		args = append(args, keyArgs...)
+		where = " WHERE " + strings.Join(clauses, " AND ")
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = domain.DefaultPageLimit
+	}
+
+	// Pedimos uno más para saber si hay otra página
+	cols := bookColumns
+	if ranked {
+		cols += ", COALESCE(h.score, 0), COALESCE(h.hl_title, ''), COALESCE(h.hl_author, ''), COALESCE(h.hl_genre, '')"
+	}
+	q := "SELECT " + cols + from + where + orderByClause(keys, sortColumn) + " LIMIT ? OFFSET ?"
+	args = append(append(fromArgs, args...), limit+1, filter.Offset)
+
+	rows, err := r.conn(ctx).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !ranked {
+		books, err := scanBooks(rows)
+		if err != nil {
+			return nil, err
+		}
+		return newBookPage(books, nil, total, limit, sort), nil
+	}
+	books, matches, err := scanRankedBooks(rows)
+	if err != nil {
+		return nil, err
+	}
+	return newBookPage(books, matches, total, limit, sort), nil
+}
+
+// fromClause devuelve el FROM de una búsqueda: books b y, si la consulta tiene
+// texto libre, el join con el índice FTS5 (alias h) que aporta relevancia y resaltado.
+// Es LEFT JOIN porque con OR un libro puede cumplir la consulta sin coincidir en texto.
+func fromClause(query domain.QueryNode) (string, []any) {
+	ranking := domain.RankingTerms(query)
+	if len(ranking) == 0 {
+		return " FROM books b", nil
+	}
+	// El MATCH va en una subconsulta para poder usar bm25/highlight y
+	// seguir filtrando y ordenando sobre las columnas de books
+	from := ` FROM books b LEFT JOIN (
+		SELECT rowid AS fts_id, bm25(books_fts) AS score,
+		       highlight(books_fts, 0, '` + highlightOpen + `', '` + highlightClose + `') AS hl_title,
+		       highlight(books_fts, 1, '` + highlightOpen + `', '` + highlightClose + `') AS hl_author,
+		       highlight(books_fts, 2, '` + highlightOpen + `', '` + highlightClose + `') AS hl_genre
+		FROM books_fts WHERE books_fts MATCH ?
+	) h ON h.fts_id = b.id`
+	return from, []any{ftsRankingExpr(ranking)}
 }
 
-// FindByFilter obtiene libros por filtros del repositorio
-func (r *SqlBookRepository) FindByFilter(ctx context.Context, filter domain.BookFilter) ([]*domain.Book, error) {
-	clauses := []string{}
+// filterClauses traduce los criterios del filtro y la consulta avanzada ya parseada
+// a condiciones SQL parametrizadas sobre books b
+func filterClauses(filter domain.BookFilter, query domain.QueryNode) ([]string, []any) {
+	// Los libros de la papelera no aparecen en ninguna búsqueda
+	clauses := []string{"b.deleted_at IS NULL"}
 	args := []any{}
 
+	// Las columnas *_norm guardan el texto plegado (sin tildes, en minúsculas),
+	// así "ficcion" encuentra "Ficción" y "GARCÍA" encuentra "García"
 	addLike := func(col string, p *string) {
 		if p != nil {
-			needle := "%" + strings.ToLower(strings.TrimSpace(*p)) + "%"
-			clauses = append(clauses, fmt.Sprintf("LOWER(%s) LIKE ?", col))
+			needle := "%" + escapeLike(domain.FoldText(*p)) + "%"
+			clauses = append(clauses, fmt.Sprintf("%s LIKE ? ESCAPE '\\'", col))
 			args = append(args, needle)
 		}
 	}
@@ -132,28 +301,236 @@ func (r *SqlBookRepository) FindByFilter(ctx context.Context, filter domain.Book
 			args = append(args, int(*p))
 		}
 	}
+	addCmpUint := func(col, op string, p *uint) {
+		if p != nil {
+			clauses = append(clauses, fmt.Sprintf("%s %s ?", col, op))
+			args = append(args, int(*p))
+		}
+	}
+	addInFolded := func(col string, values []string) {
+		if len(values) > 0 {
+			marks := make([]string, len(values))
+			for i, v := range values {
+				marks[i] = "?"
+				args = append(args, domain.FoldText(v))
+			}
+			clauses = append(clauses, fmt.Sprintf("%s IN (%s)", col, strings.Join(marks, ", ")))
+		}
+	}
+	addCmpTime := func(col, op string, p *time.Time) {
+		if p != nil {
+			clauses = append(clauses, fmt.Sprintf("%s %s ?", col, op))
+			args = append(args, p.UTC())
+		}
+	}
+
+	addLike("b.title_norm", filter.Title)
+	addLike("b.author_norm", filter.Author)
+	addEqUint("b.year", filter.Year)
+	addLike("b.genre_norm", filter.Genre)
+	addCmpUint("b.year", ">=", filter.YearFrom)
+	addCmpUint("b.year", "<=", filter.YearTo)
+	addInFolded("b.genre_norm", filter.Genres)
+	if filter.ISBNPrefix != nil {
+		// El dominio garantiza que el prefijo solo tiene dígitos o X, sin comodines
+		clauses = append(clauses, "b.isbn LIKE ?")
+		args = append(args, domain.NormalizeISBN(*filter.ISBNPrefix)+"%")
+	}
+	addCmpTime("b.created_at", ">", filter.CreatedAfter)
+	addCmpTime("b.created_at", "<", filter.CreatedBefore)
+	addCmpTime("b.updated_at", ">=", filter.UpdatedSince)
+	addCmpTime("b.updated_at", "<", filter.UpdatedBefore)
 
-	addLike("title", filter.Title)
-	addLike("author", filter.Author)
-	addEqUint("year", filter.Year)
-	addLike("genre", filter.Genre)
+	if query != nil {
+		clause, queryArgs := compileQuery(query)
+		clauses = append(clauses, clause)
+		args = append(args, queryArgs...)
+	}
+
+	return clauses, args
+}
+
+// ftsMatchExpr arma la expresión MATCH de FTS5: cada término entre comillas
+// (sin operadores del usuario) y con * para coincidir por prefijo
+func ftsMatchExpr(terms []string) string {
+	parts := make([]string, len(terms))
+	for i, t := range terms {
+		parts[i] = `"` + strings.ReplaceAll(t, `"`, `""`) + `"*`
+	}
+	return strings.Join(parts, " ")
+}
 
-	q := `SELECT id, title, author, year, genre, isbn, created_at, updated_at FROM books`
+// CountFacets cuenta los libros que cumplen el filtro por género, década y autor en una sola consulta
+func (r *SqlBookRepository) CountFacets(ctx context.Context, filter domain.BookFilter) (*domain.BookFacets, error) {
+	query, err := domain.ParseQuery(filter.Query)
+	if err != nil {
+		return nil, err
+	}
+	clauses, args := filterClauses(filter, query)
+	from, fromArgs := fromClause(query)
+	where := ""
 	if len(clauses) > 0 {
-		q += " WHERE " + strings.Join(clauses, " AND ")
+		where = " WHERE " + strings.Join(clauses, " AND ")
+	}
+	base := append(append([]any{}, fromArgs...), args...)
+
+	// Género y autor se agrupan por su forma normalizada para no separar "Ficción" de "ficcion"
+	facet := func(name, label, group string, limit int) string {
+		q := fmt.Sprintf("SELECT '%s' AS facet, %s AS value, COUNT(*) AS n%s%s GROUP BY %s ORDER BY n DESC, value", name, label, from, where, group)
+		if limit > 0 {
+			q += fmt.Sprintf(" LIMIT %d", limit)
+		}
+		return "SELECT * FROM (" + q + ")"
 	}
-	q += " ORDER BY id"
+	q := strings.Join([]string{
+		facet("genre", "MIN(b.genre)", "b.genre_norm", domain.MaxFacetValues),
+		facet("decade", "CAST((b.year / 10) * 10 AS TEXT)", "(b.year / 10) * 10", 0),
+		facet("author", "MIN(b.author)", "b.author_norm", domain.MaxFacetValues),
+	}, " UNION ALL ")
+	allArgs := append(append(append([]any{}, base...), base...), base...)
 
-	rows, err := r.db.QueryContext(ctx, q, args...)
+	rows, err := r.conn(ctx).QueryContext(ctx, q, allArgs...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	return scanBooks(rows)
+
+	facets := domain.NewBookFacets()
+	for rows.Next() {
+		var (
+			name, label string
+			n           int
+		)
+		if err := rows.Scan(&name, &label, &n); err != nil {
+			return nil, err
+		}
+		switch name {
+		case "genre":
+			facets.Genre[label] = n
+		case "decade":
+			facets.Decade[label] = n
+		case "author":
+			facets.Author[label] = n
+		}
+	}
+	return facets, rows.Err()
+}
+
+// SuggestByPrefix obtiene los valores de title o author que empiezan por el prefijo, por frecuencia.
+// El valor completo se busca por rango sobre el índice de la columna *_norm y las palabras
+// sueltas ("gar" -> "Gabriel García Márquez") con el índice FTS5 restringido a esa columna.
+func (r *SqlBookRepository) SuggestByPrefix(ctx context.Context, field, prefix string, limit int) ([]domain.ValueCount, error) {
+	if err := domain.ValidateAutocompleteField(field); err != nil {
+		return nil, err
+	}
+	folded := domain.FoldText(prefix)
+	terms := domain.FullTextTerms(folded)
+	if len(terms) == 0 {
+		return []domain.ValueCount{}, nil
+	}
+	norm := "b." + field + "_norm"
+	q := fmt.Sprintf(`SELECT MIN(b.%s) AS value, COUNT(*) AS n FROM books b
+		WHERE b.deleted_at IS NULL AND ((%s >= ? AND %s < ?) OR b.id IN (SELECT rowid FROM books_fts WHERE books_fts MATCH ?))
+		GROUP BY %s ORDER BY n DESC, value LIMIT ?`, field, norm, norm, norm)
+	match := field + " : (" + ftsMatchExpr(terms) + ")"
+
+	rows, err := r.conn(ctx).QueryContext(ctx, q, folded, folded+"\U0010FFFF", match, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.ValueCount{}
+	for rows.Next() {
+		var vc domain.ValueCount
+		if err := rows.Scan(&vc.Value, &vc.Count); err != nil {
+			return nil, err
+		}
+		out = append(out, vc)
+	}
+	return out, rows.Err()
+}
+
+// SuggestionCandidates obtiene los candidatos a sugerencia con el índice FTS5 restringido
+// al campo: palabras que empiezan por alguno de los prefijos, agrupadas como en SuggestByPrefix.
+func (r *SqlBookRepository) SuggestionCandidates(ctx context.Context, field string, prefixes []string, limit int) ([]string, error) {
+	if err := domain.ValidateAutocompleteField(field); err != nil {
+		return nil, err
+	}
+	if len(prefixes) == 0 {
+		return []string{}, nil
+	}
+	parts := make([]string, len(prefixes))
+	for i, p := range prefixes {
+		parts[i] = ftsMatchExpr([]string{p})
+	}
+	match := field + " : (" + strings.Join(parts, " OR ") + ")"
+	norm := "b." + field + "_norm"
+	q := fmt.Sprintf(`SELECT MIN(b.%s) AS value FROM books b
+		WHERE b.deleted_at IS NULL AND b.id IN (SELECT rowid FROM books_fts WHERE books_fts MATCH ?)
+		GROUP BY %s ORDER BY COUNT(*) DESC, value LIMIT ?`, field, norm)
+	return queryStrings(ctx, r.conn(ctx), q, match, limit)
+}
+
+// queryStrings corre una consulta de una sola columna de texto
+func queryStrings(ctx context.Context, conn database.Conn, q string, args ...any) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []string{}
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// BackfillNormalized rellena las columnas *_norm de libros creados antes de que existieran.
+// Es idempotente: solo toca filas cuyo valor normalizado falta.
+func (r *SqlBookRepository) BackfillNormalized(ctx context.Context) (int, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, `SELECT id, title, author, genre FROM books
+		WHERE (title_norm = '' AND title <> '') OR (author_norm = '' AND author <> '') OR (genre_norm = '' AND genre <> '')`)
+	if err != nil {
+		return 0, err
+	}
+	type pending struct {
+		id                   int64
+		title, author, genre string
+	}
+	todo := []pending{}
+	for rows.Next() {
+		var p pending
+		if err := rows.Scan(&p.id, &p.title, &p.author, &p.genre); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		todo = append(todo, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	q := `UPDATE books SET title_norm = ?, author_norm = ?, genre_norm = ? WHERE id = ?`
+	for _, p := range todo {
+		if _, err := r.conn(ctx).ExecContext(ctx, q, domain.FoldText(p.title), domain.FoldText(p.author), domain.FoldText(p.genre), p.id); err != nil {
+			return 0, err
+		}
+	}
+	return len(todo), nil
 }
 
 // Helpers
 
+// escapeLike escapa los comodines de LIKE para buscar el texto tal cual
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
 func scanBook(row *sql.Row) (*domain.Book, error) {
 	var (
 		id        int64
@@ -164,8 +541,10 @@ func scanBook(row *sql.Row) (*domain.Book, error) {
 		isbn      string
 		createdAt time.Time
 		updatedAt time.Time
+		version   int64
+		deletedAt sql.NullTime
 	)
-	if err := row.Scan(&id, &title, &author, &year, &genre, &isbn, &createdAt, &updatedAt); err != nil {
+	if err := row.Scan(&id, &title, &author, &year, &genre, &isbn, &createdAt, &updatedAt, &version, &deletedAt); err != nil {
 		return nil, err
 	}
 	return &domain.Book{
@@ -177,6 +556,8 @@ func scanBook(row *sql.Row) (*domain.Book, error) {
 		ISBN:      isbn,
 		CreatedAt: createdAt.UTC(),
 		UpdatedAt: updatedAt.UTC(),
+		Version:   uint(version),
+		DeletedAt: nullTime(deletedAt),
 	}, nil
 }
 
@@ -192,8 +573,10 @@ func scanBooks(rows *sql.Rows) ([]*domain.Book, error) {
 			isbn      string
 			createdAt time.Time
 			updatedAt time.Time
+			version   int64
+			deletedAt sql.NullTime
 		)
-		if err := rows.Scan(&id, &title, &author, &year, &genre, &isbn, &createdAt, &updatedAt); err != nil {
+		if err := rows.Scan(&id, &title, &author, &year, &genre, &isbn, &createdAt, &updatedAt, &version, &deletedAt); err != nil {
 			return nil, err
 		}
 		out = append(out, &domain.Book{
@@ -205,6 +588,8 @@ func scanBooks(rows *sql.Rows) ([]*domain.Book, error) {
 			ISBN:      isbn,
 			CreatedAt: createdAt.UTC(),
 			UpdatedAt: updatedAt.UTC(),
+			Version:   uint(version),
+			DeletedAt: nullTime(deletedAt),
 		})
 	}
 	if err := rows.Err(); err != nil {
@@ -213,6 +598,121 @@ func scanBooks(rows *sql.Rows) ([]*domain.Book, error) {
 	return out, nil
 }
 
+// sortColumn traduce un campo de orden a su columna; los campos vienen de la lista blanca del dominio
+func sortColumn(field string) string {
+	if field == domain.RelevanceField {
+		// Sin coincidencia de texto (posible con OR) la relevancia es 0, la peor en bm25
+		return "COALESCE(h.score, 0)"
+	}
+	return "b." + field
+}
+
+// orderByClause traduce el orden validado a SQL; column da la expresión de cada campo
+func orderByClause(keys []domain.SortField, column func(field string) string) string {
+	parts := make([]string, len(keys))
+	for i, sf := range keys {
+		if sf.Desc {
+			parts[i] = column(sf.Field) + " DESC"
+		} else {
+			parts[i] = column(sf.Field) + " ASC"
+		}
+	}
+	return " ORDER BY " + strings.Join(parts, ", ")
+}
+
+// keysetClause arma la condición "después del cursor" para un orden multicolumna:
+// (a > ?) OR (a = ? AND b > ?) OR (a = ? AND b = ? AND id > ?) ...
+func keysetClause(keys []domain.SortField, values []any, column func(field string) string) (string, []any) {
+	ors := []string{}
+	args := []any{}
+	for i, sf := range keys {
+		ands := []string{}
+		for j := 0; j < i; j++ {
+			ands = append(ands, column(keys[j].Field)+" = ?")
+			args = append(args, values[j])
+		}
+		op := ">"
+		if sf.Desc {
+			op = "<"
+		}
+		ands = append(ands, fmt.Sprintf("%s %s ?", column(sf.Field), op))
+		args = append(args, values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+// newBookPage recorta el registro extra pedido y calcula el siguiente cursor
+func newBookPage(books []*domain.Book, matches map[uint]domain.BookMatch, total, limit int, sort []domain.SortField) *domain.BookPage {
+	page := &domain.BookPage{Books: books, Matches: matches, Total: total}
+	if len(books) > limit {
+		page.Books = books[:limit]
+		page.HasMore = true
+		last := page.Books[len(page.Books)-1]
+		page.NextCursor = domain.EncodeCursor(domain.NewRankedCursor(last, matches[last.ID].Score, sort))
+	}
+	return page
+}
+
+// scanRankedBooks lee libros junto con la relevancia y los resaltados de FTS5
+func scanRankedBooks(rows *sql.Rows) ([]*domain.Book, map[uint]domain.BookMatch, error) {
+	out := []*domain.Book{}
+	matches := map[uint]domain.BookMatch{}
+	for rows.Next() {
+		var (
+			b                          domain.Book
+			id, year, version          int64
+			createdAt, updatedAt       time.Time
+			deletedAt                  sql.NullTime
+			score                      float64
+			hlTitle, hlAuthor, hlGenre string
+		)
+		if err := rows.Scan(&id, &b.Title, &b.Author, &year, &b.Genre, &b.ISBN, &createdAt, &updatedAt, &version, &deletedAt,
+			&score, &hlTitle, &hlAuthor, &hlGenre); err != nil {
+			return nil, nil, err
+		}
+		b.ID = uint(id)
+		b.Year = uint(year)
+		b.CreatedAt = createdAt.UTC()
+		b.UpdatedAt = updatedAt.UTC()
+		b.Version = uint(version)
+		b.DeletedAt = nullTime(deletedAt)
+		out = append(out, &b)
+
+		highlights := map[string]string{}
+		for field, v := range map[string]string{"title": hlTitle, "author": hlAuthor, "genre": hlGenre} {
+			if strings.Contains(v, highlightOpen) {
+				highlights[field] = v
+			}
+		}
+		matches[b.ID] = domain.BookMatch{Score: score, Highlights: highlights}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return out, matches, nil
+}
+
+// versionMismatch es el error de un Update sobre una versión que ya no es la guardada
+func versionMismatch(book *domain.Book, current uint) error {
+	return fmt.Errorf("book %d %w: expected version %d, current is %d", book.ID, domain.ErrVersionMismatch, book.Version, current)
+}
+
+// restoreConflict es el error de restaurar un libro cuyo ISBN ya usa otro libro activo:
+// choca con el estado actual (domain.ErrConflict) por un ISBN repetido (domain.ErrDuplicateISBN)
+func restoreConflict(id uint) error {
+	return fmt.Errorf("%w: book %d cannot be restored while another book uses its isbn (%w)", domain.ErrConflict, id, domain.ErrDuplicateISBN)
+}
+
+// nullTime convierte una columna de tiempo opcional (deleted_at) a *time.Time en UTC
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	utc := t.Time.UTC()
+	return &utc
+}
+
 func isUniqueViolation(err error) bool {
 	msg := strings.ToLower(err.Error())
 	return strings.Contains(msg, "unique") || strings.Contains(msg, "constraint")
@@ -0,0 +1,20 @@
+package infrastructure_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"api-go-gestion-libros-hexagonal/modules/book/infrastructure"
+	"api-go-gestion-libros-hexagonal/modules/book/infrastructure/repotest"
+	"testing"
+)
+
+func TestSqlBookRepository_Contract(t *testing.T) {
+	repotest.RunBookRepositoryContract(t, func(t *testing.T) domain.BookRepository {
+		return newSQLiteRepository(t)
+	})
+}
+
+func TestMemoryBookRepository_Contract(t *testing.T) {
+	repotest.RunBookRepositoryContract(t, func(t *testing.T) domain.BookRepository {
+		return infrastructure.NewMemoryBookRepository()
+	})
+}
@@ -0,0 +1,98 @@
+package infrastructure_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"api-go-gestion-libros-hexagonal/modules/book/infrastructure"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createdEvent(id uint) domain.Event {
+	return domain.BookCreated{EventMeta: domain.EventMeta{ID: domain.EventID(domain.EventBookCreated, id, 1), BookID: id}}
+}
+
+func deletedEvent(id uint) domain.Event {
+	return domain.BookDeleted{EventMeta: domain.EventMeta{BookID: id}}
+}
+
+func TestSyncEventBus_DeliversByNameAndToAll(t *testing.T) {
+	// Arrange
+	bus := infrastructure.NewSyncEventBus()
+	var got []string
+	bus.Subscribe(domain.EventBookCreated, func(ctx context.Context, e domain.Event) error {
+		got = append(got, "created")
+		return nil
+	})
+	bus.Subscribe(domain.AllEvents, func(ctx context.Context, e domain.Event) error {
+		got = append(got, "all:"+e.EventName())
+		return nil
+	})
+
+	// Act
+	err := bus.Publish(context.Background(), createdEvent(1), deletedEvent(1))
+
+	// Assert: en orden, y los de un nombre antes que los de todos
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"created", "all:book.created", "all:book.deleted"}, got)
+}
+
+func TestSyncEventBus_ReturnsErrorsAndKeepsDelivering(t *testing.T) {
+	// Arrange
+	bus := infrastructure.NewSyncEventBus()
+	failure := errors.New("index unavailable")
+	calls := 0
+	bus.Subscribe(domain.EventBookCreated, func(ctx context.Context, e domain.Event) error { return failure })
+	bus.Subscribe(domain.EventBookCreated, func(ctx context.Context, e domain.Event) error { panic("boom") })
+	bus.Subscribe(domain.EventBookCreated, func(ctx context.Context, e domain.Event) error {
+		calls++
+		return nil
+	})
+
+	// Act
+	err := bus.Publish(context.Background(), createdEvent(7))
+
+	// Assert
+	assert.ErrorIs(t, err, failure)
+	assert.ErrorContains(t, err, "book.created for book 7")
+	assert.ErrorContains(t, err, "subscriber panic: boom")
+	assert.Equal(t, 1, calls)
+}
+
+func TestAsyncEventBus_DeliversInOrderAndDrainsOnClose(t *testing.T) {
+	// Arrange
+	var (
+		mu   sync.Mutex
+		ids  []uint
+		errs []error
+	)
+	bus := infrastructure.NewAsyncEventBus(1, func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	})
+	bus.Subscribe(domain.AllEvents, func(ctx context.Context, e domain.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		ids = append(ids, e.Meta().BookID)
+		if e.Meta().BookID == 2 {
+			return errors.New("fail")
+		}
+		return nil
+	})
+
+	// Act: el ctx cancelado de la petición no corta la entrega
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, bus.Publish(ctx, createdEvent(1), createdEvent(2), createdEvent(3)))
+	cancel()
+	bus.Close()
+
+	// Assert
+	assert.Equal(t, []uint{1, 2, 3}, ids)
+	assert.Len(t, errs, 1)
+	assert.ErrorIs(t, bus.Publish(context.Background(), createdEvent(4)), infrastructure.ErrEventBusClosed)
+}
@@ -0,0 +1,233 @@
+package infrastructure_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"api-go-gestion-libros-hexagonal/modules/book/infrastructure"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMemoryBook(title, author string, year uint, genre, isbn string) *domain.Book {
+	now := time.Now().UTC()
+	return &domain.Book{Title: title, Author: author, Year: year, Genre: genre, ISBN: isbn, CreatedAt: now, UpdatedAt: now}
+}
+
+func TestMemoryBookRepository_ConcurrentCreates(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := infrastructure.NewMemoryBookRepository()
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+
+	// Act: 50 ISBN distintos y cada uno intentado dos veces en paralelo
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			isbn := fmt.Sprintf("97800000%05d", i%50)
+			errs <- repo.Create(ctx, newMemoryBook("Libro", "Autor", 2000, "Novela", isbn))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	// Assert
+	failed := 0
+	for err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	all, err := repo.GetAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 50, failed, "cada ISBN se guarda una sola vez")
+	assert.Len(t, all, 50)
+	for i, b := range all {
+		if i > 0 {
+			assert.Greater(t, b.ID, all[i-1].ID)
+		}
+	}
+}
+
+func TestMemoryBookRepository_IDsAreNotReused(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := infrastructure.NewMemoryBookRepository()
+	first := newMemoryBook("Ficciones", "Jorge Luis Borges", 1944, "Cuento", "9788420633114")
+	require.NoError(t, repo.Create(ctx, first))
+	require.NoError(t, repo.Delete(ctx, first.ID))
+
+	// Act
+	second := newMemoryBook("El Aleph", "Jorge Luis Borges", 1949, "Cuento", "9788420633121")
+	err := repo.Create(ctx, second)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, first.ID+1, second.ID)
+}
+
+func TestMemoryBookRepository_ReturnsCopies(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := infrastructure.NewMemoryBookRepository()
+	book := newMemoryBook("Ficciones", "Jorge Luis Borges", 1944, "Cuento", "978-84-206-3311-4")
+	require.NoError(t, repo.Create(ctx, book))
+
+	// Act: modificar lo devuelto no cambia lo guardado
+	found, err := repo.GetByID(ctx, book.ID)
+	require.NoError(t, err)
+	found.Title = "Otro"
+	again, _ := repo.GetByID(ctx, book.ID)
+
+	// Assert
+	assert.Equal(t, "Ficciones", again.Title)
+	assert.Equal(t, "9788420633114", again.ISBN)
+}
+
+func TestMemoryBookRepository_QueryRelevanceAndHighlights(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := infrastructure.NewMemoryBookRepository()
+	require.NoError(t, repo.Create(ctx, newMemoryBook("Ficciones", "Jorge Luis Borges", 1944, "Cuento", "9788420633114")))
+	require.NoError(t, repo.Create(ctx, newMemoryBook("El libro de arena", "Jorge Luis Borges", 1975, "Cuento", "9788420633138")))
+	require.NoError(t, repo.Create(ctx, newMemoryBook("Cien años de soledad", "Gabriel García Márquez", 1967, "Novela", "9780307474728")))
+
+	// Act
+	page, err := repo.FindByFilter(ctx, domain.BookFilter{Query: `"el libro" OR garcia -year<1950`, Limit: 10})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+	assert.Equal(t, "<mark>El libro</mark> de arena", page.Matches[page.Books[0].ID].Highlights["title"])
+	assert.Equal(t, "Gabriel <mark>García</mark> Márquez", page.Matches[page.Books[1].ID].Highlights["author"])
+	assert.Less(t, page.Matches[page.Books[0].ID].Score, 0.0)
+}
+
+func TestMemoryBookRepository_WithinTxRollsBack(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := infrastructure.NewMemoryBookRepository()
+	kept := newMemoryBook("Ficciones", "Jorge Luis Borges", 1944, "Cuento", "9788420633114")
+	require.NoError(t, repo.Create(ctx, kept))
+
+	// Act
+	err := repo.WithinTx(ctx, func(ctx context.Context) error {
+		if err := repo.Create(ctx, newMemoryBook("El Aleph", "Jorge Luis Borges", 1949, "Cuento", "9788420633121")); err != nil {
+			return err
+		}
+		if err := repo.Delete(ctx, kept.ID); err != nil {
+			return err
+		}
+		return fmt.Errorf("boom")
+	})
+
+	// Assert
+	assert.EqualError(t, err, "boom")
+	all, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, kept.ID, all[0].ID)
+}
+
+func TestMemoryBookRepository_WithinTxIsNotVisibleUntilCommit(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := infrastructure.NewMemoryBookRepository()
+	var inside []*domain.Book
+
+	// Act: afuera de la unidad de trabajo no se ve el libro que crea
+	err := repo.WithinTx(ctx, func(txCtx context.Context) error {
+		if err := repo.Create(txCtx, newMemoryBook("El Aleph", "Jorge Luis Borges", 1949, "Cuento", "9788420633121")); err != nil {
+			return err
+		}
+		var err error
+		inside, err = repo.GetAll(ctx)
+		return err
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, inside)
+	after, err := repo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, after, 1)
+}
+
+func TestMemoryBookRepository_RollbackKeepsWritesOutsideTx(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := infrastructure.NewMemoryBookRepository()
+	book := newMemoryBook("Ficciones", "Jorge Luis Borges", 1944, "Cuento", "9788420633114")
+	require.NoError(t, repo.Create(ctx, book))
+	require.NoError(t, repo.Delete(ctx, book.ID))
+
+	// Act: se vacía la papelera mientras corre una unidad de trabajo que después falla
+	started := make(chan struct{})
+	purged := make(chan int)
+	go func() {
+		<-started
+		n, _ := repo.Purge(ctx, time.Now().Add(time.Hour))
+		purged <- n
+	}()
+	err := repo.WithinTx(ctx, func(ctx context.Context) error {
+		close(started)
+		time.Sleep(20 * time.Millisecond)
+		return fmt.Errorf("boom")
+	})
+
+	// Assert: el libro purgado no vuelve
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, 1, <-purged)
+	trash, err := repo.ListDeleted(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+}
+
+func TestMemoryRepositories_JoinWithinTx(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	books := infrastructure.NewMemoryBookRepository()
+	revisions := infrastructure.NewMemoryRevisionRepository()
+	outbox := infrastructure.NewMemoryOutboxRepository()
+	write := func(fail bool) error {
+		return books.WithinTx(ctx, func(ctx context.Context) error {
+			if err := revisions.Append(ctx, &domain.Revision{BookID: 1, Action: domain.RevisionUpdate}); err != nil {
+				return err
+			}
+			listed, err := revisions.ListByBook(ctx, 1)
+			if err != nil || len(listed) != 1 {
+				return fmt.Errorf("the unit of work does not see its own revision: %v", err)
+			}
+			if err := outbox.Add(ctx, &domain.OutboxMessage{DedupeKey: "book.updated:1:2"}); err != nil {
+				return err
+			}
+			if fail {
+				return fmt.Errorf("boom")
+			}
+			return nil
+		})
+	}
+
+	// Act
+	rollbackErr := write(true)
+	commitErr := write(false)
+
+	// Assert: solo queda lo de la unidad confirmada
+	assert.EqualError(t, rollbackErr, "boom")
+	require.NoError(t, commitErr)
+	revs, err := revisions.ListByBook(ctx, 1)
+	require.NoError(t, err)
+	if assert.Len(t, revs, 1) {
+		assert.Equal(t, uint(1), revs[0].ID)
+		assert.Equal(t, uint(1), revs[0].Number)
+	}
+	backlog, err := outbox.Backlog(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, backlog.Pending)
+}
@@ -0,0 +1,144 @@
+package infrastructure_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"api-go-gestion-libros-hexagonal/modules/book/infrastructure"
+	"api-go-gestion-libros-hexagonal/shared/database"
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// outboxMessage es un mensaje pendiente de key creado en at
+func outboxMessage(key string, at time.Time) *domain.OutboxMessage {
+	return &domain.OutboxMessage{DedupeKey: key, EventName: domain.EventBookCreated, BookID: 7,
+		Payload: []byte(`{"id":"` + key + `"}`), CreatedAt: at, AvailableAt: at}
+}
+
+// testOutboxRepository revisa lo que todo domain.OutboxRepository debe cumplir
+func testOutboxRepository(t *testing.T, repo domain.OutboxRepository) {
+	ctx := context.Background()
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// El mismo evento no se guarda dos veces
+	first := outboxMessage("book.created:7:1", at)
+	require.NoError(t, repo.Add(ctx, first))
+	assert.NotZero(t, first.ID)
+	again := outboxMessage("book.created:7:1", at)
+	require.NoError(t, repo.Add(ctx, again))
+	assert.Zero(t, again.ID)
+	second := outboxMessage("book.updated:7:2", at.Add(time.Minute))
+	require.NoError(t, repo.Add(ctx, second))
+	third := outboxMessage("book.deleted:7:3", at.Add(2*time.Minute))
+	require.NoError(t, repo.Add(ctx, third))
+
+	// Due respeta available_at y el orden de llegada
+	due, err := repo.Due(ctx, at.Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, first.ID, due[0].ID)
+	assert.Equal(t, second.ID, due[1].ID)
+	assert.Equal(t, first.Payload, due[0].Payload)
+	assert.Equal(t, at, due[0].CreatedAt)
+
+	due, err = repo.Due(ctx, at.Add(time.Hour), 1)
+	require.NoError(t, err)
+	assert.Len(t, due, 1)
+
+	// Entregado, reprogramado y fallido
+	require.NoError(t, repo.MarkPublished(ctx, first.ID, at.Add(time.Hour)))
+	require.NoError(t, repo.MarkRetry(ctx, second.ID, "timeout", at.Add(2*time.Hour)))
+	require.NoError(t, repo.MarkFailed(ctx, third.ID, "gone", at.Add(time.Hour)))
+	assert.ErrorIs(t, repo.MarkPublished(ctx, 999, at), domain.ErrNotFound)
+
+	due, err = repo.Due(ctx, at.Add(time.Hour), 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	due, err = repo.Due(ctx, at.Add(2*time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, 1, due[0].Attempts)
+	assert.Equal(t, "timeout", due[0].LastError)
+
+	backlog, err := repo.Backlog(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, backlog.Pending)
+	assert.Equal(t, 1, backlog.Failed)
+	if assert.NotNil(t, backlog.OldestPendingAt) {
+		assert.Equal(t, at.Add(time.Minute), *backlog.OldestPendingAt)
+	}
+	require.Len(t, backlog.Messages, 2)
+	assert.Equal(t, second.ID, backlog.Messages[0].ID)
+	assert.Nil(t, backlog.Messages[0].FailedAt)
+	assert.Equal(t, "gone", backlog.Messages[1].LastError)
+	assert.NotNil(t, backlog.Messages[1].FailedAt)
+
+	backlog, err = repo.Backlog(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, backlog.Failed)
+	assert.Len(t, backlog.Messages, 1)
+}
+
+func TestMemoryOutboxRepository(t *testing.T) {
+	testOutboxRepository(t, infrastructure.NewMemoryOutboxRepository())
+}
+
+func TestSqlOutboxRepository_SQLite(t *testing.T) {
+	testOutboxRepository(t, infrastructure.NewSqlOutboxRepository(openSQLite(t)))
+}
+
+func TestSqlOutboxRepository_Postgres(t *testing.T) {
+	if os.Getenv("POSTGRES_TEST_DSN") == "" {
+		t.Skip("POSTGRES_TEST_DSN not set")
+	}
+	testOutboxRepository(t, infrastructure.NewPostgresOutboxRepository(openPostgres(t)))
+}
+
+func TestBookService_SQLite_OutboxDeliversAfterFailure(t *testing.T) {
+	// Arrange: los eventos se guardan con cada cambio y el relay los entrega al bus
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	db := openSQLite(t)
+	outbox := infrastructure.NewSqlOutboxRepository(db)
+	service := application.NewBookService(infrastructure.NewSqlBookRepository(db),
+		infrastructure.NewSqlRevisionRepository(db), database.NewTxManager(db), application.NewOutboxPublisher(outbox))
+
+	bus := infrastructure.NewSyncEventBus()
+	var received []string
+	failing := true
+	bus.Subscribe(domain.AllEvents, func(ctx context.Context, event domain.Event) error {
+		if failing {
+			return errors.New("index unavailable")
+		}
+		received = append(received, event.Meta().ID)
+		return nil
+	})
+	relay := application.NewOutboxRelay(outbox, bus, domain.RetryPolicy{MaxAttempts: 3})
+
+	created, err := service.CreateBook(ctx, "Ficciones", "Jorge Luis Borges", 1944, "Cuento", "9788420633114")
+	require.NoError(t, err)
+	// Un cambio que falla no deja evento
+	_, err = service.CreateBook(ctx, "Otro", "Otro", 1944, "Cuento", "9788420633114")
+	require.Error(t, err)
+
+	// Act: el primer intento falla y el mensaje queda para después; el segundo entrega
+	n, err := relay.Relay(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	failing = false
+	n, err = relay.Relay(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{domain.EventID(domain.EventBookCreated, created.ID, created.Version)}, received)
+	backlog, err := relay.Backlog(ctx, 0)
+	require.NoError(t, err)
+	assert.Zero(t, backlog.Pending)
+}
@@ -0,0 +1,106 @@
+package infrastructure_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"api-go-gestion-libros-hexagonal/modules/book/infrastructure"
+	"api-go-gestion-libros-hexagonal/shared/database"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRevisionRepository revisa lo que todo domain.RevisionRepository debe cumplir
+func testRevisionRepository(t *testing.T, repo domain.RevisionRepository) {
+	ctx := context.Background()
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Cada libro numera sus revisiones desde 1
+	first := &domain.Revision{BookID: 7, Action: domain.RevisionCreate, Actor: "ana", CreatedAt: at,
+		Changes: []domain.FieldChange{{Field: "title", To: "Ficciones"}, {Field: "year", To: "1944"}}}
+	require.NoError(t, repo.Append(ctx, first))
+	other := &domain.Revision{BookID: 8, Action: domain.RevisionCreate, Actor: "ana", CreatedAt: at,
+		Changes: []domain.FieldChange{{Field: "title", To: "El Aleph"}}}
+	require.NoError(t, repo.Append(ctx, other))
+	second := &domain.Revision{BookID: 7, Action: domain.RevisionRevert, Actor: "luis", RevertedTo: 1, CreatedAt: at.Add(time.Hour),
+		Changes: []domain.FieldChange{{Field: "year", From: "1956", To: "1944"}}}
+	require.NoError(t, repo.Append(ctx, second))
+
+	assert.Equal(t, uint(1), first.Number)
+	assert.Equal(t, uint(1), other.Number)
+	assert.Equal(t, uint(2), second.Number)
+	assert.NotEqual(t, first.ID, second.ID)
+
+	revs, err := repo.ListByBook(ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, revs, 2)
+	assert.Equal(t, *first, *revs[0])
+	assert.Equal(t, *second, *revs[1])
+
+	revs, err = repo.ListByBook(ctx, 99)
+	require.NoError(t, err)
+	assert.Empty(t, revs)
+}
+
+func TestMemoryRevisionRepository(t *testing.T) {
+	testRevisionRepository(t, infrastructure.NewMemoryRevisionRepository())
+}
+
+func TestSqlRevisionRepository_SQLite(t *testing.T) {
+	testRevisionRepository(t, infrastructure.NewSqlRevisionRepository(openSQLite(t)))
+}
+
+func TestSqlRevisionRepository_Postgres(t *testing.T) {
+	if os.Getenv("POSTGRES_TEST_DSN") == "" {
+		t.Skip("POSTGRES_TEST_DSN not set")
+	}
+	testRevisionRepository(t, infrastructure.NewPostgresRevisionRepository(openPostgres(t)))
+}
+
+func TestBookService_SQLite_HistoryAndRevert(t *testing.T) {
+	// Arrange: servicio completo sobre SQLite, con el historial en la misma transacción
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = application.WithActor(ctx, "ana")
+	db := openSQLite(t)
+	service := application.NewBookService(infrastructure.NewSqlBookRepository(db),
+		infrastructure.NewSqlRevisionRepository(db), database.NewTxManager(db), infrastructure.NewSyncEventBus())
+
+	created, err := service.CreateBook(ctx, "Ficciones", "Jorge Luis Borges", 1944, "Cuento", "9788420633114")
+	require.NoError(t, err)
+	title, year := "Ficciones (1956)", uint(1956)
+	updated, err := service.UpdateBook(ctx, created.ID, created.Version, domain.UpdateBookInput{Title: &title, Year: &year})
+	require.NoError(t, err)
+
+	// Act
+	reverted, err := service.RevertBook(ctx, created.ID, 1, updated.Version)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Ficciones", reverted.Title)
+	assert.Equal(t, uint(1944), reverted.Year)
+	assert.Equal(t, uint(3), reverted.Version)
+
+	revs, err := service.GetBookHistory(ctx, created.ID)
+	require.NoError(t, err)
+	require.Len(t, revs, 3)
+	assert.Equal(t, []string{domain.RevisionCreate, domain.RevisionUpdate, domain.RevisionRevert},
+		[]string{revs[0].Action, revs[1].Action, revs[2].Action})
+	assert.Equal(t, "ana", revs[2].Actor)
+	assert.Equal(t, uint(1), revs[2].RevertedTo)
+	assert.Equal(t, []domain.FieldChange{
+		{Field: "title", From: "Ficciones (1956)", To: "Ficciones"},
+		{Field: "year", From: "1956", To: "1944"},
+	}, revs[2].Changes)
+
+	// El historial sobrevive al borrado
+	require.NoError(t, service.DeleteBook(ctx, created.ID))
+	revs, err = service.GetBookHistory(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Len(t, revs, 4)
+	assert.Equal(t, domain.RevisionDelete, revs[3].Action)
+}
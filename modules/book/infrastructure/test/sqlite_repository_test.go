@@ -0,0 +1,103 @@
+package infrastructure_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"api-go-gestion-libros-hexagonal/modules/book/infrastructure"
+	"api-go-gestion-libros-hexagonal/shared/config"
+	"api-go-gestion-libros-hexagonal/shared/database"
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openSQLite abre SQLite en memoria con el esquema migrado
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := database.OpenSQLite(database.MemoryPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	migrator, err := database.NewMigrator(db, config.DriverSQLite)
+	require.NoError(t, err)
+	_, err = migrator.Up(context.Background())
+	require.NoError(t, err)
+	return db
+}
+
+// newSQLiteRepository crea un repositorio sobre SQLite en memoria con el esquema migrado
+func newSQLiteRepository(t *testing.T) *infrastructure.SqlBookRepository {
+	t.Helper()
+	return infrastructure.NewSqlBookRepository(openSQLite(t))
+}
+
+func seedBooks(t *testing.T, repo *infrastructure.SqlBookRepository) []*domain.Book {
+	t.Helper()
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	books := []*domain.Book{
+		{Title: "Ficciones", Author: "Jorge Luis Borges", Year: 1944, Genre: "Cuento", ISBN: "9788420633114"},
+		{Title: "El Aleph", Author: "Jorge Luis Borges", Year: 1949, Genre: "Cuento", ISBN: "9788420633121"},
+		{Title: "Cien años de soledad", Author: "Gabriel García Márquez", Year: 1967, Genre: "Novela", ISBN: "9780307474728"},
+	}
+	for i, b := range books {
+		b.CreatedAt = created.Add(time.Duration(i) * time.Hour)
+		b.UpdatedAt = b.CreatedAt
+		require.NoError(t, repo.Create(context.Background(), b))
+	}
+	return books
+}
+
+func TestSqlBookRepository_SQLite_FullTextQueryAndFilters(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	repo := newSQLiteRepository(t)
+	seedBooks(t, repo)
+	createdAfter := time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC)
+
+	// Act
+	page, err := repo.FindByFilter(ctx, domain.BookFilter{
+		Query:        `author:borges OR garcia`,
+		CreatedAfter: &createdAfter,
+		Limit:        10,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+	assert.Equal(t, "Cien años de soledad", page.Books[0].Title, "la coincidencia de texto libre va primero")
+	assert.Equal(t, "Gabriel <mark>García</mark> Márquez", page.Matches[page.Books[0].ID].Highlights["author"])
+}
+
+func TestSqlBookRepository_SQLite_WithinTx(t *testing.T) {
+	// Arrange: SQLite en memoria usa una sola conexión, así que una llamada del
+	// repositorio que no use la transacción del ctx se quedaría esperando
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	db := openSQLite(t)
+	repo := infrastructure.NewSqlBookRepository(db)
+	tx := database.NewTxManager(db)
+	service := application.NewBookService(repo, infrastructure.NewSqlRevisionRepository(db), tx, infrastructure.NewSyncEventBus())
+
+	// Act
+	created, createErr := service.CreateBook(ctx, "Ficciones", "Jorge Luis Borges", 1944, "Cuento", "9788420633114")
+	_, dupErr := service.CreateBook(ctx, "Ficciones", "Jorge Luis Borges", 1944, "Cuento", "9788420633114")
+	rollbackErr := tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := repo.Delete(ctx, created.ID); err != nil {
+			return err
+		}
+		return errors.New("boom")
+	})
+
+	// Assert
+	require.NoError(t, createErr)
+	assert.NotZero(t, created.ID)
+	assert.ErrorIs(t, dupErr, domain.ErrDuplicateISBN)
+	assert.EqualError(t, rollbackErr, "boom")
+	found, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Ficciones", found.Title)
+}
@@ -0,0 +1,361 @@
+package infrastructure_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"api-go-gestion-libros-hexagonal/modules/book/infrastructure"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver es un sistema externo de prueba: responde status y guarda lo recibido
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []receivedWebhook
+}
+
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, receivedWebhook{header: req.Header.Clone(), body: body})
+	w.WriteHeader(r.status)
+}
+
+func (r *receiver) respond(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func (r *receiver) received() []receivedWebhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedWebhook(nil), r.requests...)
+}
+
+// newWebhookSetup arma el servicio de libros con el outbox y los webhooks en memoria,
+// entregando a un receptor httptest
+func newWebhookSetup(t *testing.T, policy domain.RetryPolicy, disableAfter int) (*application.BookService, *application.OutboxRelay, *application.WebhookService, *receiver, string) {
+	rcv := &receiver{status: http.StatusOK}
+	server := httptest.NewServer(rcv)
+	t.Cleanup(server.Close)
+
+	webhooks := application.NewWebhookService(infrastructure.NewMemoryWebhookRepository(),
+		infrastructure.NewHTTPWebhookClient(time.Second), policy, disableAfter)
+	bus := infrastructure.NewSyncEventBus()
+	bus.Subscribe(domain.AllEvents, webhooks.Enqueue)
+
+	outbox := infrastructure.NewMemoryOutboxRepository()
+	books := infrastructure.NewMemoryBookRepository()
+	service := application.NewBookService(books, infrastructure.NewMemoryRevisionRepository(), books,
+		application.NewOutboxPublisher(outbox))
+	relay := application.NewOutboxRelay(outbox, bus, domain.DefaultRetryPolicy)
+	return service, relay, webhooks, rcv, server.URL
+}
+
+func TestWebhooks_DeliversSignedEvent(t *testing.T) {
+	// Arrange
+	ctx := application.WithActor(context.Background(), "ana")
+	service, relay, webhooks, rcv, url := newWebhookSetup(t, domain.DefaultRetryPolicy, 5)
+	secret := "0123456789abcdef"
+	hook, err := webhooks.CreateWebhook(ctx, domain.WebhookInput{URL: &url, Events: []string{domain.EventBookCreated}, Secret: &secret})
+	require.NoError(t, err)
+
+	book, err := service.CreateBook(ctx, "Ficciones", "Jorge Luis Borges", 1944, "Cuento", "9788420633114")
+	require.NoError(t, err)
+	// Un evento que el webhook no pidió
+	require.NoError(t, service.DeleteBook(ctx, book.ID))
+
+	// Act
+	_, err = relay.Relay(ctx)
+	require.NoError(t, err)
+	n, err := webhooks.Dispatch(ctx)
+
+	// Assert: firmado con el secreto y con el ID del evento
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	requests := rcv.received()
+	require.Len(t, requests, 1)
+	req := requests[0]
+	assert.True(t, domain.VerifyWebhookSignature(secret, req.body, req.header.Get(domain.WebhookSignatureHeader)))
+	assert.Equal(t, "application/json", req.header.Get("Content-Type"))
+	assert.Equal(t, domain.EventBookCreated, req.header.Get(domain.WebhookEventHeader))
+	eventID := domain.EventID(domain.EventBookCreated, book.ID, book.Version)
+	assert.Equal(t, eventID, req.header.Get(domain.WebhookEventIDHeader))
+
+	var payload struct {
+		ID    string `json:"id"`
+		Event string `json:"event"`
+		Data  struct {
+			Actor string `json:"actor"`
+			Book  struct {
+				Title string `json:"title"`
+			} `json:"book"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(req.body, &payload))
+	assert.Equal(t, eventID, payload.ID)
+	assert.Equal(t, domain.EventBookCreated, payload.Event)
+	assert.Equal(t, "ana", payload.Data.Actor)
+	assert.Equal(t, "Ficciones", payload.Data.Book.Title)
+
+	deliveries, err := webhooks.ListDeliveries(ctx, hook.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, domain.DeliveryDelivered, deliveries[0].Status)
+	assert.Equal(t, http.StatusOK, deliveries[0].ResponseStatus)
+}
+
+func TestWebhooks_RetriesUntilReceiverRecovers(t *testing.T) {
+	// Arrange: sin espera entre intentos para no dormir en el test
+	ctx := context.Background()
+	service, relay, webhooks, rcv, url := newWebhookSetup(t, domain.RetryPolicy{MaxAttempts: 5}, 10)
+	hook, err := webhooks.CreateWebhook(ctx, domain.WebhookInput{URL: &url})
+	require.NoError(t, err)
+	_, err = service.CreateBook(ctx, "Ficciones", "Jorge Luis Borges", 1944, "Cuento", "9788420633114")
+	require.NoError(t, err)
+	_, err = relay.Relay(ctx)
+	require.NoError(t, err)
+
+	// Act: dos fallos y luego responde bien
+	rcv.respond(http.StatusServiceUnavailable)
+	for range 2 {
+		n, err := webhooks.Dispatch(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+	}
+	failing, err := webhooks.GetWebhook(ctx, hook.ID)
+	require.NoError(t, err)
+	rcv.respond(http.StatusNoContent)
+	n, err := webhooks.Dispatch(ctx)
+
+	// Assert: el mismo evento en los tres intentos, y el contador de fallos vuelve a cero
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	requests := rcv.received()
+	require.Len(t, requests, 3)
+	assert.Equal(t, requests[0].body, requests[2].body)
+	assert.Equal(t, requests[0].header.Get(domain.WebhookEventIDHeader), requests[2].header.Get(domain.WebhookEventIDHeader))
+	assert.Equal(t, 2, failing.Failures)
+
+	deliveries, err := webhooks.ListDeliveries(ctx, hook.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, domain.DeliveryDelivered, deliveries[0].Status)
+	assert.Equal(t, 3, deliveries[0].Attempts)
+	recovered, err := webhooks.GetWebhook(ctx, hook.ID)
+	require.NoError(t, err)
+	assert.Zero(t, recovered.Failures)
+}
+
+func TestWebhooks_BackoffSchedulesNextAttempt(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	service, relay, webhooks, rcv, url := newWebhookSetup(t, domain.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Minute, MaxDelay: time.Hour}, 10)
+	hook, err := webhooks.CreateWebhook(ctx, domain.WebhookInput{URL: &url})
+	require.NoError(t, err)
+	_, err = service.CreateBook(ctx, "Ficciones", "Jorge Luis Borges", 1944, "Cuento", "9788420633114")
+	require.NoError(t, err)
+	_, err = relay.Relay(ctx)
+	require.NoError(t, err)
+	rcv.respond(http.StatusInternalServerError)
+
+	// Act: el segundo Dispatch no reintenta antes de tiempo
+	_, err = webhooks.Dispatch(ctx)
+	require.NoError(t, err)
+	_, err = webhooks.Dispatch(ctx)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Len(t, rcv.received(), 1)
+	deliveries, err := webhooks.ListDeliveries(ctx, hook.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, domain.DeliveryPending, deliveries[0].Status)
+	assert.Equal(t, "receiver responded 500", deliveries[0].LastError)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deliveries[0].NextAttemptAt, 5*time.Second)
+}
+
+func TestWebhooks_DisablesAfterRepeatedFailuresAndRedelivers(t *testing.T) {
+	// Arrange: cada entrega tiene un solo intento y el webhook se desactiva al segundo fallo
+	ctx := context.Background()
+	service, relay, webhooks, rcv, url := newWebhookSetup(t, domain.RetryPolicy{MaxAttempts: 1}, 2)
+	hook, err := webhooks.CreateWebhook(ctx, domain.WebhookInput{URL: &url})
+	require.NoError(t, err)
+	rcv.respond(http.StatusInternalServerError)
+	for _, isbn := range []string{"9788420633114", "9780306406157", "9780140449136"} {
+		_, err := service.CreateBook(ctx, "Libro "+isbn, "Autor", 1944, "Cuento", isbn)
+		require.NoError(t, err)
+	}
+	_, err = relay.Relay(ctx)
+	require.NoError(t, err)
+
+	// Act: cada pasada se detiene en el primer fallo del webhook
+	_, err = webhooks.Dispatch(ctx)
+	require.NoError(t, err)
+	assert.Len(t, rcv.received(), 1)
+	_, err = webhooks.Dispatch(ctx)
+	require.NoError(t, err)
+
+	// Assert: la tercera entrega espera a que se reactive
+	assert.Len(t, rcv.received(), 2)
+	disabled, err := webhooks.GetWebhook(ctx, hook.ID)
+	require.NoError(t, err)
+	assert.False(t, disabled.Active)
+	assert.NotNil(t, disabled.DisabledAt)
+	deliveries, err := webhooks.ListDeliveries(ctx, hook.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+	assert.Equal(t, domain.DeliveryPending, deliveries[0].Status)
+	assert.Equal(t, domain.DeliveryFailed, deliveries[1].Status)
+
+	// Act: el receptor se arregla; se reenvía a mano una fallida y se reactiva el webhook
+	rcv.respond(http.StatusOK)
+	redelivered, err := webhooks.RedeliverWebhook(ctx, hook.ID, deliveries[1].ID)
+	require.NoError(t, err)
+	active := true
+	_, err = webhooks.UpdateWebhook(ctx, hook.ID, domain.WebhookInput{Active: &active})
+	require.NoError(t, err)
+	n, err := webhooks.Dispatch(ctx)
+
+	// Assert: el reenvío es una entrega nueva y la original conserva su intento fallido
+	require.NoError(t, err)
+	assert.Equal(t, domain.DeliveryDelivered, redelivered.Status)
+	assert.NotEqual(t, deliveries[1].ID, redelivered.ID)
+	assert.Equal(t, deliveries[1].ID, redelivered.RedeliveryOf)
+	assert.Equal(t, 1, n)
+	history, err := webhooks.ListDeliveries(ctx, hook.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+	for _, d := range history {
+		if d.ID == deliveries[1].ID {
+			assert.Equal(t, domain.DeliveryFailed, d.Status)
+			assert.Equal(t, 1, d.Attempts)
+		}
+	}
+	requests := rcv.received()
+	require.Len(t, requests, 4)
+	assert.Equal(t, deliveries[1].EventID, requests[2].header.Get(domain.WebhookEventIDHeader))
+	assert.Equal(t, deliveries[0].EventID, requests[3].header.Get(domain.WebhookEventIDHeader))
+	enabled, err := webhooks.GetWebhook(ctx, hook.ID)
+	require.NoError(t, err)
+	assert.True(t, enabled.Active)
+	assert.Zero(t, enabled.Failures)
+}
+
+func TestWebhooks_DeadReceiverDoesNotStallOthers(t *testing.T) {
+	// Arrange: un receptor que no contesta (el cliente corta al segundo) y otro sano
+	ctx := context.Background()
+	service, relay, webhooks, rcv, url := newWebhookSetup(t, domain.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Minute, MaxDelay: time.Hour}, 10)
+	release := make(chan struct{})
+	var stalled atomic.Int32
+	dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stalled.Add(1)
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(dead.Close)
+	deadURL := dead.URL
+	_, err := webhooks.CreateWebhook(ctx, domain.WebhookInput{URL: &deadURL})
+	require.NoError(t, err)
+	_, err = webhooks.CreateWebhook(ctx, domain.WebhookInput{URL: &url})
+	require.NoError(t, err)
+	for _, isbn := range []string{"9788420633114", "9780306406157", "9780140449136"} {
+		_, err := service.CreateBook(ctx, "Libro "+isbn, "Autor", 1944, "Cuento", isbn)
+		require.NoError(t, err)
+	}
+	_, err = relay.Relay(ctx)
+	require.NoError(t, err)
+
+	// Act
+	done := make(chan int, 1)
+	go func() {
+		n, err := webhooks.Dispatch(ctx)
+		assert.NoError(t, err)
+		done <- n
+	}()
+
+	// Assert: el sano recibe todo mientras el otro sigue colgado en su primer intento
+	assert.Eventually(t, func() bool { return len(rcv.received()) == 3 }, 800*time.Millisecond, 10*time.Millisecond)
+	assert.EqualValues(t, 1, stalled.Load())
+	close(release)
+	assert.Equal(t, 3, <-done)
+	assert.EqualValues(t, 1, stalled.Load())
+}
+
+func TestWebhooks_DownReceiverWaitsInsteadOfBeingDisabled(t *testing.T) {
+	// Arrange: un receptor caído con más entregas pendientes que un lote y que el límite
+	// de fallos, y un webhook sano que se da de alta después
+	ctx := context.Background()
+	_, _, webhooks, rcv, url := newWebhookSetup(t, domain.RetryPolicy{MaxAttempts: 5, BaseDelay: time.Minute, MaxDelay: time.Hour}, 3)
+	var hits atomic.Int32
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(down.Close)
+	downURL := down.URL
+	failing, err := webhooks.CreateWebhook(ctx, domain.WebhookInput{URL: &downURL})
+	require.NoError(t, err)
+	for id := range application.DefaultWebhookBatch + 10 {
+		require.NoError(t, webhooks.Enqueue(ctx, createdEvent(uint(id+1))))
+	}
+	_, err = webhooks.CreateWebhook(ctx, domain.WebhookInput{URL: &url})
+	require.NoError(t, err)
+	require.NoError(t, webhooks.Enqueue(ctx, createdEvent(1000)))
+
+	// Act: varias pasadas seguidas, como el despachador cada pocos segundos
+	delivered := 0
+	for range 10 {
+		n, err := webhooks.Dispatch(ctx)
+		require.NoError(t, err)
+		delivered += n
+	}
+
+	// Assert: un solo intento y un solo fallo en la espera; el sano recibe lo suyo
+	assert.EqualValues(t, 1, hits.Load())
+	got, err := webhooks.GetWebhook(ctx, failing.ID)
+	require.NoError(t, err)
+	assert.True(t, got.Active)
+	assert.Equal(t, 1, got.Failures)
+	if assert.NotNil(t, got.RetryAt) {
+		assert.WithinDuration(t, time.Now().Add(time.Minute), *got.RetryAt, 5*time.Second)
+	}
+	assert.Equal(t, 1, delivered)
+	assert.Len(t, rcv.received(), 1)
+}
+
+func TestWebhooks_RelayRedeliveryDoesNotDuplicate(t *testing.T) {
+	// Arrange: el mismo evento llega dos veces al suscriptor (entrega al menos una vez)
+	ctx := context.Background()
+	_, _, webhooks, rcv, url := newWebhookSetup(t, domain.DefaultRetryPolicy, 5)
+	_, err := webhooks.CreateWebhook(ctx, domain.WebhookInput{URL: &url})
+	require.NoError(t, err)
+	event := createdEvent(7)
+
+	// Act
+	require.NoError(t, webhooks.Enqueue(ctx, event))
+	require.NoError(t, webhooks.Enqueue(ctx, event))
+	_, err = webhooks.Dispatch(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, rcv.received(), 1)
+}
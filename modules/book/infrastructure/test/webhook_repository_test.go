@@ -0,0 +1,156 @@
+package infrastructure_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"api-go-gestion-libros-hexagonal/modules/book/infrastructure"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pendingDelivery es una entrega pendiente de eventID para webhook, lista en at
+func pendingDelivery(webhookID uint, eventID string, at time.Time) *domain.WebhookDelivery {
+	return &domain.WebhookDelivery{WebhookID: webhookID, EventID: eventID, EventName: domain.EventBookCreated,
+		Payload: []byte(`{"id":"` + eventID + `"}`), Status: domain.DeliveryPending, NextAttemptAt: at, CreatedAt: at}
+}
+
+// testWebhookRepository revisa lo que todo domain.WebhookRepository debe cumplir
+func testWebhookRepository(t *testing.T, repo domain.WebhookRepository) {
+	ctx := context.Background()
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Alta, lectura y cambios
+	url, secret := "http://erp.local/hooks", "0123456789abcdef"
+	erp := domain.NewWebhook(domain.WebhookInput{URL: &url, Events: []string{domain.EventBookCreated}, Secret: &secret})
+	erp.CreatedAt, erp.UpdatedAt = at, at
+	require.NoError(t, repo.Create(ctx, erp))
+	web := domain.NewWebhook(domain.WebhookInput{URL: &url, Secret: &secret})
+	web.CreatedAt, web.UpdatedAt = at, at
+	require.NoError(t, repo.Create(ctx, web))
+	assert.NotEqual(t, erp.ID, web.ID)
+
+	got, err := repo.GetByID(ctx, erp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, *erp, *got)
+	_, err = repo.GetByID(ctx, 999)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	erp.Events = []string{domain.EventBookCreated, domain.EventBookDeleted}
+	require.NoError(t, repo.Update(ctx, erp))
+	all, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, erp.Events, all[0].Events)
+	assert.Equal(t, []string{domain.AllEvents}, all[1].Events)
+
+	// Entregas: una por webhook y evento
+	first := pendingDelivery(erp.ID, "book.created:7:1", at)
+	require.NoError(t, repo.AddDelivery(ctx, first))
+	assert.NotZero(t, first.ID)
+	again := pendingDelivery(erp.ID, "book.created:7:1", at)
+	require.NoError(t, repo.AddDelivery(ctx, again))
+	assert.Zero(t, again.ID)
+	original := pendingDelivery(web.ID, "book.created:7:1", at)
+	require.NoError(t, repo.AddDelivery(ctx, original))
+	second := pendingDelivery(erp.ID, "book.deleted:7:2", at.Add(time.Minute))
+	require.NoError(t, repo.AddDelivery(ctx, second))
+
+	// Un reenvío es otra entrega del mismo evento, que apunta a la original
+	redelivery := pendingDelivery(web.ID, "book.created:7:1", at.Add(3*time.Hour))
+	redelivery.RedeliveryOf = original.ID
+	require.NoError(t, repo.AddDelivery(ctx, redelivery))
+	assert.NotZero(t, redelivery.ID)
+	gotRedelivery, err := repo.GetDelivery(ctx, web.ID, redelivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, *redelivery, *gotRedelivery)
+
+	due, err := repo.DueDeliveries(ctx, at, 10)
+	require.NoError(t, err)
+	assert.Len(t, due, 2)
+
+	// Un intento fallido reprograma la entrega
+	first.Attempts, first.ResponseStatus, first.LastError = 1, 500, "receiver responded 500"
+	first.NextAttemptAt = at.Add(time.Hour)
+	require.NoError(t, repo.UpdateDelivery(ctx, first))
+	got1, err := repo.GetDelivery(ctx, erp.ID, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, *first, *got1)
+	_, err = repo.GetDelivery(ctx, web.ID, first.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	// Los fallos seguidos desactivan el webhook; sus entregas dejan de salir
+	require.NoError(t, repo.RecordFailure(ctx, erp.ID, 2, at, at.Add(time.Minute)))
+	got, err = repo.GetByID(ctx, erp.ID)
+	require.NoError(t, err)
+	assert.True(t, got.Active)
+	assert.Equal(t, 1, got.Failures)
+	require.NoError(t, repo.RecordFailure(ctx, erp.ID, 2, at.Add(time.Minute), at.Add(3*time.Minute)))
+	got, err = repo.GetByID(ctx, erp.ID)
+	require.NoError(t, err)
+	assert.False(t, got.Active)
+	assert.Equal(t, 2, got.Failures)
+	if assert.NotNil(t, got.DisabledAt) {
+		assert.Equal(t, at.Add(time.Minute), *got.DisabledAt)
+	}
+	due, err = repo.DueDeliveries(ctx, at.Add(2*time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, web.ID, due[0].WebhookID)
+
+	// Tras un fallo el webhook espera: ninguna de sus entregas sale hasta retryAt
+	require.NoError(t, repo.RecordFailure(ctx, web.ID, 2, at, at.Add(4*time.Hour)))
+	got, err = repo.GetByID(ctx, web.ID)
+	require.NoError(t, err)
+	if assert.NotNil(t, got.RetryAt) {
+		assert.Equal(t, at.Add(4*time.Hour), *got.RetryAt)
+	}
+	due, err = repo.DueDeliveries(ctx, at.Add(2*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+	due, err = repo.DueDeliveries(ctx, at.Add(4*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Len(t, due, 2)
+
+	require.NoError(t, repo.ResetFailures(ctx, web.ID))
+	got, err = repo.GetByID(ctx, web.ID)
+	require.NoError(t, err)
+	assert.Zero(t, got.Failures)
+	assert.Nil(t, got.RetryAt)
+	assert.True(t, got.Active)
+	due, err = repo.DueDeliveries(ctx, at.Add(2*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Len(t, due, 1)
+
+	// El registro va de la entrega más nueva a la más vieja
+	deliveries, err := repo.ListDeliveries(ctx, erp.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, second.ID, deliveries[0].ID)
+	assert.Equal(t, first.ID, deliveries[1].ID)
+
+	// Borrar el webhook borra sus entregas
+	require.NoError(t, repo.Delete(ctx, erp.ID))
+	assert.ErrorIs(t, repo.Delete(ctx, erp.ID), domain.ErrNotFound)
+	deliveries, err = repo.ListDeliveries(ctx, erp.ID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+}
+
+func TestMemoryWebhookRepository(t *testing.T) {
+	testWebhookRepository(t, infrastructure.NewMemoryWebhookRepository())
+}
+
+func TestSqlWebhookRepository_SQLite(t *testing.T) {
+	testWebhookRepository(t, infrastructure.NewSqlWebhookRepository(openSQLite(t)))
+}
+
+func TestSqlWebhookRepository_Postgres(t *testing.T) {
+	if os.Getenv("POSTGRES_TEST_DSN") == "" {
+		t.Skip("POSTGRES_TEST_DSN not set")
+	}
+	testWebhookRepository(t, infrastructure.NewPostgresWebhookRepository(openPostgres(t)))
+}
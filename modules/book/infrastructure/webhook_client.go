@@ -0,0 +1,47 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultWebhookTimeout es cuánto se espera la respuesta de un receptor
+const DefaultWebhookTimeout = 10 * time.Second
+
+// HTTPWebhookClient envía las entregas de webhooks por HTTP. No sigue redirecciones:
+// un 3xx cuenta como fallo, así la URL configurada es la que recibe.
+type HTTPWebhookClient struct {
+	client *http.Client
+}
+
+func NewHTTPWebhookClient(timeout time.Duration) *HTTPWebhookClient {
+	return &HTTPWebhookClient{client: &http.Client{
+		Timeout: timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// Post envía body a url y devuelve el código de la respuesta
+func (c *HTTPWebhookClient) Post(ctx context.Context, url string, body []byte, headers map[string]string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "api-go-gestion-libros-webhooks")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Leer un poco del cuerpo deja reutilizar la conexión
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,250 @@
+package infrastructure
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"api-go-gestion-libros-hexagonal/shared/database"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SqlWebhookRepository guarda los webhooks en la tabla webhooks (el filtro de eventos
+// como JSON) y sus entregas en webhook_deliveries. Como SqlRevisionRepository, sirve
+// para SQLite/Turso y Postgres cambiando los placeholders.
+type SqlWebhookRepository struct {
+	db     *sql.DB
+	rebind func(string) string
+}
+
+// NewSqlWebhookRepository crea el repositorio para SQLite y Turso
+func NewSqlWebhookRepository(db *sql.DB) *SqlWebhookRepository {
+	return &SqlWebhookRepository{db: db, rebind: func(q string) string { return q }}
+}
+
+// NewPostgresWebhookRepository crea el repositorio para PostgreSQL ($n en lugar de ?)
+func NewPostgresWebhookRepository(db *sql.DB) *SqlWebhookRepository {
+	return &SqlWebhookRepository{db: db, rebind: database.Rebind}
+}
+
+// conn devuelve la transacción abierta por database.TxManager, si ctx trae una
+func (r *SqlWebhookRepository) conn(ctx context.Context) database.Conn {
+	return database.ConnFrom(ctx, r.db)
+}
+
+const webhookColumns = `id, url, events, secret, active, failures, disabled_at, retry_at, created_at, updated_at`
+
+const deliveryColumns = `d.id, d.webhook_id, d.event_id, d.event_name, d.payload, d.status, d.attempts,
+	d.response_status, d.last_error, d.next_attempt_at, d.created_at, d.delivered_at, d.redelivery_of`
+
+func (r *SqlWebhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return err
+	}
+	q := `INSERT INTO webhooks (url, events, secret, active, failures, disabled_at, retry_at, created_at, updated_at)
+	      VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`
+	var id int64
+	err = r.conn(ctx).QueryRowContext(ctx, r.rebind(q),
+		webhook.URL, string(events), webhook.Secret, webhook.Active, webhook.Failures, webhook.DisabledAt,
+		webhook.RetryAt, webhook.CreatedAt.UTC(), webhook.UpdatedAt.UTC(),
+	).Scan(&id)
+	if err != nil {
+		return err
+	}
+	webhook.ID = uint(id)
+	return nil
+}
+
+func (r *SqlWebhookRepository) GetByID(ctx context.Context, id uint) (*domain.Webhook, error) {
+	q := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = ?`
+	webhooks, err := r.queryWebhooks(ctx, q, int64(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(webhooks) == 0 {
+		return nil, fmt.Errorf("webhook %d %w", id, domain.ErrNotFound)
+	}
+	return webhooks[0], nil
+}
+
+// List obtiene todos los webhooks, por orden de alta
+func (r *SqlWebhookRepository) List(ctx context.Context) ([]*domain.Webhook, error) {
+	return r.queryWebhooks(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id`)
+}
+
+func (r *SqlWebhookRepository) Update(ctx context.Context, webhook *domain.Webhook) error {
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return err
+	}
+	q := `UPDATE webhooks SET url = ?, events = ?, secret = ?, active = ?, failures = ?, disabled_at = ?, retry_at = ?,
+	        updated_at = ?
+	      WHERE id = ?`
+	return r.exec(ctx, "webhook", webhook.ID, q, webhook.URL, string(events), webhook.Secret, webhook.Active,
+		webhook.Failures, webhook.DisabledAt, webhook.RetryAt, webhook.UpdatedAt.UTC())
+}
+
+// Delete elimina el webhook y sus entregas. Las entregas se borran a mano: Turso no
+// siempre aplica ON DELETE CASCADE.
+func (r *SqlWebhookRepository) Delete(ctx context.Context, id uint) error {
+	if _, err := r.conn(ctx).ExecContext(ctx, r.rebind(`DELETE FROM webhook_deliveries WHERE webhook_id = ?`), int64(id)); err != nil {
+		return err
+	}
+	return r.exec(ctx, "webhook", id, `DELETE FROM webhooks WHERE id = ?`)
+}
+
+// RecordFailure suma un fallo seguido, hace esperar al webhook hasta retryAt y lo
+// desactiva si llega a disableAfter. Las expresiones del SET ven los valores de antes
+// del UPDATE.
+func (r *SqlWebhookRepository) RecordFailure(ctx context.Context, id uint, disableAfter int, at, retryAt time.Time) error {
+	q := `UPDATE webhooks SET
+	        failures = failures + 1,
+	        retry_at = ?,
+	        disabled_at = CASE WHEN active AND failures + 1 >= ? THEN ? ELSE disabled_at END,
+	        active = CASE WHEN failures + 1 >= ? THEN FALSE ELSE active END
+	      WHERE id = ?`
+	return r.exec(ctx, "webhook", id, q, retryAt.UTC(), disableAfter, at.UTC(), disableAfter)
+}
+
+func (r *SqlWebhookRepository) ResetFailures(ctx context.Context, id uint) error {
+	return r.exec(ctx, "webhook", id, `UPDATE webhooks SET failures = 0, retry_at = NULL WHERE id = ?`)
+}
+
+// AddDelivery guarda una entrega nueva; con un evento repetido no hace nada y deja delivery.ID
+// en 0. Los reenvíos no chocan con el índice único, que solo cubre las originales.
+func (r *SqlWebhookRepository) AddDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	q := `INSERT INTO webhook_deliveries (webhook_id, event_id, event_name, payload, status, next_attempt_at, created_at, redelivery_of)
+	      VALUES (?, ?, ?, ?, ?, ?, ?, ?) ON CONFLICT (webhook_id, event_id) WHERE redelivery_of IS NULL DO NOTHING RETURNING id`
+	var redeliveryOf sql.NullInt64
+	if delivery.RedeliveryOf != 0 {
+		redeliveryOf = sql.NullInt64{Int64: int64(delivery.RedeliveryOf), Valid: true}
+	}
+	var id int64
+	err := r.conn(ctx).QueryRowContext(ctx, r.rebind(q),
+		int64(delivery.WebhookID), delivery.EventID, delivery.EventName, string(delivery.Payload), delivery.Status,
+		delivery.NextAttemptAt.UTC(), delivery.CreatedAt.UTC(), redeliveryOf,
+	).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	delivery.ID = uint(id)
+	return nil
+}
+
+func (r *SqlWebhookRepository) GetDelivery(ctx context.Context, webhookID, id uint) (*domain.WebhookDelivery, error) {
+	q := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries d WHERE d.webhook_id = ? AND d.id = ?`
+	deliveries, err := r.queryDeliveries(ctx, q, int64(webhookID), int64(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(deliveries) == 0 {
+		return nil, fmt.Errorf("delivery %d of webhook %d %w", id, webhookID, domain.ErrNotFound)
+	}
+	return deliveries[0], nil
+}
+
+// DueDeliveries obtiene hasta limit entregas pendientes con next_attempt_at <= now de
+// webhooks activos que no están esperando, por orden de llegada
+func (r *SqlWebhookRepository) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	q := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries d
+	      JOIN webhooks w ON w.id = d.webhook_id
+	      WHERE d.status = ? AND w.active = ? AND d.next_attempt_at <= ? AND (w.retry_at IS NULL OR w.retry_at <= ?)
+	      ORDER BY d.id LIMIT ?`
+	return r.queryDeliveries(ctx, q, domain.DeliveryPending, true, now.UTC(), now.UTC(), limit)
+}
+
+func (r *SqlWebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	q := `UPDATE webhook_deliveries SET status = ?, attempts = ?, response_status = ?, last_error = ?,
+	        next_attempt_at = ?, delivered_at = ?
+	      WHERE id = ?`
+	return r.exec(ctx, "delivery", delivery.ID, q, delivery.Status, delivery.Attempts, delivery.ResponseStatus,
+		delivery.LastError, delivery.NextAttemptAt.UTC(), delivery.DeliveredAt)
+}
+
+// ListDeliveries obtiene hasta limit entregas de un webhook, de la más nueva a la más vieja
+func (r *SqlWebhookRepository) ListDeliveries(ctx context.Context, webhookID uint, limit int) ([]*domain.WebhookDelivery, error) {
+	q := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries d WHERE d.webhook_id = ? ORDER BY d.id DESC LIMIT ?`
+	return r.queryDeliveries(ctx, q, int64(webhookID), limit)
+}
+
+// exec corre un UPDATE o DELETE de una fila de kind; ErrNotFound si no existe
+func (r *SqlWebhookRepository) exec(ctx context.Context, kind string, id uint, q string, args ...any) error {
+	res, err := r.conn(ctx).ExecContext(ctx, r.rebind(q), append(args, int64(id))...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%s %d %w", kind, id, domain.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *SqlWebhookRepository) queryWebhooks(ctx context.Context, q string, args ...any) ([]*domain.Webhook, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, r.rebind(q), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []*domain.Webhook{}
+	for rows.Next() {
+		var (
+			w                    domain.Webhook
+			id                   int64
+			events               string
+			disabledAt, retryAt  sql.NullTime
+			createdAt, updatedAt time.Time
+		)
+		err := rows.Scan(&id, &w.URL, &events, &w.Secret, &w.Active, &w.Failures, &disabledAt, &retryAt, &createdAt, &updatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(events), &w.Events); err != nil {
+			return nil, err
+		}
+		w.ID, w.DisabledAt, w.RetryAt = uint(id), nullTime(disabledAt), nullTime(retryAt)
+		w.CreatedAt, w.UpdatedAt = createdAt.UTC(), updatedAt.UTC()
+		out = append(out, &w)
+	}
+	return out, rows.Err()
+}
+
+func (r *SqlWebhookRepository) queryDeliveries(ctx context.Context, q string, args ...any) ([]*domain.WebhookDelivery, error) {
+	rows, err := r.conn(ctx).QueryContext(ctx, r.rebind(q), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []*domain.WebhookDelivery{}
+	for rows.Next() {
+		var (
+			d                        domain.WebhookDelivery
+			id, webhookID            int64
+			payload                  string
+			nextAttemptAt, createdAt time.Time
+			deliveredAt              sql.NullTime
+			redeliveryOf             sql.NullInt64
+		)
+		err := rows.Scan(&id, &webhookID, &d.EventID, &d.EventName, &payload, &d.Status, &d.Attempts,
+			&d.ResponseStatus, &d.LastError, &nextAttemptAt, &createdAt, &deliveredAt, &redeliveryOf)
+		if err != nil {
+			return nil, err
+		}
+		d.ID, d.WebhookID, d.Payload = uint(id), uint(webhookID), []byte(payload)
+		d.RedeliveryOf = uint(redeliveryOf.Int64)
+		d.NextAttemptAt, d.CreatedAt, d.DeliveredAt = nextAttemptAt.UTC(), createdAt.UTC(), nullTime(deliveredAt)
+		out = append(out, &d)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,56 @@
+package presentation
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminHandler expone el estado interno del servicio para operación
+type AdminHandler struct {
+	outbox application.OutboxServiceInterface
+}
+
+func NewAdminHandler(outbox application.OutboxServiceInterface) *AdminHandler {
+	return &AdminHandler{outbox: outbox}
+}
+
+// OutboxBacklog muestra los eventos que faltan entregar: GET /outbox?limit=50
+func (h *AdminHandler) OutboxBacklog(c *fiber.Ctx) error {
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		return respondBadRequest(c, err)
+	}
+
+	backlog, err := h.outbox.Backlog(c.UserContext(), limit)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	messages := make([]OutboxMessageResponse, len(backlog.Messages))
+	for i, msg := range backlog.Messages {
+		messages[i] = OutboxMessageResponse{
+			ID:        msg.ID,
+			DedupeKey: msg.DedupeKey,
+			Event:     msg.EventName,
+			BookID:    msg.BookID,
+			Attempts:  msg.Attempts,
+			LastError: msg.LastError,
+			CreatedAt: msg.CreatedAt,
+			FailedAt:  msg.FailedAt,
+		}
+		if msg.FailedAt == nil {
+			next := msg.AvailableAt
+			messages[i].NextAttemptAt = &next
+		}
+	}
+	return c.JSON(Response{
+		Success: true,
+		Data: OutboxBacklogResponse{
+			Pending:         backlog.Pending,
+			Failed:          backlog.Failed,
+			OldestPendingAt: backlog.OldestPendingAt,
+			Messages:        messages,
+		},
+	})
+}
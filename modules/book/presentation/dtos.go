@@ -1,6 +1,9 @@
 package presentation
 
-import "time"
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"time"
+)
 
 // CreateBookRequest define la estructura para crear un libro via API
 
@@ -32,14 +35,43 @@ type BookResponse struct {
 	ISBN      string    `json:"isbn"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	Version   uint      `json:"version"` // igual al ETag; se envía en If-Match al actualizar
+
+	// Solo en la papelera (GET /trash)
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// Solo en búsquedas de texto libre (q=)
+	Score      *float64          `json:"score,omitempty"`
+	Highlights map[string]string `json:"highlights,omitempty"`
 }
 
-//BookFilterRequest define la estructura para filtrar libros via API
+//BookFilterRequest define la estructura para filtrar libros via API.
+// Se llena desde el body (POST /search) o desde la query string (GET /search)
 type BookFilterRequest struct {
+	Q      string  `json:"q,omitempty"` // texto libre sobre título, autor y género
 	Title  *string `json:"title,omitempty"`
 	Author *string `json:"author,omitempty"`
 	Year   *uint   `json:"year,omitempty"`
 	Genre  *string `json:"genre,omitempty"`
+
+	YearFrom *uint    `json:"year_from,omitempty"`
+	YearTo   *uint    `json:"year_to,omitempty"`
+	Genres   []string `json:"genres,omitempty"`
+
+	ISBNPrefix *string `json:"isbn_prefix,omitempty"`
+
+	// Fechas RFC3339 (2024-01-31T10:00:00Z) o solo fecha (2024-01-31)
+	CreatedAfter  *string `json:"created_after,omitempty"`
+	CreatedBefore *string `json:"created_before,omitempty"`
+	UpdatedSince  *string `json:"updated_since,omitempty"`
+	UpdatedBefore *string `json:"updated_before,omitempty"`
+
+	Limit  int    `json:"limit,omitempty"`
+	Offset int    `json:"offset,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
+	Sort   string `json:"sort,omitempty"` // ej: "-year,title"
+
+	Facets bool `json:"facets,omitempty"` // incluir conteos por género, década y autor
 }
 
 // Response estándar para todas las APIs
@@ -49,10 +81,130 @@ type Response struct {
 	Data    interface{} `json:"data,omitempty"`
 	Message string      `json:"message,omitempty"`
 	Error   string      `json:"error,omitempty"`
+
+	// Metadatos de paginación, solo presentes en listados
+	Total      *int   `json:"total,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    *bool  `json:"has_more,omitempty"`
+
+	// "Quizás quisiste decir": solo cuando una búsqueda no encontró nada
+	Suggestions []SuggestionResponse `json:"suggestions,omitempty"`
+
+	// Conteos por faceta para la búsqueda actual: {"genre":{"Ficción":120}}
+	Facets map[string]map[string]int `json:"facets,omitempty"`
+}
+
+// AutocompleteResponse es un valor sugerido al escribir y cuántos libros lo tienen
+type AutocompleteResponse struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// SuggestionResponse es un valor del catálogo parecido al buscado
+type SuggestionResponse struct {
+	Field string  `json:"field"`
+	Value string  `json:"value"`
+	Score float64 `json:"score"`
+}
+
+// RevisionResponse es un cambio del historial de un libro
+type RevisionResponse struct {
+	Revision   uint                  `json:"revision"`
+	Action     string                `json:"action"` // create, update, delete o revert
+	Actor      string                `json:"actor"`
+	Changes    []FieldChangeResponse `json:"changes"`
+	RevertedTo uint                  `json:"reverted_to,omitempty"` // revisión restaurada por un revert
+	CreatedAt  time.Time             `json:"created_at"`
+}
+
+// FieldChangeResponse es el cambio de un campo; from está vacío al crear y to al borrar
+type FieldChangeResponse struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// OutboxBacklogResponse resume los eventos que faltan entregar
+type OutboxBacklogResponse struct {
+	Pending         int                     `json:"pending"`
+	Failed          int                     `json:"failed"` // ya no se reintentan
+	OldestPendingAt *time.Time              `json:"oldest_pending_at"`
+	Messages        []OutboxMessageResponse `json:"messages"`
+}
+
+// OutboxMessageResponse es un evento del outbox; next_attempt_at no viene si falló
+type OutboxMessageResponse struct {
+	ID            uint       `json:"id"`
+	DedupeKey     string     `json:"dedupe_key"`
+	Event         string     `json:"event"`
+	BookID        uint       `json:"book_id"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	FailedAt      *time.Time `json:"failed_at,omitempty"`
+}
+
+// WebhookRequest define la estructura para crear o actualizar un webhook via API;
+// los campos que no vienen no cambian
+type WebhookRequest struct {
+	URL    *string  `json:"url,omitempty"`
+	Events []string `json:"events,omitempty"` // book.created, ... o "*"; vacío recibe todos
+	Secret *string  `json:"secret,omitempty"` // al crear, si no viene se genera
+	Active *bool    `json:"active,omitempty"`
+}
+
+func (r WebhookRequest) toDomain() domain.WebhookInput {
+	return domain.WebhookInput{URL: r.URL, Events: r.Events, Secret: r.Secret, Active: r.Active}
+}
+
+// WebhookResponse define la estructura para devolver un webhook via API
+type WebhookResponse struct {
+	ID         uint       `json:"id"`
+	URL        string     `json:"url"`
+	Events     []string   `json:"events"`
+	Active     bool       `json:"active"`
+	Failures   int        `json:"failures"`              // intentos fallidos seguidos
+	DisabledAt *time.Time `json:"disabled_at,omitempty"` // desactivado por fallos
+	RetryAt    *time.Time `json:"retry_at,omitempty"`    // esperando tras el último fallo
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	// Solo al crear: con él se verifica X-Signature
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookDeliveryResponse es una entrada del registro de entregas de un webhook
+type WebhookDeliveryResponse struct {
+	ID             uint       `json:"id"`
+	WebhookID      uint       `json:"webhook_id"`
+	EventID        string     `json:"event_id"`
+	Event          string     `json:"event"`
+	Status         string     `json:"status"` // pending, delivered o failed
+	Attempts       int        `json:"attempts"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	RedeliveryOf   uint       `json:"redelivery_of,omitempty"` // entrega original de un reenvío
+}
+
+// ProblemResponse es el cuerpo de un error (RFC 7807, application/problem+json)
+type ProblemResponse struct {
+	Type     string `json:"type"`     // URI que identifica la clase de error
+	Title    string `json:"title"`    // resumen fijo de esa clase
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"` // ruta que produjo el error
+
+	// Un elemento por campo inválido, para marcarlo en el formulario
+	Errors []FieldErrorResponse `json:"errors,omitempty"`
 }
 
-// ErrorResponse para errores detallados
-type ErrorResponse struct {
-	Success bool     `json:"success"`
-	Errors  []string `json:"errors"`
+// FieldErrorResponse es un campo inválido: su nombre en el JSON o la query string,
+// un código estable (domain.Code*) y un mensaje legible
+type FieldErrorResponse struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
 }
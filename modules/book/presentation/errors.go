@@ -0,0 +1,167 @@
+package presentation
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// problemContentType es el tipo de contenido de las respuestas de error (RFC 7807)
+const problemContentType = "application/problem+json"
+
+// errIfMatchRequired es el detalle del 428 de un PUT sin If-Match
+var errIfMatchRequired = errors.New("send the book's ETag in If-Match to update it")
+
+// problemKind es el type y el title de un ProblemResponse según su código HTTP
+type problemKind struct {
+	typ   string
+	title string
+}
+
+var problemKinds = map[int]problemKind{
+	fiber.StatusBadRequest:           {"/problems/bad-request", "Malformed request"},
+	fiber.StatusNotFound:             {"/problems/not-found", "Resource not found"},
+	fiber.StatusConflict:             {"/problems/conflict", "Conflict with the current state"},
+	fiber.StatusPreconditionFailed:   {"/problems/version-mismatch", "Book was modified by someone else"},
+	fiber.StatusPreconditionRequired: {"/problems/precondition-required", "If-Match header is required"},
+	fiber.StatusUnprocessableEntity:  {"/problems/validation", "Validation failed"},
+	fiber.StatusInternalServerError:  {"/problems/internal", "Internal server error"},
+}
+
+// errorStatus traduce un error de los casos de uso a su código HTTP
+func errorStatus(err error) int {
+	var (
+		validation  *domain.ValidationError
+		syntax      *domain.QuerySyntaxError
+		fieldErrors validator.ValidationErrors
+	)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, domain.ErrDuplicateISBN), errors.Is(err, domain.ErrConflict):
+		return fiber.StatusConflict
+	case errors.Is(err, domain.ErrVersionMismatch):
+		return fiber.StatusPreconditionFailed
+	case errors.As(err, &validation), errors.As(err, &syntax), errors.As(err, &fieldErrors):
+		return fiber.StatusUnprocessableEntity
+	}
+	return fiber.StatusInternalServerError
+}
+
+// respondError responde un error de los casos de uso con el código que le corresponde
+func respondError(c *fiber.Ctx, err error) error {
+	return respondProblem(c, errorStatus(err), err)
+}
+
+// respondBadRequest responde 400 a una petición que no se pudo leer (JSON, id, query string)
+func respondBadRequest(c *fiber.Ctx, err error) error {
+	return respondProblem(c, fiber.StatusBadRequest, err)
+}
+
+// respondProblem escribe err como application/problem+json.
+// Los errores inesperados (500) se registran y no se muestran al cliente.
+func respondProblem(c *fiber.Ctx, status int, err error) error {
+	kind, ok := problemKinds[status]
+	if !ok {
+		// Sin clase propia: about:blank con la frase del código (RFC 7807, 4.2)
+		kind = problemKind{"about:blank", utils.StatusMessage(status)}
+	}
+	problem := ProblemResponse{
+		Type:     kind.typ,
+		Title:    kind.title,
+		Status:   status,
+		Detail:   err.Error(),
+		Instance: c.Path(),
+	}
+	if status == fiber.StatusInternalServerError {
+		log.Printf("%s %s: %v", c.Method(), c.Path(), err)
+		problem.Detail = ""
+	} else if problem.Errors = fieldErrors(err); len(problem.Errors) > 0 {
+		// El texto de validator no sirve al cliente; el detalle repite los mensajes de cada campo
+		messages := make([]string, len(problem.Errors))
+		for i, fe := range problem.Errors {
+			messages[i] = fe.Message
+		}
+		problem.Detail = strings.Join(messages, "; ")
+	}
+	return c.Status(status).JSON(problem, problemContentType)
+}
+
+// ErrorHandler es el manejador de errores de Fiber: los errores que no atienden los
+// handlers (ruta inexistente, método no permitido, pánico) también salen como problem+json
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return respondProblem(c, fiberErr.Code, err)
+	}
+	return respondProblem(c, fiber.StatusInternalServerError, err)
+}
+
+// fieldErrors extrae los campos inválidos de err, sea del validator o del dominio
+func fieldErrors(err error) []FieldErrorResponse {
+	var (
+		many       domain.ValidationErrors
+		validation *domain.ValidationError
+		syntax     *domain.QuerySyntaxError
+		tagged     validator.ValidationErrors
+	)
+	switch {
+	case errors.As(err, &many):
+		out := make([]FieldErrorResponse, len(many))
+		for i, v := range many {
+			out[i] = FieldErrorResponse{Field: v.Field, Code: v.Code, Message: v.Message}
+		}
+		return out
+	case errors.As(err, &tagged):
+		return fieldErrors(validatorErrors(tagged))
+	case errors.As(err, &validation):
+		return []FieldErrorResponse{{Field: validation.Field, Code: validation.Code, Message: validation.Message}}
+	case errors.As(err, &syntax):
+		return []FieldErrorResponse{{Field: "q", Code: domain.CodeSyntax, Message: syntax.Error()}}
+	}
+	return nil
+}
+
+// validatorErrors traduce las reglas de validate:"..." a errores del dominio.
+// fe.Field() es el nombre JSON del campo (ver jsonFieldName).
+func validatorErrors(tagged validator.ValidationErrors) domain.ValidationErrors {
+	out := make(domain.ValidationErrors, len(tagged))
+	for i, fe := range tagged {
+		field := fe.Field()
+		switch {
+		case fe.Tag() == "required":
+			out[i] = &domain.ValidationError{Field: field, Code: domain.CodeRequired, Message: field + " is required"}
+		case fe.Tag() == "min" && fe.Kind() == reflect.String:
+			out[i] = &domain.ValidationError{Field: field, Code: domain.CodeEmpty, Message: field + " cannot be empty"}
+		case fe.Tag() == "min":
+			out[i] = &domain.ValidationError{Field: field, Code: domain.CodeOutOfRange, Message: fmt.Sprintf("%s must be at least %s", field, fe.Param())}
+		default:
+			out[i] = &domain.ValidationError{Field: field, Code: domain.CodeInvalidValue, Message: field + " is invalid"}
+		}
+	}
+	return out
+}
+
+// jsonFieldName hace que validator nombre los campos como en el JSON ("year", no "Year")
+func jsonFieldName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// invalidParam es el error de un parámetro de la URL o la query string que no se pudo leer
+func invalidParam(key, raw string) error {
+	return &domain.ValidationError{Field: key, Code: domain.CodeInvalidFormat, Message: fmt.Sprintf("invalid %s: %q", key, raw)}
+}
@@ -0,0 +1,35 @@
+package presentation
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Concurrencia optimista por HTTP: el ETag de un libro es su versión ("3") y
+// PUT exige If-Match con la versión que el cliente leyó.
+
+// setETag pone la versión de book en la cabecera ETag
+func setETag(c *fiber.Ctx, book *domain.Book) {
+	c.Set(fiber.HeaderETag, strconv.Quote(strconv.FormatUint(uint64(book.Version), 10)))
+}
+
+// parseIfMatch lee la versión esperada de If-Match. "*" acepta cualquier versión
+// (domain.AnyVersion). Los ETag débiles (W/"3") no sirven: If-Match compara fuerte.
+func parseIfMatch(raw string) (uint, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "*" {
+		return domain.AnyVersion, nil
+	}
+	unquoted, err := strconv.Unquote(raw)
+	if err != nil || !strings.HasPrefix(raw, `"`) {
+		return 0, invalidParam("If-Match", raw)
+	}
+	version, err := strconv.ParseUint(unquoted, 10, 32)
+	if err != nil || version == 0 {
+		return 0, invalidParam("If-Match", raw)
+	}
+	return uint(version), nil
+}
@@ -3,8 +3,10 @@ package presentation
 import (
 	"api-go-gestion-libros-hexagonal/modules/book/application"
 	"api-go-gestion-libros-hexagonal/modules/book/domain"
-	"context"
+	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -16,12 +18,24 @@ type BookHandler struct {
 }
 
 func NewBookHandler(bookService application.BookServiceInterface) *BookHandler {
+	v := validator.New()
+	v.RegisterTagNameFunc(jsonFieldName)
 	return &BookHandler{
 		bookService: bookService,
-		validator:   validator.New(),
+		validator:   v,
 	}
 }
 
+// parseID lee el parámetro :id de la ruta
+func parseID(c *fiber.Ctx) (uint, error) {
+	raw := c.Params("id")
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		return 0, invalidParam("id", raw)
+	}
+	return uint(id), nil
+}
+
 // Helper functions para convertir entre DTOs y Domain
 func domainToResponse(book *domain.Book) *BookResponse {
 	return &BookResponse{
@@ -33,6 +47,8 @@ func domainToResponse(book *domain.Book) *BookResponse {
 		ISBN:      book.ISBN,
 		CreatedAt: book.CreatedAt,
 		UpdatedAt: book.UpdatedAt,
+		Version:   book.Version,
+		DeletedAt: book.DeletedAt,
 	}
 }
 
@@ -47,40 +63,129 @@ func requestToDomain(req CreateBookRequest) domain.UpdateBookInput {
 	}
 }
 
-func filterRequestToDomain(req BookFilterRequest) domain.BookFilter {
+func filterRequestToDomain(req BookFilterRequest) (domain.BookFilter, error) {
+	sort, err := domain.ParseSort(req.Sort)
+	if err != nil {
+		return domain.BookFilter{}, err
+	}
+	createdAfter, err := parseTimePtr("created_after", req.CreatedAfter)
+	if err != nil {
+		return domain.BookFilter{}, err
+	}
+	createdBefore, err := parseTimePtr("created_before", req.CreatedBefore)
+	if err != nil {
+		return domain.BookFilter{}, err
+	}
+	updatedSince, err := parseTimePtr("updated_since", req.UpdatedSince)
+	if err != nil {
+		return domain.BookFilter{}, err
+	}
+	updatedBefore, err := parseTimePtr("updated_before", req.UpdatedBefore)
+	if err != nil {
+		return domain.BookFilter{}, err
+	}
 	return domain.BookFilter{
+		Query:  req.Q,
 		Title:  req.Title,
 		Author: req.Author,
 		Year:   req.Year,
 		Genre:  req.Genre,
+
+		YearFrom: req.YearFrom,
+		YearTo:   req.YearTo,
+		Genres:   req.Genres,
+
+		ISBNPrefix:    req.ISBNPrefix,
+		CreatedAfter:  createdAfter,
+		CreatedBefore: createdBefore,
+		UpdatedSince:  updatedSince,
+		UpdatedBefore: updatedBefore,
+
+		Limit:  req.Limit,
+		Offset: req.Offset,
+		Cursor: req.Cursor,
+		Sort:   sort,
+	}, nil
+}
+
+// pageToResponse arma la respuesta de un listado con sus metadatos de paginación
+func pageToResponse(page *domain.BookPage) Response {
+	responses := make([]BookResponse, len(page.Books))
+	for i, book := range page.Books {
+		responses[i] = *domainToResponse(book)
+		if match, ok := page.Matches[book.ID]; ok {
+			score := match.Score
+			responses[i].Score = &score
+			responses[i].Highlights = match.Highlights
+		}
 	}
+	total := page.Total
+	hasMore := page.HasMore
+	resp := Response{
+		Success:    true,
+		Data:       responses,
+		Total:      &total,
+		NextCursor: page.NextCursor,
+		HasMore:    &hasMore,
+	}
+	for _, s := range page.Suggestions {
+		resp.Suggestions = append(resp.Suggestions, SuggestionResponse{Field: s.Field, Value: s.Value, Score: s.Score})
+	}
+	return resp
+}
+
+// validateCreate aplica las reglas de validate:"..." y, si alguna falla, suma las del
+// dominio de los demás campos, para responder todos los campos inválidos de una vez.
+// Si el request pasa, las reglas del dominio las aplica el caso de uso.
+func (h *BookHandler) validateCreate(req CreateBookRequest) error {
+	var tagged validator.ValidationErrors
+	if err := h.validator.Struct(&req); err == nil {
+		return nil
+	} else if !errors.As(err, &tagged) {
+		return err
+	}
+
+	errs := validatorErrors(tagged)
+	var domainErrs domain.ValidationErrors
+	book := domain.NewBook(req.Title, req.Author, uint(max(req.Year, 0)), req.Genre, req.ISBN)
+	if errors.As(book.ValidateBasic(), &domainErrs) {
+		for _, e := range domainErrs {
+			if !errs.Has(e.Field) {
+				errs = append(errs, e)
+			}
+		}
+	}
+	return errs
+}
+
+// ActorHeader es la cabecera con quién hace la petición; queda como autor en el historial
+const ActorHeader = "X-Actor"
+
+// withActor pasa el autor de la petición al contexto de los casos de uso
+func withActor(c *fiber.Ctx) error {
+	if actor := strings.TrimSpace(c.Get(ActorHeader)); actor != "" {
+		c.SetUserContext(application.WithActor(c.UserContext(), actor))
+	}
+	return c.Next()
 }
 
 // HTTP Handlers
 func (h *BookHandler) CreateBook(c *fiber.Ctx) error {
 	var req CreateBookRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Success: false,
-			Errors:  []string{err.Error()},
-		})
+		return respondBadRequest(c, err)
 	}
 
-	if err := h.validator.Struct(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Success: false,
-			Errors:  []string{err.Error()},
-		})
+	if err := h.validateCreate(req); err != nil {
+		return respondError(c, err)
 	}
 
-	book, err := h.bookService.CreateBook(context.Background(), req.Title, req.Author, uint(req.Year), req.Genre, req.ISBN)
+	book, err := h.bookService.CreateBook(c.UserContext(), req.Title, req.Author, uint(req.Year), req.Genre, req.ISBN)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Success: false,
-			Errors:  []string{err.Error()},
-		})
+		return respondError(c, err)
 	}
 
+	setETag(c, book)
 	return c.Status(fiber.StatusCreated).JSON(Response{
 		Success: true,
 		Data:    domainToResponse(book),
@@ -90,23 +195,17 @@ func (h *BookHandler) CreateBook(c *fiber.Ctx) error {
 }
 
 func (h *BookHandler) GetBookById(c *fiber.Ctx) error {
-	idParam := c.Params("id")
-	id, err := strconv.ParseUint(idParam, 10, 32)
+	id, err := parseID(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Success: false,
-			Errors:  []string{"Invalid book ID"},
-		})
+		return respondBadRequest(c, err)
 	}
 
-	book, err := h.bookService.GetBookByID(context.Background(), uint(id))
+	book, err := h.bookService.GetBookByID(c.UserContext(), id)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Success: false,
-			Errors:  []string{err.Error()},
-		})
+		return respondError(c, err)
 	}
 
+	setETag(c, book)
 	return c.JSON(Response{
 		Success: true,
 		Data:    domainToResponse(book),
@@ -117,14 +216,12 @@ func (h *BookHandler) GetBookByISBN(c *fiber.Ctx) error {
 	// Aqui lo que hacemos es obtener el isbn que viene como string
 	isbn := c.Params("isbn")
 	// Aqui lo que hacemos es obtener el libro por isbn
-	book, err := h.bookService.GetBookByISBN(context.Background(), isbn)
+	book, err := h.bookService.GetBookByISBN(c.UserContext(), isbn)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
-			Success: false,
-			Errors:  []string{err.Error()},
-		})
+		return respondError(c, err)
 	}
 
+	setETag(c, book)
 	return c.JSON(Response{
 		Success: true,
 		Data:    domainToResponse(book),
@@ -133,24 +230,26 @@ func (h *BookHandler) GetBookByISBN(c *fiber.Ctx) error {
 
 func (h *BookHandler) UpdateBook(c *fiber.Ctx) error {
 	// Aqui lo que hacemos es obtener el id que viene como string
-	idParam := c.Params("id")
+	id, err := parseID(c)
+	if err != nil {
+		return respondBadRequest(c, err)
+	}
 
-	// Aqui lo que hacemos es convertir el id que viene como string a uint
-	id, err := strconv.ParseUint(idParam, 10, 32)
+	// Aqui lo que hacemos es exigir la versión que el cliente leyó (ETag), para no
+	// pisar cambios de otro
+	ifMatch := c.Get(fiber.HeaderIfMatch)
+	if ifMatch == "" {
+		return respondProblem(c, fiber.StatusPreconditionRequired, errIfMatchRequired)
+	}
+	version, err := parseIfMatch(ifMatch)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Success: false,
-			Errors:  []string{err.Error()},
-		})
+		return respondBadRequest(c, err)
 	}
 
 	// Aqui lo que hacemos es obtener el body que viene como json
 	var req UpdateBookRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Success: false,
-			Errors:  []string{err.Error()},
-		})
+		return respondBadRequest(c, err)
 	}
 
 	// Aqui lo que hacemos es convertir el body que viene como json a domain.UpdateBookInput
@@ -163,15 +262,13 @@ func (h *BookHandler) UpdateBook(c *fiber.Ctx) error {
 	}
 
 	// Aqui lo que hacemos es actualizar el libro
-	book, err := h.bookService.UpdateBook(context.Background(), uint(id), input)
+	book, err := h.bookService.UpdateBook(c.UserContext(), id, version, input)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Success: false,
-			Errors:  []string{err.Error()},
-		})
+		return respondError(c, err)
 	}
 
 	// Aqui lo que hacemos es devolver el libro actualizado
+	setETag(c, book)
 	return c.JSON(Response{
 		Success: true,
 		Data:    domainToResponse(book),
@@ -181,102 +278,227 @@ func (h *BookHandler) UpdateBook(c *fiber.Ctx) error {
 }
 
 func (h *BookHandler) DeleteBook(c *fiber.Ctx) error {
-	idParam := c.Params("id")
-	id, err := strconv.ParseUint(idParam, 10, 32)
+	id, err := parseID(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Success: false,
-			Errors:  []string{err.Error()},
-		})
+		return respondBadRequest(c, err)
 	}
 
-	if err := h.bookService.DeleteBook(context.Background(), uint(id)); err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
-			Success: false,
-			Errors:  []string{err.Error()},
-		})
+	if err := h.bookService.DeleteBook(c.UserContext(), id); err != nil {
+		return respondError(c, err)
 	}
 
 	return c.JSON(Response{
 		Success: true,
-		Message: "book deleted successfully",
+		Message: "book moved to trash",
 	})
 
 }
 
+// SearchBooks busca con filtros en la query string: GET /search?title=...&genres=a&genres=b&year=1940..1960
 func (h *BookHandler) SearchBooks(c *fiber.Ctx) error {
+	req, err := parseFilterQuery(c)
+	if err != nil {
+		return respondBadRequest(c, err)
+	}
+	return h.searchBooks(c, req)
+}
+
+// Autocomplete sugiere valores mientras se escribe: GET /suggest?field=author&prefix=gar&limit=10
+func (h *BookHandler) Autocomplete(c *fiber.Ctx) error {
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		return respondBadRequest(c, err)
+	}
+
+	values, err := h.bookService.Autocomplete(c.UserContext(), c.Query("field"), c.Query("prefix"), limit)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	responses := make([]AutocompleteResponse, len(values))
+	for i, v := range values {
+		responses[i] = AutocompleteResponse{Value: v.Value, Count: v.Count}
+	}
+	return c.JSON(Response{
+		Success: true,
+		Data:    responses,
+	})
+}
+
+// SearchBooksByBody busca con filtros en un body JSON, para consultas complejas: POST /search
+func (h *BookHandler) SearchBooksByBody(c *fiber.Ctx) error {
 	// Aqui lo que hacemos es obtener el body que viene como json
 	var req BookFilterRequest
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Success: false,
-			Errors:  []string{err.Error()},
-		})
+		return respondBadRequest(c, err)
 	}
-	// Aqui lo que hacemos es convertir el body que viene como json a domain.BookFilter
-	filter := filterRequestToDomain(req)
+	return h.searchBooks(c, req)
+}
 
-	books, err := h.bookService.SearchBooks(context.Background(), filter)
+// searchBooks es comun a GET y POST /search: ambos llegan al mismo domain.BookFilter
+func (h *BookHandler) searchBooks(c *fiber.Ctx, req BookFilterRequest) error {
+	// Aqui lo que hacemos es convertir el request a domain.BookFilter
+	filter, err := filterRequestToDomain(req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-			Success: false,
-			Errors:  []string{err.Error()},
-		})
+		return respondBadRequest(c, err)
 	}
-	// Convertir a response
-	responses := make([]BookResponse, len(books))
-	for i, book := range books {
-		responses[i] = *domainToResponse(book)
+
+	page, err := h.bookService.SearchBooks(c.UserContext(), filter)
+	if err != nil {
+		return respondError(c, err)
+	}
+	resp := pageToResponse(page)
+
+	// Facetas opcionales para la barra lateral de filtros
+	if req.Facets {
+		facets, err := h.bookService.GetFacets(c.UserContext(), filter)
+		if err != nil {
+			return respondError(c, err)
+		}
+		resp.Facets = map[string]map[string]int{
+			"genre":  facets.Genre,
+			"decade": facets.Decade,
+			"author": facets.Author,
+		}
+	}
+
+	return c.JSON(resp)
+}
+
+func (h *BookHandler) GetBookByID(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return respondBadRequest(c, err)
+	}
+
+	book, err := h.bookService.GetBookByID(c.UserContext(), id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	setETag(c, book)
+	return c.JSON(Response{
+		Success: true,
+		Data:    domainToResponse(book),
+	})
+}
+
+func (h *BookHandler) GetAllBooks(c *fiber.Ctx) error {
+	// Paginación y orden por query string: ?limit=20&offset=40&sort=-year,title o ?limit=20&cursor=...
+	query, err := parseFilterQuery(c)
+	if err != nil {
+		return respondBadRequest(c, err)
+	}
+	return h.searchBooks(c, BookFilterRequest{
+		Limit:  query.Limit,
+		Offset: query.Offset,
+		Cursor: query.Cursor,
+		Sort:   query.Sort,
+	})
+}
+
+// GetBookHistory lista quién cambió qué y cuándo: GET /:id/history
+func (h *BookHandler) GetBookHistory(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return respondBadRequest(c, err)
 	}
 
+	revs, err := h.bookService.GetBookHistory(c.UserContext(), id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	responses := make([]RevisionResponse, len(revs))
+	for i, rev := range revs {
+		responses[i] = revisionToResponse(rev)
+	}
 	return c.JSON(Response{
 		Success: true,
 		Data:    responses,
 	})
 }
 
-func (h *BookHandler) GetBookByID(c *fiber.Ctx) error {
-	idParam := c.Params("id")
-	id, err := strconv.ParseUint(idParam, 10, 32)
+// RevertBook vuelve un libro a los valores de una revisión: POST /:id/revert/:revision.
+// If-Match es opcional: si viene, el libro tiene que seguir en esa versión.
+func (h *BookHandler) RevertBook(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return respondBadRequest(c, err)
+	}
+	rawRevision := c.Params("revision")
+	revision, err := strconv.ParseUint(rawRevision, 10, 32)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(ErrorResponse{
-			Success: false,
-			Errors:  []string{"Invalid book ID"},
-		})
+		return respondBadRequest(c, invalidParam("revision", rawRevision))
+	}
+	version := domain.AnyVersion
+	if ifMatch := c.Get(fiber.HeaderIfMatch); ifMatch != "" {
+		if version, err = parseIfMatch(ifMatch); err != nil {
+			return respondBadRequest(c, err)
+		}
 	}
 
-	book, err := h.bookService.GetBookByID(context.Background(), uint(id))
+	book, err := h.bookService.RevertBook(c.UserContext(), id, uint(revision), version)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(ErrorResponse{
-			Success: false,
-			Errors:  []string{err.Error()},
-		})
+		return respondError(c, err)
 	}
 
+	setETag(c, book)
 	return c.JSON(Response{
 		Success: true,
 		Data:    domainToResponse(book),
+		Message: fmt.Sprintf("book reverted to revision %d", revision),
 	})
 }
 
-func (h *BookHandler) GetAllBooks(c *fiber.Ctx) error {
-	books, err := h.bookService.SearchBooks(context.Background(), domain.BookFilter{})
+// ListTrash lista los libros borrados que se pueden restaurar: GET /trash
+func (h *BookHandler) ListTrash(c *fiber.Ctx) error {
+	books, err := h.bookService.ListTrash(c.UserContext())
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(ErrorResponse{
-			Success: false,
-			Errors:  []string{err.Error()},
-		})
+		return respondError(c, err)
 	}
 
-	// Convertir a response
-	responses := make([]BookResponse, len(books))
+	responses := make([]*BookResponse, len(books))
 	for i, book := range books {
-		responses[i] = *domainToResponse(book)
+		responses[i] = domainToResponse(book)
 	}
-
 	return c.JSON(Response{
 		Success: true,
 		Data:    responses,
 	})
+}
+
+// RestoreBook saca un libro de la papelera: POST /:id/restore
+func (h *BookHandler) RestoreBook(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return respondBadRequest(c, err)
+	}
+
+	book, err := h.bookService.RestoreBook(c.UserContext(), id)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	setETag(c, book)
+	return c.JSON(Response{
+		Success: true,
+		Data:    domainToResponse(book),
+		Message: "book restored successfully",
+	})
+}
 
+func revisionToResponse(rev *domain.Revision) RevisionResponse {
+	changes := make([]FieldChangeResponse, len(rev.Changes))
+	for i, ch := range rev.Changes {
+		changes[i] = FieldChangeResponse{Field: ch.Field, From: ch.From, To: ch.To}
+	}
+	return RevisionResponse{
+		Revision:   rev.Number,
+		Action:     rev.Action,
+		Actor:      rev.Actor,
+		Changes:    changes,
+		RevertedTo: rev.RevertedTo,
+		CreatedAt:  rev.CreatedAt,
+	}
 }
@@ -0,0 +1,137 @@
+package presentation
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseFilterQuery arma un BookFilterRequest desde la query string, para que
+// GET /search y POST /search terminen en el mismo filtro.
+//
+// Formatos aceptados:
+//
+//	q=garcia marq                 texto libre, por prefijo y ordenado por relevancia
+//	genres=cuento&genres=ensayo   valores repetidos
+//	genres=cuento,ensayo          o separados por coma
+//	year=1967                     año exacto
+//	year=1940..1960               rango inclusive (también 1940.. y ..1960)
+//	year_from=1940&year_to=1960   rango con parámetros separados (no junto con year=a..b)
+//	created_after=2024-01-31      fechas RFC3339 o solo fecha (UTC)
+//	facets=true                   agrega conteos por género, década y autor
+func parseFilterQuery(c *fiber.Ctx) (BookFilterRequest, error) {
+	var req BookFilterRequest
+	args := c.Context().QueryArgs()
+
+	values := func(key string) []string {
+		out := []string{}
+		for _, raw := range args.PeekMulti(key) {
+			for _, v := range strings.Split(string(raw), ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					out = append(out, v)
+				}
+			}
+		}
+		return out
+	}
+	single := func(key string) *string {
+		if !args.Has(key) {
+			return nil
+		}
+		v := string(args.Peek(key))
+		return &v
+	}
+
+	req.Q = c.Query("q")
+	req.Title = single("title")
+	req.Author = single("author")
+	req.Genre = single("genre")
+	req.Genres = values("genres")
+	req.ISBNPrefix = single("isbn_prefix")
+	req.CreatedAfter = single("created_after")
+	req.CreatedBefore = single("created_before")
+	req.UpdatedSince = single("updated_since")
+	req.UpdatedBefore = single("updated_before")
+	req.Cursor = c.Query("cursor")
+	req.Sort = strings.Join(values("sort"), ",")
+	req.Facets = c.QueryBool("facets")
+
+	var err error
+	if req.YearFrom, err = queryUint(c, "year_from"); err != nil {
+		return req, err
+	}
+	if req.YearTo, err = queryUint(c, "year_to"); err != nil {
+		return req, err
+	}
+	if y := c.Query("year"); y != "" {
+		if from, to, ok := strings.Cut(y, ".."); ok {
+			// Un rango en year no pisa los extremos enviados por separado
+			if req.YearFrom != nil || req.YearTo != nil {
+				return req, invalidParam("year", y)
+			}
+			if req.YearFrom, err = parseUintPtr("year", from); err != nil {
+				return req, err
+			}
+			if req.YearTo, err = parseUintPtr("year", to); err != nil {
+				return req, err
+			}
+		} else if req.Year, err = parseUintPtr("year", y); err != nil {
+			return req, err
+		}
+	}
+
+	if req.Limit, err = queryInt(c, "limit"); err != nil {
+		return req, err
+	}
+	if req.Offset, err = queryInt(c, "offset"); err != nil {
+		return req, err
+	}
+	return req, nil
+}
+
+func queryUint(c *fiber.Ctx, key string) (*uint, error) {
+	return parseUintPtr(key, c.Query(key))
+}
+
+func queryInt(c *fiber.Ctx, key string) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, invalidParam(key, raw)
+	}
+	return v, nil
+}
+
+// parseTimePtr acepta RFC3339 o solo fecha; nil si el valor no viene
+func parseTimePtr(key string, raw *string) (*time.Time, error) {
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return nil, nil
+	}
+	v := strings.TrimSpace(*raw)
+	for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+		if t, err := time.Parse(layout, v); err == nil {
+			t = t.UTC()
+			return &t, nil
+		}
+	}
+	return nil, invalidParam(key, v)
+}
+
+// parseUintPtr devuelve nil si el valor está vacío (extremo abierto de un rango)
+func parseUintPtr(key, raw string) (*uint, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		return nil, invalidParam(key, raw)
+	}
+	u := uint(v)
+	return &u, nil
+}
@@ -3,14 +3,45 @@ package presentation
 import "github.com/gofiber/fiber/v2"
 
 func SetupBookRoutes(app *fiber.App, handler *BookHandler) {
-	api := app.Group("/api/v1/books")
+	api := app.Group("/api/v1/books", withActor)
 
 	// CRUD endpoints
-	api.Post("/", handler.CreateBook)             // POST /api/v1/books
-	api.Get("/", handler.GetAllBooks)             // GET /api/v1/books
-	api.Get("/search", handler.SearchBooks)       // GET /api/v1/books/search?title=...&author=...
-	api.Get("/:id", handler.GetBookByID)          // GET /api/v1/books/123
-	api.Get("/isbn/:isbn", handler.GetBookByISBN) // GET /api/v1/books/isbn/978-3-16-148410-0
-	api.Put("/:id", handler.UpdateBook)           // PUT /api/v1/books/123
-	api.Delete("/:id", handler.DeleteBook)        // DELETE /api/v1/books/123
+	api.Post("/", handler.CreateBook)              // POST /api/v1/books
+	api.Get("/", handler.GetAllBooks)              // GET /api/v1/books
+	api.Get("/search", handler.SearchBooks)        // GET /api/v1/books/search?title=...&author=...
+	api.Post("/search", handler.SearchBooksByBody) // POST /api/v1/books/search (filtros en JSON)
+	api.Get("/suggest", handler.Autocomplete)      // GET /api/v1/books/suggest?field=author&prefix=gar
+	api.Get("/trash", handler.ListTrash)           // GET /api/v1/books/trash (antes de /:id)
+	api.Get("/:id", handler.GetBookByID)           // GET /api/v1/books/123
+	api.Get("/isbn/:isbn", handler.GetBookByISBN)  // GET /api/v1/books/isbn/978-3-16-148410-0
+	api.Put("/:id", handler.UpdateBook)            // PUT /api/v1/books/123
+	api.Delete("/:id", handler.DeleteBook)         // DELETE /api/v1/books/123
+
+	// Historial de cambios
+	api.Get("/:id/history", handler.GetBookHistory)       // GET /api/v1/books/123/history
+	api.Post("/:id/revert/:revision", handler.RevertBook) // POST /api/v1/books/123/revert/2
+
+	// Papelera: DELETE /:id mueve el libro a la papelera hasta que se purga
+	api.Post("/:id/restore", handler.RestoreBook) // POST /api/v1/books/123/restore
+}
+
+// SetupAdminRoutes registra los endpoints de operación, fuera de /books
+func SetupAdminRoutes(app *fiber.App, handler *AdminHandler) {
+	admin := app.Group("/api/v1/admin")
+
+	admin.Get("/outbox", handler.OutboxBacklog) // GET /api/v1/admin/outbox?limit=50
+}
+
+// SetupWebhookRoutes registra la administración de webhooks y su registro de entregas
+func SetupWebhookRoutes(app *fiber.App, handler *WebhookHandler) {
+	api := app.Group("/api/v1/webhooks")
+
+	api.Post("/", handler.CreateWebhook)      // POST /api/v1/webhooks
+	api.Get("/", handler.ListWebhooks)        // GET /api/v1/webhooks
+	api.Get("/:id", handler.GetWebhook)       // GET /api/v1/webhooks/3
+	api.Put("/:id", handler.UpdateWebhook)    // PUT /api/v1/webhooks/3
+	api.Delete("/:id", handler.DeleteWebhook) // DELETE /api/v1/webhooks/3
+
+	api.Get("/:id/deliveries", handler.ListDeliveries)                 // GET /api/v1/webhooks/3/deliveries?limit=50
+	api.Post("/:id/deliveries/:delivery/redeliver", handler.Redeliver) // POST /api/v1/webhooks/3/deliveries/12/redeliver
 }
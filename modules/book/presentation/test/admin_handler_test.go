@@ -0,0 +1,85 @@
+package presentation_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"api-go-gestion-libros-hexagonal/modules/book/presentation"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubOutbox devuelve backlog y guarda el limit pedido
+type stubOutbox struct {
+	backlog *domain.OutboxBacklog
+	limit   int
+}
+
+func (s *stubOutbox) Backlog(ctx context.Context, limit int) (*domain.OutboxBacklog, error) {
+	s.limit = limit
+	return s.backlog, nil
+}
+
+func newAdminApp(outbox *stubOutbox) *fiber.App {
+	app := fiber.New()
+	presentation.SetupAdminRoutes(app, presentation.NewAdminHandler(outbox))
+	return app
+}
+
+func TestOutboxBacklog_ShowsPendingAndFailed(t *testing.T) {
+	// Arrange
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	failedAt := at.Add(time.Hour)
+	outbox := &stubOutbox{backlog: &domain.OutboxBacklog{Pending: 1, Failed: 1, OldestPendingAt: &at,
+		Messages: []*domain.OutboxMessage{
+			{ID: 1, DedupeKey: "book.created:7:1", EventName: domain.EventBookCreated, BookID: 7, Attempts: 2,
+				LastError: "timeout", CreatedAt: at, AvailableAt: at.Add(time.Minute)},
+			{ID: 2, DedupeKey: "book.deleted:7:2", EventName: domain.EventBookDeleted, BookID: 7, Attempts: 10,
+				LastError: "gone", CreatedAt: at, AvailableAt: at, FailedAt: &failedAt},
+		}}}
+	app := newAdminApp(outbox)
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/admin/outbox?limit=20", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 20, outbox.limit)
+	var body struct {
+		Data struct {
+			Pending         int              `json:"pending"`
+			Failed          int              `json:"failed"`
+			OldestPendingAt string           `json:"oldest_pending_at"`
+			Messages        []map[string]any `json:"messages"`
+		} `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, 1, body.Data.Pending)
+	assert.Equal(t, 1, body.Data.Failed)
+	assert.Equal(t, "2024-05-01T12:00:00Z", body.Data.OldestPendingAt)
+	if assert.Len(t, body.Data.Messages, 2) {
+		assert.Equal(t, "book.created:7:1", body.Data.Messages[0]["dedupe_key"])
+		assert.Equal(t, "2024-05-01T12:01:00Z", body.Data.Messages[0]["next_attempt_at"])
+		assert.NotContains(t, body.Data.Messages[0], "failed_at")
+		assert.Equal(t, "2024-05-01T13:00:00Z", body.Data.Messages[1]["failed_at"])
+		assert.NotContains(t, body.Data.Messages[1], "next_attempt_at")
+	}
+}
+
+func TestOutboxBacklog_InvalidLimit(t *testing.T) {
+	// Arrange
+	app := newAdminApp(&stubOutbox{})
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/admin/outbox?limit=many", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,177 @@
+package presentation_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"api-go-gestion-libros-hexagonal/modules/book/presentation"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlers_MapDomainErrorsToStatus(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{"not found", fmt.Errorf("book 7 %w", domain.ErrNotFound), http.MethodGet, "/api/v1/books/7", "", http.StatusNotFound},
+		{"isbn not found", domain.ErrNotFound, http.MethodGet, "/api/v1/books/isbn/9788418037016", "", http.StatusNotFound},
+		{"delete not found", domain.ErrNotFound, http.MethodDelete, "/api/v1/books/7", "", http.StatusNotFound},
+		{"duplicate isbn", fmt.Errorf("%w: 9788418037016", domain.ErrDuplicateISBN), http.MethodPost, "/api/v1/books",
+			`{"title":"Ficciones","author":"Borges","year":1944,"genre":"cuento","isbn":"9788418037016"}`, http.StatusConflict},
+		{"conflict", domain.ErrConflict, http.MethodPut, "/api/v1/books/7", `{"title":"Ficciones"}`, http.StatusConflict},
+		{"version mismatch", fmt.Errorf("book 7 %w", domain.ErrVersionMismatch), http.MethodPut, "/api/v1/books/7",
+			`{"title":"Ficciones"}`, http.StatusPreconditionFailed},
+		{"validation", &domain.ValidationError{Field: "title", Message: "title is required"}, http.MethodPut, "/api/v1/books/7",
+			`{"title":"Ficciones"}`, http.StatusUnprocessableEntity},
+		{"unexpected", errors.New("connection refused"), http.MethodGet, "/api/v1/books/7", "", http.StatusInternalServerError},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Arrange
+			app := newTestApp(&stubBookService{err: tc.err})
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", `"3"`)
+
+			// Act
+			resp, err := app.Test(req)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tc.status, resp.StatusCode)
+		})
+	}
+}
+
+// readProblem decodifica un cuerpo application/problem+json
+func readProblem(t *testing.T, resp *http.Response) presentation.ProblemResponse {
+	t.Helper()
+	assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+	var problem presentation.ProblemResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&problem))
+	return problem
+}
+
+func TestHandlers_UnexpectedErrorIsNotExposed(t *testing.T) {
+	// Arrange
+	app := newTestApp(&stubBookService{err: errors.New("dial tcp 10.0.0.1:5432: connection refused")})
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/books/7", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	assert.Contains(t, string(body), "Internal server error")
+	assert.NotContains(t, string(body), "10.0.0.1")
+}
+
+func TestCreateBook_ValidatorErrorsUseJSONFieldNames(t *testing.T) {
+	// Arrange
+	app := newTestApp(&stubBookService{})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/books", strings.NewReader(`{"title":"Ficciones","author":"Borges","year":1200}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	problem := readProblem(t, resp)
+	assert.Equal(t, "/problems/validation", problem.Type)
+	assert.Equal(t, http.StatusUnprocessableEntity, problem.Status)
+	assert.Equal(t, "/api/v1/books", problem.Instance)
+	assert.ElementsMatch(t, []presentation.FieldErrorResponse{
+		{Field: "year", Code: domain.CodeOutOfRange, Message: "year must be at least 1450"},
+		{Field: "isbn", Code: domain.CodeRequired, Message: "isbn is required"},
+	}, problem.Errors)
+	assert.NotContains(t, problem.Detail, "CreateBookRequest")
+}
+
+func TestCreateBook_ReturnsValidatorAndDomainErrorsTogether(t *testing.T) {
+	// Arrange: falta el título (validator) y el ISBN tiene mal el dígito de control (dominio)
+	app := newTestApp(&stubBookService{})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/books",
+		strings.NewReader(`{"author":"Borges","year":1944,"isbn":"9788418037010"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	problem := readProblem(t, resp)
+	assert.Equal(t, []presentation.FieldErrorResponse{
+		{Field: "title", Code: domain.CodeRequired, Message: "title is required"},
+		{Field: "isbn", Code: domain.CodeInvalidChecksum, Message: "invalid ISBN-13 checksum"},
+	}, problem.Errors)
+}
+
+func TestUpdateBook_DomainValidationErrorHasField(t *testing.T) {
+	// Arrange
+	app := newTestApp(&stubBookService{err: &domain.ValidationError{
+		Field: "isbn", Code: domain.CodeInvalidChecksum, Message: "invalid ISBN-13 checksum",
+	}})
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/books/7", strings.NewReader(`{"isbn":"9788418037010"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"3"`)
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	problem := readProblem(t, resp)
+	assert.Equal(t, "invalid ISBN-13 checksum", problem.Detail)
+	assert.Equal(t, []presentation.FieldErrorResponse{
+		{Field: "isbn", Code: domain.CodeInvalidChecksum, Message: "invalid ISBN-13 checksum"},
+	}, problem.Errors)
+}
+
+func TestGetBookByID_InvalidIDIsBadRequest(t *testing.T) {
+	// Arrange
+	app := newTestApp(&stubBookService{})
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/books/abc", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	problem := readProblem(t, resp)
+	assert.Equal(t, "/problems/bad-request", problem.Type)
+	assert.Equal(t, []presentation.FieldErrorResponse{
+		{Field: "id", Code: domain.CodeInvalidFormat, Message: `invalid id: "abc"`},
+	}, problem.Errors)
+}
+
+func TestSearchBooks_QuerySyntaxErrorPointsToQ(t *testing.T) {
+	// Arrange
+	app := newTestApp(&stubBookService{searchErr: &domain.QuerySyntaxError{Pos: 7, Msg: "missing closing parenthesis"}})
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/books/search?q=(borges", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	problem := readProblem(t, resp)
+	assert.Len(t, problem.Errors, 1)
+	assert.Equal(t, "q", problem.Errors[0].Field)
+	assert.Equal(t, domain.CodeSyntax, problem.Errors[0].Code)
+}
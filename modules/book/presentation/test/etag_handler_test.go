@@ -0,0 +1,93 @@
+package presentation_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newUpdateRequest arma un PUT /books/7 con If-Match, si ifMatch no está vacío
+func newUpdateRequest(ifMatch string) *http.Request {
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/books/7", strings.NewReader(`{"title":"Ficciones"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	return req
+}
+
+func TestGetBookByID_ReturnsVersionAsETag(t *testing.T) {
+	// Arrange
+	app := newTestApp(&stubBookService{})
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/books/7", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `"3"`, resp.Header.Get("ETag"))
+}
+
+func TestUpdateBook_SendsIfMatchVersionAndReturnsNewETag(t *testing.T) {
+	// Arrange
+	service := &stubBookService{}
+	app := newTestApp(service)
+
+	// Act
+	resp, err := app.Test(newUpdateRequest(`"3"`))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, uint(3), service.version)
+	assert.Equal(t, `"4"`, resp.Header.Get("ETag"))
+}
+
+func TestUpdateBook_IfMatchAnyAcceptsAnyVersion(t *testing.T) {
+	// Arrange
+	service := &stubBookService{version: 99}
+	app := newTestApp(service)
+
+	// Act
+	resp, err := app.Test(newUpdateRequest("*"))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, domain.AnyVersion, service.version)
+}
+
+func TestUpdateBook_WithoutIfMatchIsPreconditionRequired(t *testing.T) {
+	// Arrange
+	service := &stubBookService{version: 99}
+	app := newTestApp(service)
+
+	// Act
+	resp, err := app.Test(newUpdateRequest(""))
+
+	// Assert: el caso de uso no se llama
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPreconditionRequired, resp.StatusCode)
+	assert.Equal(t, uint(99), service.version)
+}
+
+func TestUpdateBook_MalformedIfMatchIsBadRequest(t *testing.T) {
+	for _, ifMatch := range []string{"3", `W/"3"`, `"tres"`, `"0"`} {
+		t.Run(ifMatch, func(t *testing.T) {
+			// Arrange
+			app := newTestApp(&stubBookService{})
+
+			// Act
+			resp, err := app.Test(newUpdateRequest(ifMatch))
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package presentation_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBookHistory_ListsRevisions(t *testing.T) {
+	// Arrange
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	app := newTestApp(&stubBookService{revisions: []*domain.Revision{
+		{Number: 1, Action: domain.RevisionCreate, Actor: "ana", CreatedAt: at,
+			Changes: []domain.FieldChange{{Field: "title", To: "Ficciones"}}},
+		{Number: 2, Action: domain.RevisionRevert, Actor: "luis", RevertedTo: 1, CreatedAt: at,
+			Changes: []domain.FieldChange{{Field: "year", From: "1956", To: "1944"}}},
+	}})
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/books/7/history", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var body struct {
+		Data []map[string]any `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	if assert.Len(t, body.Data, 2) {
+		assert.Equal(t, float64(1), body.Data[0]["revision"])
+		assert.Equal(t, "ana", body.Data[0]["actor"])
+		assert.NotContains(t, body.Data[0], "reverted_to")
+		assert.Equal(t, float64(1), body.Data[1]["reverted_to"])
+		assert.Equal(t, []any{map[string]any{"field": "year", "from": "1956", "to": "1944"}}, body.Data[1]["changes"])
+	}
+}
+
+func TestGetBookHistory_UnknownBookIsNotFound(t *testing.T) {
+	// Arrange
+	app := newTestApp(&stubBookService{err: domain.ErrNotFound})
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/books/9/history", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestRevertBook_PassesActorAndOptionalIfMatch(t *testing.T) {
+	// Arrange
+	service := &stubBookService{version: 99}
+	app := newTestApp(service)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/books/7/revert/1", nil)
+	req.Header.Set("X-Actor", "ana")
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert: sin If-Match no se comprueba la versión
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `"5"`, resp.Header.Get("ETag"))
+	assert.Equal(t, domain.AnyVersion, service.version)
+	assert.Equal(t, "ana", service.actor)
+
+	// Y con If-Match se pasa la versión
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/books/7/revert/1", nil)
+	req.Header.Set("If-Match", `"4"`)
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, uint(4), service.version)
+	assert.Equal(t, application.AnonymousActor, service.actor)
+}
+
+func TestRevertBook_InvalidRevisionIsBadRequest(t *testing.T) {
+	// Arrange
+	app := newTestApp(&stubBookService{})
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/api/v1/books/7/revert/ultima", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,262 @@
+package presentation_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"api-go-gestion-libros-hexagonal/modules/book/presentation"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubBookService captura el filtro y la versión que llegan al caso de uso; err y
+// searchErr, si no son nil, son los errores que devuelven los casos de uso de un
+// libro y la búsqueda
+type stubBookService struct {
+	filter    domain.BookFilter
+	version   uint
+	actor     string
+	revisions []*domain.Revision
+	trash     []*domain.Book
+	page      *domain.BookPage
+	facets    *domain.BookFacets
+	err       error
+	searchErr error
+}
+
+func (s *stubBookService) CreateBook(ctx context.Context, title, author string, year uint, genre, isbn string) (*domain.Book, error) {
+	return nil, s.err
+}
+
+func (s *stubBookService) UpdateBook(ctx context.Context, id, version uint, input domain.UpdateBookInput) (*domain.Book, error) {
+	s.version = version
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.Book{ID: id, Version: version + 1}, nil
+}
+
+func (s *stubBookService) DeleteBook(ctx context.Context, id uint) error {
+	return s.err
+}
+
+func (s *stubBookService) GetBookByID(ctx context.Context, id uint) (*domain.Book, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.Book{ID: id, Version: 3}, nil
+}
+
+func (s *stubBookService) GetBookByISBN(ctx context.Context, isbn string) (*domain.Book, error) {
+	return nil, s.err
+}
+
+func (s *stubBookService) SearchBooks(ctx context.Context, filter domain.BookFilter) (*domain.BookPage, error) {
+	s.filter = filter
+	if s.searchErr != nil {
+		return nil, s.searchErr
+	}
+	if s.page != nil {
+		return s.page, nil
+	}
+	return &domain.BookPage{}, nil
+}
+
+func (s *stubBookService) GetFacets(ctx context.Context, filter domain.BookFilter) (*domain.BookFacets, error) {
+	if s.facets != nil {
+		return s.facets, nil
+	}
+	return domain.NewBookFacets(), nil
+}
+
+func (s *stubBookService) Autocomplete(ctx context.Context, field, prefix string, limit int) ([]domain.ValueCount, error) {
+	return []domain.ValueCount{}, nil
+}
+
+func (s *stubBookService) GetBookHistory(ctx context.Context, id uint) ([]*domain.Revision, error) {
+	return s.revisions, s.err
+}
+
+func (s *stubBookService) RevertBook(ctx context.Context, id, revision, version uint) (*domain.Book, error) {
+	s.version, s.actor = version, application.ActorFrom(ctx)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.Book{ID: id, Title: "Ficciones", Version: 5}, nil
+}
+
+func (s *stubBookService) ListTrash(ctx context.Context) ([]*domain.Book, error) {
+	return s.trash, s.err
+}
+
+func (s *stubBookService) RestoreBook(ctx context.Context, id uint) (*domain.Book, error) {
+	s.actor = application.ActorFrom(ctx)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &domain.Book{ID: id, Title: "Ficciones", Version: 3}, nil
+}
+
+func newTestApp(service *stubBookService) *fiber.App {
+	app := fiber.New()
+	presentation.SetupBookRoutes(app, presentation.NewBookHandler(service))
+	return app
+}
+
+func TestSearchBooks_QueryAndBodyProduceSameFilter(t *testing.T) {
+	// Arrange
+	getService := &stubBookService{}
+	postService := &stubBookService{}
+
+	get := httptest.NewRequest(http.MethodGet,
+		"/api/v1/books/search?author=Borges&genres=cuento&genres=ensayo&year=1940..1960&sort=-year,title&limit=5", nil)
+	post := httptest.NewRequest(http.MethodPost, "/api/v1/books/search", strings.NewReader(`{
+		"author": "Borges",
+		"genres": ["cuento", "ensayo"],
+		"year_from": 1940,
+		"year_to": 1960,
+		"sort": "-year,title",
+		"limit": 5
+	}`))
+	post.Header.Set("Content-Type", "application/json")
+
+	// Act
+	getResp, err := newTestApp(getService).Test(get)
+	assert.NoError(t, err)
+	postResp, err := newTestApp(postService).Test(post)
+	assert.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, fiber.StatusOK, getResp.StatusCode)
+	assert.Equal(t, fiber.StatusOK, postResp.StatusCode)
+	assert.Equal(t, postService.filter, getService.filter)
+	assert.Equal(t, "Borges", *getService.filter.Author)
+	assert.Equal(t, []string{"cuento", "ensayo"}, getService.filter.Genres)
+	assert.Equal(t, uint(1940), *getService.filter.YearFrom)
+	assert.Equal(t, uint(1960), *getService.filter.YearTo)
+}
+
+func TestSearchBooks_QueryCommaSeparatedAndOpenRange(t *testing.T) {
+	// Arrange
+	service := &stubBookService{}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/books/search?genres=cuento,ensayo&year=1950..", nil)
+
+	// Act
+	resp, err := newTestApp(service).Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"cuento", "ensayo"}, service.filter.Genres)
+	assert.Equal(t, uint(1950), *service.filter.YearFrom)
+	assert.Nil(t, service.filter.YearTo)
+}
+
+func TestSearchBooks_InvalidQueryValue(t *testing.T) {
+	// Arrange
+	service := &stubBookService{}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/books/search?year=mil", nil)
+
+	// Act
+	resp, err := newTestApp(service).Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestSearchBooks_YearRangeWithSeparateEndsIsRejected(t *testing.T) {
+	for _, query := range []string{"year=1940..&year_to=1960", "year=1940..1960&year_from=1950"} {
+		t.Run(query, func(t *testing.T) {
+			// Arrange
+			service := &stubBookService{}
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/books/search?"+query, nil)
+
+			// Act
+			resp, err := newTestApp(service).Test(req)
+
+			// Assert: no se pisa year_from/year_to en silencio
+			assert.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+			assert.Nil(t, service.filter.YearTo)
+		})
+	}
+}
+
+func TestSearchBooks_ISBNPrefixAndTimeWindows(t *testing.T) {
+	// Arrange
+	service := &stubBookService{}
+	req := httptest.NewRequest(http.MethodGet,
+		"/api/v1/books/search?isbn_prefix=978-84&created_after=2024-01-31&updated_since=2024-02-01T10:30:00Z", nil)
+
+	// Act
+	resp, err := newTestApp(service).Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "978-84", *service.filter.ISBNPrefix)
+	assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), *service.filter.CreatedAfter)
+	assert.Equal(t, time.Date(2024, 2, 1, 10, 30, 0, 0, time.UTC), *service.filter.UpdatedSince)
+}
+
+func TestSearchBooks_InvalidTimestamp(t *testing.T) {
+	// Arrange
+	service := &stubBookService{}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/books/search?updated_since=ayer", nil)
+
+	// Act
+	resp, err := newTestApp(service).Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
+
+func TestSearchBooks_FullTextReturnsScoreAndHighlights(t *testing.T) {
+	// Arrange
+	service := &stubBookService{page: &domain.BookPage{
+		Books: []*domain.Book{{ID: 1, Title: "Cien años de soledad", Author: "Gabriel García Márquez"}},
+		Total: 1,
+		Matches: map[uint]domain.BookMatch{
+			1: {Score: -0.8, Highlights: map[string]string{"author": "Gabriel <mark>García</mark> Márquez"}},
+		},
+	}}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/books/search?q=garc", nil)
+
+	// Act
+	resp, err := newTestApp(service).Test(req)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+
+	// Assert
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "garc", service.filter.Query)
+	assert.Contains(t, string(body), `"score":-0.8`)
+	assert.Contains(t, string(body), `"highlights":{"author":"Gabriel \u003cmark\u003eGarcía\u003c/mark\u003e Márquez"}`)
+}
+
+func TestSearchBooks_WithFacets(t *testing.T) {
+	// Arrange
+	facets := domain.NewBookFacets()
+	facets.Genre["Ficción"] = 120
+	facets.Decade["1960"] = 3
+	service := &stubBookService{facets: facets}
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/books/search?author=garcia&facets=true", nil)
+
+	// Act
+	resp, err := newTestApp(service).Test(req)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+
+	// Assert
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `"facets":{"author":{},"decade":{"1960":3},"genre":{"Ficción":120}}`)
+}
@@ -0,0 +1,93 @@
+package presentation_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListTrash_IncludesDeletedAt(t *testing.T) {
+	// Arrange
+	deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	app := newTestApp(&stubBookService{trash: []*domain.Book{
+		{ID: 7, Title: "Ficciones", Version: 2, DeletedAt: &deletedAt},
+	}})
+
+	// Act: /trash no debe tomarse como un :id
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/books/trash", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var body struct {
+		Data []map[string]any `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	if assert.Len(t, body.Data, 1) {
+		assert.Equal(t, float64(7), body.Data[0]["id"])
+		assert.Equal(t, "2024-05-01T12:00:00Z", body.Data[0]["deleted_at"])
+	}
+}
+
+func TestGetBookByID_OmitsDeletedAt(t *testing.T) {
+	// Arrange
+	app := newTestApp(&stubBookService{})
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/books/7", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.NotContains(t, body.Data, "deleted_at")
+}
+
+func TestRestoreBook_ReturnsBookWithETag(t *testing.T) {
+	// Arrange
+	service := &stubBookService{}
+	app := newTestApp(service)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/books/7/restore", nil)
+	req.Header.Set("X-Actor", "ana")
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `"3"`, resp.Header.Get("ETag"))
+	assert.Equal(t, "ana", service.actor)
+}
+
+func TestRestoreBook_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"not in trash", fmt.Errorf("book 7 %w in trash", domain.ErrNotFound), http.StatusNotFound},
+		{"isbn taken", fmt.Errorf("%w: book 7 cannot be restored (%w)", domain.ErrConflict, domain.ErrDuplicateISBN), http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			app := newTestApp(&stubBookService{err: tt.err})
+
+			// Act
+			resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/api/v1/books/7/restore", nil))
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
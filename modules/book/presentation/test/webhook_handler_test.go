@@ -0,0 +1,175 @@
+package presentation_test
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"api-go-gestion-libros-hexagonal/modules/book/presentation"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubWebhookService guarda lo que recibe y devuelve webhooks y entregas fijos
+type stubWebhookService struct {
+	input      domain.WebhookInput
+	redelivery [2]uint
+}
+
+func (s *stubWebhookService) CreateWebhook(ctx context.Context, in domain.WebhookInput) (*domain.Webhook, error) {
+	s.input = in
+	if in.URL == nil {
+		return nil, domain.ValidationErrors{{Field: "url", Code: domain.CodeRequired, Message: "url is required"}}
+	}
+	return &domain.Webhook{ID: 3, URL: *in.URL, Events: []string{domain.AllEvents}, Secret: "generated-secret-0123", Active: true}, nil
+}
+
+func (s *stubWebhookService) ListWebhooks(ctx context.Context) ([]*domain.Webhook, error) {
+	return []*domain.Webhook{{ID: 3, URL: "http://erp.local", Secret: "generated-secret-0123"}}, nil
+}
+
+func (s *stubWebhookService) GetWebhook(ctx context.Context, id uint) (*domain.Webhook, error) {
+	return nil, fmt.Errorf("webhook %d %w", id, domain.ErrNotFound)
+}
+
+func (s *stubWebhookService) UpdateWebhook(ctx context.Context, id uint, in domain.WebhookInput) (*domain.Webhook, error) {
+	s.input = in
+	return &domain.Webhook{ID: id, Active: true}, nil
+}
+
+func (s *stubWebhookService) DeleteWebhook(ctx context.Context, id uint) error { return nil }
+
+func (s *stubWebhookService) ListDeliveries(ctx context.Context, id uint, limit int) ([]*domain.WebhookDelivery, error) {
+	return nil, nil
+}
+
+func (s *stubWebhookService) RedeliverWebhook(ctx context.Context, id, deliveryID uint) (*domain.WebhookDelivery, error) {
+	s.redelivery = [2]uint{id, deliveryID}
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return &domain.WebhookDelivery{ID: 40, WebhookID: id, EventID: "book.created:7:1", Status: domain.DeliveryDelivered,
+		Attempts: 1, ResponseStatus: 200, DeliveredAt: &at, RedeliveryOf: deliveryID}, nil
+}
+
+func newWebhookApp(service *stubWebhookService) *fiber.App {
+	app := fiber.New()
+	presentation.SetupWebhookRoutes(app, presentation.NewWebhookHandler(service))
+	return app
+}
+
+func TestCreateWebhook_ReturnsSecretOnce(t *testing.T) {
+	// Arrange
+	service := &stubWebhookService{}
+	app := newWebhookApp(service)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks",
+		strings.NewReader(`{"url":"http://erp.local/hooks","events":["book.created"]}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	created, err := app.Test(req)
+	assert.NoError(t, err)
+	listed, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/webhooks", nil))
+
+	// Assert: el secreto solo aparece al crear
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, created.StatusCode)
+	assert.Equal(t, []string{domain.EventBookCreated}, service.input.Events)
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(created.Body).Decode(&body))
+	assert.Equal(t, "generated-secret-0123", body.Data["secret"])
+
+	var list struct {
+		Data []map[string]any `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(listed.Body).Decode(&list))
+	if assert.Len(t, list.Data, 1) {
+		assert.NotContains(t, list.Data[0], "secret")
+	}
+}
+
+func TestCreateWebhook_ValidationIs422(t *testing.T) {
+	// Arrange
+	app := newWebhookApp(&stubWebhookService{})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+}
+
+func TestGetWebhook_NotFound(t *testing.T) {
+	// Arrange
+	app := newWebhookApp(&stubWebhookService{})
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/9", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestUpdateWebhook_ReactivatesWithActive(t *testing.T) {
+	// Arrange
+	service := &stubWebhookService{}
+	app := newWebhookApp(service)
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/webhooks/3", strings.NewReader(`{"active":true}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	resp, err := app.Test(req)
+
+	// Assert: los campos que no vienen no cambian
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	if assert.NotNil(t, service.input.Active) {
+		assert.True(t, *service.input.Active)
+	}
+	assert.Nil(t, service.input.URL)
+	assert.Nil(t, service.input.Events)
+}
+
+func TestRedeliver_ReturnsAttemptResult(t *testing.T) {
+	// Arrange
+	service := &stubWebhookService{}
+	app := newWebhookApp(service)
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/3/deliveries/12/redeliver", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, [2]uint{3, 12}, service.redelivery)
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, domain.DeliveryDelivered, body.Data["status"])
+	assert.Equal(t, float64(12), body.Data["redelivery_of"])
+	assert.Equal(t, "2024-05-01T12:00:00Z", body.Data["delivered_at"])
+	assert.NotContains(t, body.Data, "next_attempt_at")
+}
+
+func TestRedeliver_InvalidDeliveryID(t *testing.T) {
+	// Arrange
+	app := newWebhookApp(&stubWebhookService{})
+
+	// Act
+	resp, err := app.Test(httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/3/deliveries/last/redeliver", nil))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,194 @@
+package presentation
+
+import (
+	"api-go-gestion-libros-hexagonal/modules/book/application"
+	"api-go-gestion-libros-hexagonal/modules/book/domain"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WebhookHandler expone la administración de webhooks. Las reglas (URL, eventos,
+// secreto) las valida el dominio.
+type WebhookHandler struct {
+	webhookService application.WebhookServiceInterface
+}
+
+func NewWebhookHandler(webhookService application.WebhookServiceInterface) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+func webhookToResponse(webhook *domain.Webhook) *WebhookResponse {
+	return &WebhookResponse{
+		ID:         webhook.ID,
+		URL:        webhook.URL,
+		Events:     webhook.Events,
+		Active:     webhook.Active,
+		Failures:   webhook.Failures,
+		DisabledAt: webhook.DisabledAt,
+		RetryAt:    webhook.RetryAt,
+		CreatedAt:  webhook.CreatedAt,
+		UpdatedAt:  webhook.UpdatedAt,
+	}
+}
+
+func deliveryToResponse(delivery *domain.WebhookDelivery) WebhookDeliveryResponse {
+	resp := WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		EventID:        delivery.EventID,
+		Event:          delivery.EventName,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+		RedeliveryOf:   delivery.RedeliveryOf,
+	}
+	if delivery.Status == domain.DeliveryPending {
+		next := delivery.NextAttemptAt
+		resp.NextAttemptAt = &next
+	}
+	return resp
+}
+
+// CreateWebhook da de alta un webhook: POST /webhooks. Es la única respuesta con el secreto.
+func (h *WebhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	var req WebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return respondBadRequest(c, err)
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(c.UserContext(), req.toDomain())
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	resp := webhookToResponse(webhook)
+	resp.Secret = webhook.Secret
+	return c.Status(fiber.StatusCreated).JSON(Response{
+		Success: true,
+		Data:    resp,
+		Message: "Webhook created successfully",
+	})
+}
+
+func (h *WebhookHandler) ListWebhooks(c *fiber.Ctx) error {
+	webhooks, err := h.webhookService.ListWebhooks(c.UserContext())
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	responses := make([]*WebhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		responses[i] = webhookToResponse(webhook)
+	}
+	return c.JSON(Response{
+		Success: true,
+		Data:    responses,
+	})
+}
+
+func (h *WebhookHandler) GetWebhook(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return respondBadRequest(c, err)
+	}
+
+	webhook, err := h.webhookService.GetWebhook(c.UserContext(), id)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(Response{
+		Success: true,
+		Data:    webhookToResponse(webhook),
+	})
+}
+
+// UpdateWebhook cambia los campos enviados: PUT /webhooks/:id. {"active": true} reactiva
+// un webhook desactivado por fallos.
+func (h *WebhookHandler) UpdateWebhook(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return respondBadRequest(c, err)
+	}
+	var req WebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return respondBadRequest(c, err)
+	}
+
+	webhook, err := h.webhookService.UpdateWebhook(c.UserContext(), id, req.toDomain())
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(Response{
+		Success: true,
+		Data:    webhookToResponse(webhook),
+		Message: "Webhook updated successfully",
+	})
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return respondBadRequest(c, err)
+	}
+
+	if err := h.webhookService.DeleteWebhook(c.UserContext(), id); err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(Response{
+		Success: true,
+		Message: "Webhook deleted successfully",
+	})
+}
+
+// ListDeliveries devuelve el registro de entregas: GET /webhooks/:id/deliveries?limit=50
+func (h *WebhookHandler) ListDeliveries(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return respondBadRequest(c, err)
+	}
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		return respondBadRequest(c, err)
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(c.UserContext(), id, limit)
+	if err != nil {
+		return respondError(c, err)
+	}
+
+	responses := make([]WebhookDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		responses[i] = deliveryToResponse(delivery)
+	}
+	return c.JSON(Response{
+		Success: true,
+		Data:    responses,
+	})
+}
+
+// Redeliver vuelve a enviar una entrega y devuelve el resultado del intento:
+// POST /webhooks/:id/deliveries/:delivery/redeliver
+func (h *WebhookHandler) Redeliver(c *fiber.Ctx) error {
+	id, err := parseID(c)
+	if err != nil {
+		return respondBadRequest(c, err)
+	}
+	rawDelivery := c.Params("delivery")
+	deliveryID, err := strconv.ParseUint(rawDelivery, 10, 32)
+	if err != nil {
+		return respondBadRequest(c, invalidParam("delivery", rawDelivery))
+	}
+
+	delivery, err := h.webhookService.RedeliverWebhook(c.UserContext(), id, uint(deliveryID))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(Response{
+		Success: true,
+		Data:    deliveryToResponse(delivery),
+	})
+}
@@ -0,0 +1,380 @@
+package database
+
+import (
+	"api-go-gestion-libros-hexagonal/shared/config"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Las migraciones de cada driver están en su carpeta (migrations/sqlite, migrations/postgres).
+// Turso usa las de SQLite.
+//
+//go:embed migrations/*/*.sql
+var embeddedMigrations embed.FS
+
+// Migration es un cambio de esquema numerado con su SQL de ida (up) y de vuelta (down).
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // sha256 del SQL up: detecta migraciones editadas después de aplicarse
+}
+
+// MigrationStatus indica si una migración está aplicada y cuándo.
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+	// Modified indica que el archivo cambió después de aplicarse (checksum distinto)
+	Modified bool
+}
+
+const (
+	// lockTimeout es cuánto espera una instancia a que otra termine de migrar
+	lockTimeout = 30 * time.Second
+	// staleLockAfter libera el lock de una instancia que murió a mitad de la migración
+	staleLockAfter = 10 * time.Minute
+)
+
+// migrationFileRe reconoce archivos como 0002_add_normalized_columns.up.sql
+var migrationFileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// LoadMigrations lee las migraciones de un directorio y las devuelve ordenadas por versión.
+// Cada versión debe tener su archivo up y su archivo down.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		m := migrationFileRe.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", e.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+		content, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names: %q and %q", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(content)
+			sum := sha256.Sum256(content)
+			mig.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			mig.Down = string(content)
+		}
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %d (%s) needs both up and down files", mig.Version, mig.Name)
+		}
+		out = append(out, *mig)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// Migrator aplica y revierte migraciones registrando las aplicadas en schema_migrations.
+type Migrator struct {
+	db         *sql.DB
+	postgres   bool
+	migrations []Migration
+	owner      string
+}
+
+// NewMigrator crea un Migrator con las migraciones embebidas en el binario para el driver dado
+func NewMigrator(db *sql.DB, driver string) (*Migrator, error) {
+	dir := "migrations/sqlite"
+	if driver == config.DriverPostgres {
+		dir = "migrations/postgres"
+	}
+	sub, err := fs.Sub(embeddedMigrations, dir)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := LoadMigrations(sub)
+	if err != nil {
+		return nil, err
+	}
+	return NewMigratorWith(db, driver, migrations), nil
+}
+
+// NewMigratorWith crea un Migrator con un conjunto de migraciones dado (útil en tests)
+func NewMigratorWith(db *sql.DB, driver string, migrations []Migration) *Migrator {
+	host, _ := os.Hostname()
+	return &Migrator{
+		db:         db,
+		postgres:   driver == config.DriverPostgres,
+		migrations: migrations,
+		owner:      fmt.Sprintf("%s:%d:%d", host, os.Getpid(), time.Now().UnixNano()),
+	}
+}
+
+// q adapta los placeholders de una consulta al driver
+func (m *Migrator) q(query string) string {
+	if m.postgres {
+		return Rebind(query)
+	}
+	return query
+}
+
+// Up aplica las migraciones pendientes en orden, cada una en su transacción.
+// Devuelve las que aplicó.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied := []Migration{}
+	err := m.withLock(ctx, func() error {
+		done, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(done); err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if _, ok := done[mig.Version]; ok {
+				continue
+			}
+			err := m.run(ctx, mig.Up, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx,
+					m.q(`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`),
+					mig.Version, mig.Name, mig.Checksum, time.Now().UTC())
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d (%s) up: %w", mig.Version, mig.Name, err)
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down revierte las últimas steps migraciones aplicadas, de la más nueva a la más vieja.
+// Devuelve las que revirtió.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("steps must be positive")
+	}
+	reverted := []Migration{}
+	err := m.withLock(ctx, func() error {
+		done, err := m.applied(ctx)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(done); err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := done[mig.Version]; !ok {
+				continue
+			}
+			err := m.run(ctx, mig.Down, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, m.q(`DELETE FROM schema_migrations WHERE version = ?`), mig.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d (%s) down: %w", mig.Version, mig.Name, err)
+			}
+			reverted = append(reverted, mig)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status devuelve el estado de cada migración conocida
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	if err := m.ensureTables(ctx); err != nil {
+		return nil, err
+	}
+	done, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]MigrationStatus, len(m.migrations))
+	for i, mig := range m.migrations {
+		out[i] = MigrationStatus{Migration: mig}
+		if rec, ok := done[mig.Version]; ok {
+			out[i].Applied = true
+			out[i].AppliedAt = rec.appliedAt
+			out[i].Modified = rec.checksum != mig.Checksum
+		}
+	}
+	return out, nil
+}
+
+type appliedMigration struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+func (m *Migrator) ensureTables(ctx context.Context) error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)`,
+		// Una sola fila (id = 1) mientras alguna instancia está migrando
+		`CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			owner TEXT NOT NULL,
+			locked_at BIGINT NOT NULL
+		)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := m.db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]appliedMigration, error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT version, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := map[int]appliedMigration{}
+	for rows.Next() {
+		var version int
+		var rec appliedMigration
+		if err := rows.Scan(&version, &rec.checksum, &rec.appliedAt); err != nil {
+			return nil, err
+		}
+		out[version] = rec
+	}
+	return out, rows.Err()
+}
+
+// verify falla si una migración aplicada fue modificada o si la base tiene
+// versiones que este binario no conoce (fue migrada por una versión más nueva)
+func (m *Migrator) verify(done map[int]appliedMigration) error {
+	known := map[int]bool{}
+	for _, mig := range m.migrations {
+		known[mig.Version] = true
+		if rec, ok := done[mig.Version]; ok && rec.checksum != mig.Checksum {
+			return fmt.Errorf("migration %d (%s) was modified after being applied (checksum mismatch)", mig.Version, mig.Name)
+		}
+	}
+	for version := range done {
+		if !known[version] {
+			return fmt.Errorf("database has migration %d, unknown to this build", version)
+		}
+	}
+	return nil
+}
+
+// run ejecuta el SQL de una migración y el registro en schema_migrations en una
+// misma transacción: o se aplica todo o nada
+func (m *Migrator) run(ctx context.Context, script string, record func(tx *sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stmt := range splitStatements(script) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	if err := record(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// withLock ejecuta fn con el lock de migraciones tomado, para que dos instancias
+// que arrancan a la vez no migren en paralelo
+func (m *Migrator) withLock(ctx context.Context, fn func() error) error {
+	if err := m.ensureTables(ctx); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		// Un lock muy viejo es de una instancia que murió sin liberarlo
+		stale := time.Now().Add(-staleLockAfter).Unix()
+		if _, err := m.db.ExecContext(ctx, m.q(`DELETE FROM schema_migrations_lock WHERE locked_at < ?`), stale); err != nil {
+			return err
+		}
+		res, err := m.db.ExecContext(ctx,
+			m.q(`INSERT INTO schema_migrations_lock (id, owner, locked_at) VALUES (1, ?, ?) ON CONFLICT (id) DO NOTHING`),
+			m.owner, time.Now().Unix())
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for migration lock")
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+	defer m.db.ExecContext(context.Background(), m.q(`DELETE FROM schema_migrations_lock WHERE id = 1 AND owner = ?`), m.owner)
+	return fn()
+}
+
+// splitStatements separa un script en sentencias terminadas en ";" al final de línea.
+// Los cuerpos de trigger (BEGIN ... END;) se mantienen como una sola sentencia.
+func splitStatements(script string) []string {
+	out := []string{}
+	current := []string{}
+	inBlock := false
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if len(current) == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "--")) {
+			continue
+		}
+		current = append(current, line)
+		upper := strings.ToUpper(trimmed)
+		if strings.HasSuffix(upper, " BEGIN") || upper == "BEGIN" {
+			inBlock = true
+			continue
+		}
+		if inBlock && upper != "END;" {
+			continue
+		}
+		if strings.HasSuffix(trimmed, ";") {
+			stmt := strings.TrimSpace(strings.Join(current, "\n"))
+			out = append(out, strings.TrimSuffix(stmt, ";"))
+			current = current[:0]
+			inBlock = false
+		}
+	}
+	if stmt := strings.TrimSpace(strings.Join(current, "\n")); stmt != "" {
+		out = append(out, stmt)
+	}
+	return out
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS books;
+DROP TEXT SEARCH CONFIGURATION IF EXISTS books_search;
+DROP FUNCTION IF EXISTS f_unaccent(text);
@@ -0,0 +1,31 @@
+-- Búsqueda sin tildes: unaccent y un envoltorio IMMUTABLE para poder indexarlo
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE OR REPLACE FUNCTION f_unaccent(text) RETURNS text LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT AS $$ SELECT public.unaccent('public.unaccent'::regdictionary, $1) $$;
+
+-- Configuración de texto completo: palabras sin tildes ni mayúsculas, sin stemming
+-- (equivale al tokenizer unicode61 remove_diacritics de SQLite)
+CREATE TEXT SEARCH CONFIGURATION books_search (COPY = simple);
+ALTER TEXT SEARCH CONFIGURATION books_search ALTER MAPPING FOR hword, hword_part, word WITH unaccent, simple;
+
+CREATE TABLE books (
+	id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	title TEXT NOT NULL,
+	author TEXT NOT NULL,
+	year INTEGER NOT NULL,
+	genre TEXT NOT NULL,
+	isbn TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	-- Índice de texto completo, con el campo como peso (A título, B autor, C género)
+	search TSVECTOR GENERATED ALWAYS AS (
+		setweight(to_tsvector('books_search', title), 'A') ||
+		setweight(to_tsvector('books_search', author), 'B') ||
+		setweight(to_tsvector('books_search', genre), 'C')
+	) STORED
+);
+
+CREATE INDEX idx_books_search ON books USING GIN (search);
+
+-- Filtros y autocompletado sobre el valor sin tildes
+CREATE INDEX idx_books_title_unaccent ON books (lower(f_unaccent(title)) text_pattern_ops);
+CREATE INDEX idx_books_author_unaccent ON books (lower(f_unaccent(author)) text_pattern_ops);
@@ -0,0 +1,3 @@
+-- Concurrencia optimista: cada actualización sube la versión y solo se aplica
+-- si el libro sigue en la versión que leyó el cliente
+ALTER TABLE books ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
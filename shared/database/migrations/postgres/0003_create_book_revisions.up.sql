@@ -0,0 +1,13 @@
+-- Historial de cambios de cada libro: quién, cuándo y qué campos (JSON).
+-- Sin clave foránea a books: el historial sobrevive al borrado del libro
+CREATE TABLE book_revisions (
+	id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	book_id BIGINT NOT NULL,
+	number INTEGER NOT NULL,
+	action TEXT NOT NULL,
+	actor TEXT NOT NULL,
+	changes TEXT NOT NULL,
+	reverted_to INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMPTZ NOT NULL,
+	UNIQUE (book_id, number)
+);
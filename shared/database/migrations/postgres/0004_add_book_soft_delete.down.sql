@@ -0,0 +1,7 @@
+-- Los libros de la papelera se eliminan: sin deleted_at volverían como activos
+-- y podrían repetir el ISBN de otro libro
+DELETE FROM books WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_books_deleted_at;
+DROP INDEX IF EXISTS idx_books_isbn_active;
+ALTER TABLE books ADD CONSTRAINT books_isbn_key UNIQUE (isbn);
+ALTER TABLE books DROP COLUMN deleted_at;
@@ -0,0 +1,6 @@
+-- Borrado lógico: deleted_at marca los libros de la papelera.
+-- El ISBN solo es único entre los libros activos, así un libro borrado no bloquea su ISBN
+ALTER TABLE books ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE books DROP CONSTRAINT books_isbn_key;
+CREATE UNIQUE INDEX idx_books_isbn_active ON books (isbn) WHERE deleted_at IS NULL;
+CREATE INDEX idx_books_deleted_at ON books (deleted_at) WHERE deleted_at IS NOT NULL;
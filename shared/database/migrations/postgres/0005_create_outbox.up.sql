@@ -0,0 +1,18 @@
+-- Outbox transaccional: los eventos de cada cambio se guardan en la misma transacción
+-- y un relay los entrega después, con reintentos. dedupe_key es el ID del evento.
+CREATE TABLE outbox (
+	id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	dedupe_key TEXT NOT NULL UNIQUE,
+	event_name TEXT NOT NULL,
+	book_id BIGINT NOT NULL,
+	payload TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL,
+	available_at TIMESTAMPTZ NOT NULL,
+	published_at TIMESTAMPTZ,
+	failed_at TIMESTAMPTZ
+);
+
+-- Lo que el relay busca en cada pasada
+CREATE INDEX idx_outbox_due ON outbox (available_at) WHERE published_at IS NULL AND failed_at IS NULL;
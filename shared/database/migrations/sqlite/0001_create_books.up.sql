@@ -0,0 +1,11 @@
+-- Tabla original. IF NOT EXISTS adopta las bases creadas antes de las migraciones (InitSchema)
+CREATE TABLE IF NOT EXISTS books (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	title TEXT NOT NULL,
+	author TEXT NOT NULL,
+	year INTEGER NOT NULL,
+	genre TEXT NOT NULL,
+	isbn TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
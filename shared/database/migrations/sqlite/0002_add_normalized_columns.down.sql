@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_books_author_norm;
+DROP INDEX IF EXISTS idx_books_title_norm;
+ALTER TABLE books DROP COLUMN genre_norm;
+ALTER TABLE books DROP COLUMN author_norm;
+ALTER TABLE books DROP COLUMN title_norm;
@@ -0,0 +1,9 @@
+-- Valores sin tildes ni mayúsculas para búsqueda y autocompletado.
+-- Los libros existentes quedan vacíos y los rellena SqlBookRepository.BackfillNormalized
+ALTER TABLE books ADD COLUMN title_norm TEXT NOT NULL DEFAULT '';
+ALTER TABLE books ADD COLUMN author_norm TEXT NOT NULL DEFAULT '';
+ALTER TABLE books ADD COLUMN genre_norm TEXT NOT NULL DEFAULT '';
+
+-- Autocompletado por prefijo sobre el valor normalizado
+CREATE INDEX idx_books_title_norm ON books(title_norm);
+CREATE INDEX idx_books_author_norm ON books(author_norm);
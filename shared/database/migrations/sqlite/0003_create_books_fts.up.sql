@@ -0,0 +1,23 @@
+-- Índice de texto completo sobre title/author/genre (external content: los datos viven en books)
+CREATE VIRTUAL TABLE books_fts USING fts5(
+	title, author, genre,
+	content = 'books', content_rowid = 'id',
+	tokenize = 'unicode61 remove_diacritics 2'
+);
+
+-- Triggers que mantienen el índice sincronizado
+CREATE TRIGGER books_fts_ai AFTER INSERT ON books BEGIN
+	INSERT INTO books_fts(rowid, title, author, genre) VALUES (new.id, new.title, new.author, new.genre);
+END;
+
+CREATE TRIGGER books_fts_ad AFTER DELETE ON books BEGIN
+	INSERT INTO books_fts(books_fts, rowid, title, author, genre) VALUES ('delete', old.id, old.title, old.author, old.genre);
+END;
+
+CREATE TRIGGER books_fts_au AFTER UPDATE ON books BEGIN
+	INSERT INTO books_fts(books_fts, rowid, title, author, genre) VALUES ('delete', old.id, old.title, old.author, old.genre);
+	INSERT INTO books_fts(rowid, title, author, genre) VALUES (new.id, new.title, new.author, new.genre);
+END;
+
+-- Indexar los libros que ya existían
+INSERT INTO books_fts(books_fts) VALUES ('rebuild');
@@ -0,0 +1,45 @@
+-- Los libros de la papelera se eliminan: sin deleted_at volverían como activos
+-- y podrían repetir el ISBN de otro libro
+DELETE FROM books WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE books_old (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	title TEXT NOT NULL,
+	author TEXT NOT NULL,
+	year INTEGER NOT NULL,
+	genre TEXT NOT NULL,
+	isbn TEXT NOT NULL UNIQUE,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	title_norm TEXT NOT NULL DEFAULT '',
+	author_norm TEXT NOT NULL DEFAULT '',
+	genre_norm TEXT NOT NULL DEFAULT '',
+	version INTEGER NOT NULL DEFAULT 1
+);
+
+INSERT INTO books_old (id, title, author, year, genre, isbn, created_at, updated_at, title_norm, author_norm, genre_norm, version)
+SELECT id, title, author, year, genre, isbn, created_at, updated_at, title_norm, author_norm, genre_norm, version FROM books;
+
+DELETE FROM sqlite_sequence WHERE name = 'books_old';
+INSERT INTO sqlite_sequence (name, seq) SELECT 'books_old', seq FROM sqlite_sequence WHERE name = 'books';
+
+DROP TABLE books;
+ALTER TABLE books_old RENAME TO books;
+
+CREATE INDEX idx_books_title_norm ON books(title_norm);
+CREATE INDEX idx_books_author_norm ON books(author_norm);
+
+CREATE TRIGGER books_fts_ai AFTER INSERT ON books BEGIN
+	INSERT INTO books_fts(rowid, title, author, genre) VALUES (new.id, new.title, new.author, new.genre);
+END;
+
+CREATE TRIGGER books_fts_ad AFTER DELETE ON books BEGIN
+	INSERT INTO books_fts(books_fts, rowid, title, author, genre) VALUES ('delete', old.id, old.title, old.author, old.genre);
+END;
+
+CREATE TRIGGER books_fts_au AFTER UPDATE ON books BEGIN
+	INSERT INTO books_fts(books_fts, rowid, title, author, genre) VALUES ('delete', old.id, old.title, old.author, old.genre);
+	INSERT INTO books_fts(rowid, title, author, genre) VALUES (new.id, new.title, new.author, new.genre);
+END;
+
+INSERT INTO books_fts(books_fts) VALUES ('rebuild');
@@ -0,0 +1,50 @@
+-- Borrado lógico: deleted_at marca los libros de la papelera.
+-- El ISBN solo es único entre los libros activos, así un libro borrado no bloquea su
+-- ISBN. SQLite no puede quitar el UNIQUE de la columna: se reconstruye la tabla.
+CREATE TABLE books_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	title TEXT NOT NULL,
+	author TEXT NOT NULL,
+	year INTEGER NOT NULL,
+	genre TEXT NOT NULL,
+	isbn TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	title_norm TEXT NOT NULL DEFAULT '',
+	author_norm TEXT NOT NULL DEFAULT '',
+	genre_norm TEXT NOT NULL DEFAULT '',
+	version INTEGER NOT NULL DEFAULT 1,
+	deleted_at TIMESTAMP
+);
+
+INSERT INTO books_new (id, title, author, year, genre, isbn, created_at, updated_at, title_norm, author_norm, genre_norm, version)
+SELECT id, title, author, year, genre, isbn, created_at, updated_at, title_norm, author_norm, genre_norm, version FROM books;
+
+-- Conservar el contador de AUTOINCREMENT: los IDs de libros ya borrados no se reutilizan
+DELETE FROM sqlite_sequence WHERE name = 'books_new';
+INSERT INTO sqlite_sequence (name, seq) SELECT 'books_new', seq FROM sqlite_sequence WHERE name = 'books';
+
+DROP TABLE books;
+ALTER TABLE books_new RENAME TO books;
+
+CREATE UNIQUE INDEX idx_books_isbn_active ON books(isbn) WHERE deleted_at IS NULL;
+CREATE INDEX idx_books_deleted_at ON books(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Índices y triggers de 0002 y 0003, que se fueron con la tabla vieja
+CREATE INDEX idx_books_title_norm ON books(title_norm);
+CREATE INDEX idx_books_author_norm ON books(author_norm);
+
+CREATE TRIGGER books_fts_ai AFTER INSERT ON books BEGIN
+	INSERT INTO books_fts(rowid, title, author, genre) VALUES (new.id, new.title, new.author, new.genre);
+END;
+
+CREATE TRIGGER books_fts_ad AFTER DELETE ON books BEGIN
+	INSERT INTO books_fts(books_fts, rowid, title, author, genre) VALUES ('delete', old.id, old.title, old.author, old.genre);
+END;
+
+CREATE TRIGGER books_fts_au AFTER UPDATE ON books BEGIN
+	INSERT INTO books_fts(books_fts, rowid, title, author, genre) VALUES ('delete', old.id, old.title, old.author, old.genre);
+	INSERT INTO books_fts(rowid, title, author, genre) VALUES (new.id, new.title, new.author, new.genre);
+END;
+
+INSERT INTO books_fts(books_fts) VALUES ('rebuild');
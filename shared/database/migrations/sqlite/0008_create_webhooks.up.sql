@@ -0,0 +1,40 @@
+-- Webhooks: suscripciones de sistemas externos a los eventos de libros. events es la
+-- lista de nombres en JSON (["*"] para todos). retry_at es hasta cuándo espera el
+-- webhook tras su último fallo.
+CREATE TABLE webhooks (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	url TEXT NOT NULL,
+	events TEXT NOT NULL,
+	secret TEXT NOT NULL,
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	failures INTEGER NOT NULL DEFAULT 0,
+	disabled_at TIMESTAMP,
+	retry_at TIMESTAMP,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+
+-- Registro de entregas: una por webhook y evento, con el resultado del último intento.
+-- Un reenvío manual es una entrega nueva con redelivery_of apuntando a la original, así
+-- el registro conserva todos los intentos: (webhook_id, event_id) solo es único entre
+-- las entregas originales.
+CREATE TABLE webhook_deliveries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	webhook_id INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+	event_id TEXT NOT NULL,
+	event_name TEXT NOT NULL,
+	payload TEXT NOT NULL,
+	status TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	response_status INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	next_attempt_at TIMESTAMP NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	delivered_at TIMESTAMP,
+	redelivery_of INTEGER
+);
+
+CREATE UNIQUE INDEX idx_webhook_deliveries_event ON webhook_deliveries(webhook_id, event_id) WHERE redelivery_of IS NULL;
+
+-- Lo que el despachador busca en cada pasada
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
@@ -0,0 +1,27 @@
+package database
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Rebind convierte los placeholders "?" de una consulta al estilo de Postgres ($1, $2, ...).
+// Ignora los "?" dentro de literales entre comillas simples.
+func Rebind(q string) string {
+	var b strings.Builder
+	b.Grow(len(q) + 8)
+	n := 0
+	inString := false
+	for _, r := range q {
+		switch {
+		case r == '\'':
+			inString = !inString
+		case r == '?' && !inString:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	_ "modernc.org/sqlite"
+)
+
+// MemoryPath abre una base SQLite en memoria, que se pierde al cerrar el proceso
+const MemoryPath = ":memory:"
+
+// OpenSQLite abre una base SQLite local (archivo o ":memory:"), sin credenciales.
+// Las fechas se guardan con el mismo formato que usa libsql, así el esquema y las
+// consultas del repositorio se comportan igual que contra Turso.
+func OpenSQLite(path string) (*sql.DB, error) {
+	if path == "" {
+		return nil, fmt.Errorf("missing SQLite path")
+	}
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Set("_time_format", "sqlite")
+	// Las transacciones toman el lock de escritura al empezar: evita SQLITE_BUSY
+	// cuando dos transacciones leen y luego intentan escribir a la vez
+	params.Set("_txlock", "immediate")
+
+	dsn := "file:" + path + "?" + params.Encode()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	if path == MemoryPath {
+		// Cada conexión a ":memory:" es una base distinta: se usa una sola
+		// y no se cierra por inactividad
+		db.SetMaxOpenConns(1)
+		db.SetConnMaxIdleTime(0)
+		db.SetConnMaxLifetime(0)
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ping sqlite: %w", err)
+	}
+	return db, nil
+}
@@ -0,0 +1,175 @@
+package database_test
+
+import (
+	"api-go-gestion-libros-hexagonal/shared/config"
+	"api-go-gestion-libros-hexagonal/shared/database"
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openMemory(t *testing.T) *database.Migrator {
+	t.Helper()
+	db, err := database.OpenSQLite(database.MemoryPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	migrator, err := database.NewMigrator(db, config.DriverSQLite)
+	require.NoError(t, err)
+	return migrator
+}
+
+func TestMigrator_UpDownStatus(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	migrator := openMemory(t)
+
+	// Act
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	again, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	reverted, err := migrator.Down(ctx, 1)
+	require.NoError(t, err)
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+
+	// Assert
+	assert.NotEmpty(t, applied)
+	assert.Empty(t, again, "una segunda corrida no aplica nada")
+	assert.Len(t, reverted, 1)
+	assert.Equal(t, applied[len(applied)-1].Version, reverted[0].Version)
+	assert.Len(t, statuses, len(applied))
+	for i, s := range statuses {
+		assert.Equal(t, i < len(statuses)-1, s.Applied, "migración %d", s.Version)
+		assert.False(t, s.Modified)
+	}
+
+	// La migración revertida se vuelve a aplicar
+	reapplied, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, reapplied, 1)
+}
+
+func TestMigrator_DownAllAndUpAgain(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	migrator := openMemory(t)
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+
+	// Act
+	reverted, err := migrator.Down(ctx, len(applied))
+	require.NoError(t, err)
+	reapplied, err := migrator.Up(ctx)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, reverted, len(applied))
+	assert.Len(t, reapplied, len(applied))
+}
+
+func TestMigrator_ModifiedMigrationFails(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	db, err := database.OpenSQLite(database.MemoryPath)
+	require.NoError(t, err)
+	defer db.Close()
+
+	original, err := database.LoadMigrations(fstest.MapFS{
+		"0001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (a TEXT);")},
+		"0001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+	})
+	require.NoError(t, err)
+	edited, err := database.LoadMigrations(fstest.MapFS{
+		"0001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (a TEXT, b TEXT);")},
+		"0001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+	})
+	require.NoError(t, err)
+
+	_, err = database.NewMigratorWith(db, config.DriverSQLite, original).Up(ctx)
+	require.NoError(t, err)
+
+	// Act
+	_, err = database.NewMigratorWith(db, config.DriverSQLite, edited).Up(ctx)
+	statuses, statusErr := database.NewMigratorWith(db, config.DriverSQLite, edited).Status(ctx)
+
+	// Assert
+	assert.EqualError(t, err, "migration 1 (create_table) was modified after being applied (checksum mismatch)")
+	assert.NoError(t, statusErr)
+	assert.True(t, statuses[0].Modified)
+}
+
+func TestMigrator_FailedMigrationIsRolledBack(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	db, err := database.OpenSQLite(database.MemoryPath)
+	require.NoError(t, err)
+	defer db.Close()
+
+	migrations, err := database.LoadMigrations(fstest.MapFS{
+		"0001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (a TEXT);\nINSERT INTO nope VALUES (1);")},
+		"0001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+	})
+	require.NoError(t, err)
+	migrator := database.NewMigratorWith(db, config.DriverSQLite, migrations)
+
+	// Act
+	_, err = migrator.Up(ctx)
+	statuses, _ := migrator.Status(ctx)
+	var n int
+	countErr := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 't'`).Scan(&n)
+
+	// Assert
+	assert.Error(t, err)
+	assert.False(t, statuses[0].Applied)
+	assert.NoError(t, countErr)
+	assert.Equal(t, 0, n, "la tabla creada antes del error no debe quedar")
+}
+
+func TestMigrator_SoftDeleteKeepsBooksAndSequence(t *testing.T) {
+	// Arrange: una base con libros de antes de la papelera (migración 6)
+	const softDelete = 6
+	ctx := context.Background()
+	db, err := database.OpenSQLite(database.MemoryPath)
+	require.NoError(t, err)
+	defer db.Close()
+	migrator, err := database.NewMigrator(db, config.DriverSQLite)
+	require.NoError(t, err)
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	_, err = migrator.Down(ctx, len(applied)-softDelete+1)
+	require.NoError(t, err)
+
+	insert := `INSERT INTO books (title, author, year, genre, isbn, created_at, updated_at) VALUES (?, 'Borges', 1944, 'Cuento', ?, '2024-01-01', '2024-01-01')`
+	_, err = db.ExecContext(ctx, insert, "Ficciones", "9788420633114")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, insert, "El Aleph", "9788420633121")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `DELETE FROM books WHERE id = 2`)
+	require.NoError(t, err)
+
+	// Act
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	// Assert: los libros y el índice de texto completo siguen ahí
+	var title string
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT b.title FROM books b WHERE b.id IN (SELECT rowid FROM books_fts WHERE books_fts MATCH 'ficciones')`).Scan(&title))
+	assert.Equal(t, "Ficciones", title)
+
+	// Los IDs no se reutilizan
+	var id int64
+	require.NoError(t, db.QueryRowContext(ctx, `INSERT INTO books (title, author, year, genre, isbn, created_at, updated_at) VALUES ('Rayuela', 'Cortázar', 1963, 'Novela', '9788437604572', '2024-01-01', '2024-01-01') RETURNING id`).Scan(&id))
+	assert.Equal(t, int64(3), id)
+
+	// El ISBN es único solo entre los libros activos
+	_, err = db.ExecContext(ctx, insert, "Ficciones", "9788420633114")
+	assert.Error(t, err)
+	_, err = db.ExecContext(ctx, `UPDATE books SET deleted_at = '2024-02-01' WHERE id = 1`)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, insert, "Ficciones", "9788420633114")
+	assert.NoError(t, err)
+}
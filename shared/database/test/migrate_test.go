@@ -0,0 +1,99 @@
+package database_test
+
+import (
+	"api-go-gestion-libros-hexagonal/shared/config"
+	"api-go-gestion-libros-hexagonal/shared/database"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations_SortedByVersion(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"0002_add_index.up.sql":      {Data: []byte("CREATE INDEX i ON t(a);")},
+		"0002_add_index.down.sql":    {Data: []byte("DROP INDEX i;")},
+		"0001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (a TEXT);")},
+		"0001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+	}
+
+	// Act
+	migrations, err := database.LoadMigrations(fsys)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, migrations, 2)
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "create_table", migrations[0].Name)
+	assert.Equal(t, "DROP TABLE t;", migrations[0].Down)
+	assert.Equal(t, 2, migrations[1].Version)
+	assert.Len(t, migrations[0].Checksum, 64)
+}
+
+func TestLoadMigrations_ChecksumChangesWithContent(t *testing.T) {
+	// Arrange
+	before := fstest.MapFS{
+		"0001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (a TEXT);")},
+		"0001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+	}
+	after := fstest.MapFS{
+		"0001_create_table.up.sql":   {Data: []byte("CREATE TABLE t (a TEXT, b TEXT);")},
+		"0001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+	}
+
+	// Act
+	m1, err1 := database.LoadMigrations(before)
+	m2, err2 := database.LoadMigrations(after)
+
+	// Assert
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.NotEqual(t, m1[0].Checksum, m2[0].Checksum)
+}
+
+func TestLoadMigrations_MissingDown(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"0001_create_table.up.sql": {Data: []byte("CREATE TABLE t (a TEXT);")},
+	}
+
+	// Act
+	_, err := database.LoadMigrations(fsys)
+
+	// Assert
+	assert.EqualError(t, err, "migration 1 (create_table) needs both up and down files")
+}
+
+func TestLoadMigrations_InvalidFileName(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"create_table.sql": {Data: []byte("CREATE TABLE t (a TEXT);")},
+	}
+
+	// Act
+	_, err := database.LoadMigrations(fsys)
+
+	// Assert
+	assert.EqualError(t, err, `invalid migration file name "create_table.sql"`)
+}
+
+func TestNewMigrator_EmbeddedMigrationsAreValid(t *testing.T) {
+	for _, driver := range []string{config.DriverSQLite, config.DriverTurso, config.DriverPostgres} {
+		t.Run(driver, func(t *testing.T) {
+			// Act
+			_, err := database.NewMigrator(nil, driver)
+
+			// Assert
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRebind(t *testing.T) {
+	// Act
+	got := database.Rebind(`SELECT * FROM books WHERE id = ? AND title LIKE '%?%' AND year > ?`)
+
+	// Assert
+	assert.Equal(t, `SELECT * FROM books WHERE id = $1 AND title LIKE '%?%' AND year > $2`, got)
+}
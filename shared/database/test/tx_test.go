@@ -0,0 +1,79 @@
+package database_test
+
+import (
+	"api-go-gestion-libros-hexagonal/shared/database"
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openTxDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := database.OpenSQLite(database.MemoryPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Exec(`CREATE TABLE t (v TEXT NOT NULL)`)
+	require.NoError(t, err)
+	return db
+}
+
+func insert(ctx context.Context, db *sql.DB, v string) error {
+	_, err := database.ConnFrom(ctx, db).ExecContext(ctx, `INSERT INTO t (v) VALUES (?)`, v)
+	return err
+}
+
+func countRows(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var n int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM t`).Scan(&n))
+	return n
+}
+
+func TestTxManager_CommitAndRollback(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	db := openTxDB(t)
+	tx := database.NewTxManager(db)
+
+	// Act
+	committed := tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := insert(ctx, db, "a"); err != nil {
+			return err
+		}
+		// Una unidad anidada se une a la de afuera
+		return tx.WithinTx(ctx, func(ctx context.Context) error { return insert(ctx, db, "b") })
+	})
+	rolledBack := tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := insert(ctx, db, "c"); err != nil {
+			return err
+		}
+		return errors.New("boom")
+	})
+
+	// Assert
+	assert.NoError(t, committed)
+	assert.EqualError(t, rolledBack, "boom")
+	assert.Equal(t, 2, countRows(t, db))
+}
+
+func TestTxManager_RollbackOnPanic(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	db := openTxDB(t)
+	tx := database.NewTxManager(db)
+
+	// Act
+	assert.Panics(t, func() {
+		_ = tx.WithinTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, insert(ctx, db, "a"))
+			panic("boom")
+		})
+	})
+
+	// Assert: la conexión quedó libre y sin el insert
+	assert.Equal(t, 0, countRows(t, db))
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"api-go-gestion-libros-hexagonal/shared/config"
 	"database/sql"
 	"fmt"
 
@@ -23,19 +24,15 @@ func OpenTurso(url, token string) (*sql.DB, error) {
 	return db, nil
 }
 
-// InitSchema crea la tabla books con ISBN único
-func InitSchema(db *sql.DB) error {
-	ddl := `
-	CREATE TABLE IF NOT EXISTS books (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		title TEXT NOT NULL,
-		author TEXT NOT NULL,
-		year INTEGER NOT NULL,
-		genre TEXT NOT NULL,
-		isbn TEXT NOT NULL UNIQUE,
-		created_at TIMESTAMP NOT NULL,
-		updated_at TIMESTAMP NOT NULL
-	);`
-	_, err := db.Exec(ddl)
-	return err
+// Open abre la base de datos según el driver configurado
+func Open(cfg config.Config) (*sql.DB, error) {
+	switch cfg.DatabaseDriver {
+	case config.DriverSQLite:
+		return OpenSQLite(cfg.DatabasePath)
+	case config.DriverPostgres:
+		return OpenPostgres(cfg.DatabaseURL)
+	case config.DriverTurso, "":
+		return OpenTurso(cfg.TursoURL, cfg.TursoToken)
+	}
+	return nil, fmt.Errorf("unknown database driver %q", cfg.DatabaseDriver)
 }